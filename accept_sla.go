@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type acceptSLAAlerts struct {
+	mu       sync.Mutex
+	sent     map[int]bool
+	breaches int64
+}
+
+func newAcceptSLAAlerts() *acceptSLAAlerts {
+	return &acceptSLAAlerts{sent: map[int]bool{}}
+}
+
+type MetricsResponse struct {
+	AcceptSLABreaches int64
+}
+
+// pendingSince is when a ticket started waiting to be accepted, its
+// ScheduledFor for pre-orders and its CreatedAt otherwise.
+func pendingSince(ticket Ticket) time.Time {
+	if ticket.ScheduledFor != nil && ticket.ScheduledFor.After(ticket.CreatedAt) {
+		return *ticket.ScheduledFor
+	}
+
+	return ticket.CreatedAt
+}
+
+func breachesAcceptSLA(ticket Ticket, now time.Time, sla time.Duration) bool {
+	return ticket.Status == STATUS_PENDING && now.Sub(pendingSince(ticket)) > sla
+}
+
+func (k *KitchenServer) CheckAcceptSLA() (int, error) {
+	if k.acceptSLA <= 0 {
+		return 0, nil
+	}
+
+	now := k.clock.Now()
+
+	pending := map[int]bool{}
+	breaching := []Ticket{}
+	err := k.store.StreamTickets(context.Background(), func(ticket Ticket) error {
+		if ticket.Status == STATUS_PENDING {
+			pending[ticket.ID] = true
+		}
+		if breachesAcceptSLA(ticket, now, k.acceptSLA) {
+			breaching = append(breaching, ticket)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	k.acceptSLAAlerts.mu.Lock()
+	defer k.acceptSLAAlerts.mu.Unlock()
+
+	alerted := 0
+	for _, ticket := range breaching {
+		if k.acceptSLAAlerts.sent[ticket.ID] {
+			continue
+		}
+
+		k.recordEvent(TicketEvent{
+			Type:       EVENT_ACCEPT_SLA_BREACHED,
+			TicketID:   ticket.ID,
+			Status:     ticket.Status,
+			OccurredAt: now,
+			Rush:       ticket.Rush,
+		})
+		k.acceptSLAAlerts.sent[ticket.ID] = true
+		k.acceptSLAAlerts.breaches++
+		alerted++
+	}
+
+	for id := range k.acceptSLAAlerts.sent {
+		if !pending[id] {
+			delete(k.acceptSLAAlerts.sent, id)
+		}
+	}
+
+	return alerted, nil
+}
+
+func (k *KitchenServer) CheckAcceptSLAEvery(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := k.CheckAcceptSLA(); err != nil {
+					k.logger.Error("unable to check accept SLA", "error", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (k *KitchenServer) AcceptSLABreaches() int64 {
+	k.acceptSLAAlerts.mu.Lock()
+	defer k.acceptSLAAlerts.mu.Unlock()
+
+	return k.acceptSLAAlerts.breaches
+}
+
+func (k *KitchenServer) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, MetricsResponse{AcceptSLABreaches: k.AcceptSLABreaches()})
+}
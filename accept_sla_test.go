@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckAcceptSLA(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	sla := 5 * time.Minute
+	scheduledFor := now.Add(time.Hour)
+
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_PENDING, CreatedAt: now},
+				{ID: 2, Status: STATUS_PENDING, CreatedAt: now.Add(-time.Minute)},
+				{ID: 3, Status: STATUS_ACCEPTED, CreatedAt: now},
+				{ID: 4, Status: STATUS_PENDING, CreatedAt: now, ScheduledFor: &scheduledFor},
+			},
+		}
+	}
+
+	t.Run("waits until the SLA has passed", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := NewKitchenServer(newStore(), WithClock(clock), WithAcceptSLA(sla))
+		events := server.events.subscribe()
+
+		clock.Advance(sla - time.Minute)
+		alerted, _ := server.CheckAcceptSLA()
+
+		if alerted != 0 {
+			t.Errorf("got %d tickets alerted, want 0", alerted)
+		}
+		if got := drainEvents(events); len(got) != 0 {
+			t.Errorf("got events %v, want none", got)
+		}
+	})
+
+	t.Run("alerts once per breaching ticket", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := NewKitchenServer(newStore(), WithClock(clock), WithAcceptSLA(sla))
+		events := server.events.subscribe()
+
+		clock.Advance(sla + time.Second)
+		server.CheckAcceptSLA()
+		clock.Advance(time.Minute)
+		server.CheckAcceptSLA()
+
+		got := drainEvents(events)
+		if len(got) != 2 || got[0].TicketID == got[1].TicketID {
+			t.Fatalf("got events %v, want one for each of tickets 1 and 2", got)
+		}
+		for _, event := range got {
+			if event.Type != EVENT_ACCEPT_SLA_BREACHED || (event.TicketID != 1 && event.TicketID != 2) {
+				t.Errorf("got %q event for ticket %d, want accept SLA alerts for tickets 1 and 2", event.Type, event.TicketID)
+			}
+		}
+		if breaches := server.AcceptSLABreaches(); breaches != 2 {
+			t.Errorf("got %d breaches counted, want 2", breaches)
+		}
+	})
+
+	t.Run("measures pre-orders from ScheduledFor", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := NewKitchenServer(newStore(), WithClock(clock), WithAcceptSLA(sla))
+
+		clock.Advance(time.Hour + sla)
+		server.CheckAcceptSLA()
+		if breaches := server.AcceptSLABreaches(); breaches != 2 {
+			t.Errorf("got %d breaches counted, want 2", breaches)
+		}
+
+		clock.Advance(time.Second)
+		server.CheckAcceptSLA()
+		if breaches := server.AcceptSLABreaches(); breaches != 3 {
+			t.Errorf("got %d breaches counted, want 3", breaches)
+		}
+	})
+
+	t.Run("is off without an SLA", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		clock.Advance(time.Hour)
+		alerted, _ := server.CheckAcceptSLA()
+
+		if alerted != 0 {
+			t.Errorf("got %d tickets alerted, want 0", alerted)
+		}
+	})
+
+	t.Run("reports breaches on the admin metrics", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := NewKitchenServer(newStore(), WithClock(clock), WithAcceptSLA(sla), WithAdmin(true))
+
+		clock.Advance(sla + time.Second)
+		server.CheckAcceptSLA()
+
+		request, _ := http.NewRequest(http.MethodGet, "/admin/metrics", nil)
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if got := response.Body.String(); got != `{"AcceptSLABreaches":2}`+"\n" {
+			t.Errorf("got body %q, want 2 breaches", got)
+		}
+	})
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type ReopenRequest struct {
+	Reason string
+}
+
+type RushRequest struct {
+	Rush bool
+}
+
+type Substitution struct {
+	From string
+	To   string
+}
+
+func (k *KitchenServer) serveTicketAction(w http.ResponseWriter, r *http.Request) {
+	stringID, action, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/ticket/"), "/")
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	ticketID, ok := k.pathTicketID(w, stringID)
+	if !ok {
+		return
+	}
+
+	if item, ok := strings.CutPrefix(action, "items/"); ok {
+		k.adjustItemQuantity(w, r, ticketID, item)
+		return
+	}
+
+	switch action {
+	case "accept":
+		k.acceptTicket(w, r, ticketID)
+	case "reopen":
+		k.reopenTicket(w, r, ticketID)
+	case "substitute":
+		k.substituteItem(w, r, ticketID)
+	case "steps":
+		k.checkStep(w, r, ticketID)
+	case "complete":
+		k.completeTicket(w, r, ticketID)
+	case "attachments":
+		k.uploadAttachment(w, r, ticketID)
+	case "rush":
+		k.rushTicket(w, r, ticketID)
+	case "merge":
+		k.mergeTicket(w, r, ticketID)
+	case "bump":
+		k.moveTicket(w, r, ticketID, -1, EVENT_BUMPED)
+	case "demote":
+		k.moveTicket(w, r, ticketID, 1, EVENT_DEMOTED)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (k *KitchenServer) acceptTicket(w http.ResponseWriter, r *http.Request, ticketID int) {
+	store := k.storeFor(r)
+	ticket, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if ticket.Status != STATUS_PENDING {
+		k.writeError(w, http.StatusConflict, CODE_INVALID_TRANSITION, fmt.Sprintf("can't accept %v ticket", ticket.Status))
+		return
+	}
+
+	incomplete, err := incompleteDependencies(store, ticket)
+	if err != nil {
+		k.logger.Error("unable to check ticket dependencies", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if len(incomplete) > 0 {
+		k.writeError(w, http.StatusConflict, CODE_DEPENDENCIES_INCOMPLETE, fmt.Sprintf("waiting on tickets %v", incomplete))
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	// The store checks the station capacity and that the ticket is still
+	// pending under the same lock it accepts the ticket with.
+	now := k.clock.Now()
+	station := ticket.Station
+	filter := TicketFilter{Station: station, Statuses: []Status{STATUS_PENDING}}
+	claim := Claim{At: now, TicketID: ticketID, Capacity: k.stationCapacities[station]}
+	ticket, accepted, err := k.claimTicket(store, filter, claim, TicketEvent{Type: EVENT_ACCEPTED, OccurredAt: now})
+	if errors.Is(err, ErrStationFull) {
+		k.writeStationFull(w, station, claim.Capacity)
+		return
+	}
+	if err != nil {
+		k.logger.Error("unable to accept ticket", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !accepted {
+		k.writeError(w, http.StatusConflict, CODE_INVALID_TRANSITION, "ticket is no longer pending")
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+}
+
+func (k *KitchenServer) reopenTicket(w http.ResponseWriter, r *http.Request, ticketID int) {
+	request := ReopenRequest{}
+	err := decodeRequestBody(r.Body, &request)
+	if err != nil || strings.TrimSpace(request.Reason) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := k.storeFor(r).GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if ticket.Status != STATUS_COMPLETED {
+		k.writeError(w, http.StatusConflict, CODE_INVALID_TRANSITION, fmt.Sprintf("can't reopen %v ticket", ticket.Status))
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	ticket.Status = STATUS_ACCEPTED
+	ticket.Items = undoSteps(ticket.Items)
+	ticket.CompletedAt = nil
+	ticket.UpdatedAt = now
+
+	err = k.updateTicket(k.storeFor(r), ticket, TicketEvent{
+		Type:       EVENT_REOPENED,
+		TicketID:   ticketID,
+		Status:     ticket.Status,
+		Reason:     request.Reason,
+		OccurredAt: now,
+	})
+	if err != nil {
+		k.logger.Error("unable to reopen ticket", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+}
+
+func (k *KitchenServer) substituteItem(w http.ResponseWriter, r *http.Request, ticketID int) {
+	substitution := Substitution{}
+	err := decodeRequestBody(r.Body, &substitution)
+	if err != nil || substitution.From == "" || substitution.To == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	store := k.storeFor(r)
+	ticket, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !ticketHasItem(ticket, substitution.From) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	ticket.Substitutions = append(ticket.Substitutions, substitution)
+	ticket.UpdatedAt = now
+
+	err = k.updateTicket(store, ticket, TicketEvent{
+		Type:       EVENT_SUBSTITUTED,
+		TicketID:   ticketID,
+		Status:     ticket.Status,
+		Reason:     substitution.From + " -> " + substitution.To,
+		OccurredAt: now,
+	})
+	if err != nil {
+		k.logger.Error("unable to record substitution", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+}
+
+func (k *KitchenServer) moveTicket(w http.ResponseWriter, r *http.Request, ticketID int, delta int, eventType string) {
+	store := k.storeFor(r)
+	ticket, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !containsStatus(activeStatuses, ticket.Status) {
+		k.writeError(w, http.StatusConflict, CODE_INVALID_TRANSITION, fmt.Sprintf("can't move %v ticket", ticket.Status))
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	ticket, err = k.shiftTicket(store, ticketID, delta, now, TicketEvent{Type: eventType, OccurredAt: now})
+	if err != nil {
+		k.logger.Error("unable to move ticket", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+}
+
+func (k *KitchenServer) rushTicket(w http.ResponseWriter, r *http.Request, ticketID int) {
+	request := RushRequest{}
+	err := decodeRequestBody(r.Body, &request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	store := k.storeFor(r)
+	ticket, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !containsStatus(activeStatuses, ticket.Status) {
+		k.writeError(w, http.StatusConflict, CODE_INVALID_TRANSITION, fmt.Sprintf("can't rush %v ticket", ticket.Status))
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	ticket.Rush = request.Rush
+	ticket.UpdatedAt = now
+
+	eventType := EVENT_RUSHED
+	if !ticket.Rush {
+		eventType = EVENT_UNRUSHED
+	}
+	err = k.updateTicket(store, ticket, TicketEvent{
+		Type:       eventType,
+		TicketID:   ticketID,
+		Status:     ticket.Status,
+		OccurredAt: now,
+		Rush:       ticket.Rush,
+	})
+	if err != nil {
+		k.logger.Error("unable to rush ticket", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReopenTicket(t *testing.T) {
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_COMPLETED, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH, Steps: []Step{{Name: "grill", Done: true}, {Name: "plate", Done: true}}}}},
+				{ID: 2, Status: STATUS_CANCELLED, Items: []Item{{Name: "fries", Quantity: 1, Unit: UNIT_EACH}}},
+				{ID: 3, Status: STATUS_PENDING, Items: []Item{{Name: "pizza", Quantity: 1, Unit: UNIT_EACH}}},
+			},
+		}
+	}
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+
+	t.Run("reopens completed ticket and records reason", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		request := newReopenTicketRequest(1, ReopenRequest{Reason: "burger was undercooked"})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got, _ := store.GetTicketByID(1)
+		if got.Status != STATUS_ACCEPTED {
+			t.Errorf("got status %v, want %v", got.Status, STATUS_ACCEPTED)
+		}
+		assertTime(t, got.UpdatedAt, clock.now)
+		if done, total := ticketStepProgress(got); done != 0 || total != 2 {
+			t.Errorf("got %d of %d steps done, want 0 of 2", done, total)
+		}
+
+		events, _ := store.GetTicketEvents(1)
+		want := []TicketEvent{{
+			Type:       EVENT_REOPENED,
+			TicketID:   1,
+			Status:     STATUS_ACCEPTED,
+			Reason:     "burger was undercooked",
+			OccurredAt: clock.now,
+		}}
+		assertEvents(t, events, want)
+	})
+
+	t.Run("returns Conflict on cancelled ticket", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		request := newReopenTicketRequest(2, ReopenRequest{Reason: "customer came back"})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusConflict)
+
+		got, _ := store.GetTicketByID(2)
+		if got.Status != STATUS_CANCELLED {
+			t.Errorf("got status %v, want ticket to stay %v", got.Status, STATUS_CANCELLED)
+		}
+	})
+
+	t.Run("returns Conflict on ticket that isn't completed", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		request := newReopenTicketRequest(3, ReopenRequest{Reason: "wrong order"})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusConflict)
+	})
+
+	t.Run("returns Bad Request without reason", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		request := newReopenTicketRequest(1, ReopenRequest{Reason: "  "})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		if len(store.events) != 0 {
+			t.Errorf("got events %v, want none recorded", store.events)
+		}
+	})
+
+	t.Run("returns Not Found on nonexistant ticket ID", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		request := newReopenTicketRequest(4, ReopenRequest{Reason: "wrong order"})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func newReopenTicketRequest(ticketID int, reopen ReopenRequest) *http.Request {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(reopen)
+
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/%d/reopen", ticketID), buffer)
+	return req
+}
+
+func TestSubstituteItem(t *testing.T) {
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{{
+				ID:     1,
+				Status: STATUS_ACCEPTED,
+				Items:  Items{{Name: "sourdough toast", Quantity: 1, Unit: UNIT_EACH}},
+			}},
+		}
+	}
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+
+	t.Run("records substitution and surfaces it on GET", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		substitution := Substitution{From: "sourdough toast", To: "ciabatta toast"}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newSubstituteRequest(1, substitution))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(1))
+
+		got := getTicketFromResponse(t, response.Body)
+		if len(got.Substitutions) != 1 || got.Substitutions[0] != substitution {
+			t.Errorf("got substitutions %v, want [%v]", got.Substitutions, substitution)
+		}
+	})
+
+	t.Run("returns Bad Request for item not on ticket", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newSubstituteRequest(1, Substitution{From: "rye toast", To: "ciabatta toast"}))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		if len(store.tickets[0].Substitutions) != 0 {
+			t.Errorf("got substitutions %v, want none", store.tickets[0].Substitutions)
+		}
+	})
+
+	t.Run("returns Not Found on nonexistant ticket ID", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newSubstituteRequest(2, Substitution{From: "sourdough toast", To: "ciabatta toast"}))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func newSubstituteRequest(ticketID int, substitution Substitution) *http.Request {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(substitution)
+
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/%d/substitute", ticketID), buffer)
+	return req
+}
+
+func TestMoveTicket(t *testing.T) {
+	newServer := func() (*KitchenServer, *InMemoryKitchenStore) {
+		store := NewInMemoryKitchenStore()
+		for _, item := range []string{"burger", "fries", "pizza"} {
+			store.StoreTicket(Ticket{Status: STATUS_PENDING, Items: []Item{{Name: item, Quantity: 1, Unit: UNIT_EACH}}})
+		}
+		store.StoreTicket(Ticket{Status: STATUS_COMPLETED, Items: []Item{{Name: "water", Quantity: 1, Unit: UNIT_EACH}}})
+
+		return NewKitchenServer(store), store
+	}
+
+	t.Run("bumped ticket is listed ahead of older tickets", func(t *testing.T) {
+		server, _ := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMoveTicketRequest(3, "bump"))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		assertListedIDs(t, server, []int{3, 1, 2, 4})
+	})
+
+	t.Run("demoted ticket is listed behind newer tickets", func(t *testing.T) {
+		server, _ := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMoveTicketRequest(1, "demote"))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		assertListedIDs(t, server, []int{2, 3, 4, 1})
+	})
+
+	t.Run("paginates in queue order after a bump", func(t *testing.T) {
+		server, _ := newServer()
+		server.ServeHTTP(httptest.NewRecorder(), newMoveTicketRequest(3, "bump"))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?limit=2"))
+		firstPage := getTicketPageFromResponse(t, response.Body)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?after="+firstPage.NextCursor+"&limit=2"))
+		secondPage := getTicketPageFromResponse(t, response.Body)
+
+		got := []int{}
+		for _, ticket := range append(firstPage.Tickets, secondPage.Tickets...) {
+			got = append(got, ticket.ID)
+		}
+
+		want := []int{3, 1, 2, 4}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("keeps every concurrent bump", func(t *testing.T) {
+		server, store := newServer()
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				server.ServeHTTP(httptest.NewRecorder(), newMoveTicketRequest(2, "bump"))
+			}()
+		}
+		wg.Wait()
+
+		ticket, _ := store.GetTicketByID(2)
+		if ticket.QueueRank != -10 {
+			t.Errorf("got queue rank %d, want -10", ticket.QueueRank)
+		}
+	})
+
+	t.Run("returns Conflict on completed ticket", func(t *testing.T) {
+		server, _ := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMoveTicketRequest(4, "bump"))
+
+		assertStatus(t, response.Code, http.StatusConflict)
+	})
+
+	t.Run("returns Not Found on nonexistant ticket ID", func(t *testing.T) {
+		server, _ := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMoveTicketRequest(9, "demote"))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func TestRushTicket(t *testing.T) {
+	newServer := func() (*KitchenServer, *InMemoryKitchenStore) {
+		store := NewInMemoryKitchenStore()
+		for _, item := range []string{"burger", "fries", "pizza"} {
+			store.StoreTicket(Ticket{Status: STATUS_PENDING, Items: []Item{{Name: item, Quantity: 1, Unit: UNIT_EACH}}})
+		}
+		store.StoreTicket(Ticket{Status: STATUS_COMPLETED, Items: []Item{{Name: "water", Quantity: 1, Unit: UNIT_EACH}}})
+
+		return NewKitchenServer(store), store
+	}
+
+	t.Run("rush ticket is listed ahead of older tickets", func(t *testing.T) {
+		server, _ := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRushTicketRequest(3, true))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		assertListedIDs(t, server, []int{3, 1, 2, 4})
+	})
+
+	t.Run("rush ticket stays behind bumped tickets", func(t *testing.T) {
+		server, _ := newServer()
+		server.ServeHTTP(httptest.NewRecorder(), newMoveTicketRequest(2, "bump"))
+		server.ServeHTTP(httptest.NewRecorder(), newRushTicketRequest(3, true))
+
+		assertListedIDs(t, server, []int{2, 3, 1, 4})
+	})
+
+	t.Run("rush can be set at creation", func(t *testing.T) {
+		server, _ := newServer()
+
+		ticket := Ticket{Rush: true, Items: []Item{{Name: "shake", Quantity: 1, Unit: UNIT_EACH}}}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+		assertStatus(t, response.Code, http.StatusAccepted)
+
+		assertListedIDs(t, server, []int{5, 1, 2, 3, 4})
+	})
+
+	t.Run("cleared rush ticket returns to its place", func(t *testing.T) {
+		server, store := newServer()
+		server.ServeHTTP(httptest.NewRecorder(), newRushTicketRequest(3, true))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRushTicketRequest(3, false))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		assertListedIDs(t, server, []int{1, 2, 3, 4})
+
+		events, _ := store.GetTicketEvents(3)
+		if len(events) != 2 || events[0].Type != EVENT_RUSHED || events[1].Type != EVENT_UNRUSHED {
+			t.Errorf("got events %v, want rushed then unrushed", events)
+		}
+	})
+
+	t.Run("paginates in queue order after a rush", func(t *testing.T) {
+		server, store := newServer()
+		server.ServeHTTP(httptest.NewRecorder(), newRushTicketRequest(2, true))
+
+		cursor, _ := store.GetTicketByID(2)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?after="+encodeCursor(cursor)+"&limit=2"))
+
+		got := []int{}
+		for _, ticket := range getTicketPageFromResponse(t, response.Body).Tickets {
+			got = append(got, ticket.ID)
+		}
+
+		want := []int{1, 3}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("returns Conflict on completed ticket", func(t *testing.T) {
+		server, _ := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRushTicketRequest(4, true))
+
+		assertStatus(t, response.Code, http.StatusConflict)
+	})
+}
+
+func newRushTicketRequest(ticketID int, rush bool) *http.Request {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(RushRequest{Rush: rush})
+
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/%d/rush", ticketID), buffer)
+	return req
+}
+
+func newMoveTicketRequest(ticketID int, action string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/%d/%s", ticketID, action), nil)
+	return req
+}
+
+func assertListedIDs(t testing.TB, server *KitchenServer, want []int) {
+	t.Helper()
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, newListTicketsRequest(""))
+
+	got := []int{}
+	for _, ticket := range getTicketPageFromResponse(t, response.Body).Tickets {
+		got = append(got, ticket.ID)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got IDs %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+type PurgeResponse struct {
+	Removed int
+}
+
+func (k *KitchenServer) AdminHandler() http.Handler {
+	return k.adminHandler
+}
+
+func (k *KitchenServer) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	if !k.adminEnabled {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/admin/tickets/completed":
+		if r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		k.purgeCompletedTickets(w, r)
+	case "/admin/maintenance":
+		k.serveMaintenance(w, r)
+	case "/admin/export":
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		k.exportBackup(w, r)
+	case "/admin/import":
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		k.importBackup(w, r)
+	case "/admin/reindex":
+		k.reindexStore(w, r)
+	case "/admin/metrics":
+		k.serveMetrics(w, r)
+	case "/admin/flags":
+		k.serveFeatureFlags(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (k *KitchenServer) purgeCompletedTickets(w http.ResponseWriter, r *http.Request) {
+	if !k.claimNonce(w, r) {
+		return
+	}
+
+	before, err := time.Parse(time.RFC3339, r.URL.Query().Get("before"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	removed, err := k.store.PurgeCompletedBefore(before, k.clock.Now())
+	if err != nil {
+		k.logger.Error("unable to purge completed tickets", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, PurgeResponse{Removed: removed})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPurgeCompletedTickets(t *testing.T) {
+	cutoff := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_COMPLETED, UpdatedAt: cutoff.Add(-time.Hour)},
+				{ID: 2, Status: STATUS_COMPLETED, UpdatedAt: cutoff.Add(time.Hour)},
+				{ID: 3, Status: STATUS_ACCEPTED, UpdatedAt: cutoff.Add(-time.Hour)},
+				{ID: 4, Status: STATUS_PENDING, UpdatedAt: cutoff.Add(-time.Hour)},
+			},
+		}
+	}
+
+	t.Run("purges only completed tickets older than cutoff", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithAdmin(true))
+
+		request := newPurgeCompletedRequest(cutoff.Format(time.RFC3339))
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := PurgeResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+		if got.Removed != 1 {
+			t.Errorf("got %d tickets removed, want 1", got.Removed)
+		}
+
+		if _, err := store.GetTicketByID(1); err == nil {
+			t.Errorf("old completed ticket wasn't purged")
+		}
+
+		for _, id := range []int{2, 3, 4} {
+			if _, err := store.GetTicketByID(id); err != nil {
+				t.Errorf("ticket %d was purged, want it kept", id)
+			}
+		}
+	})
+
+	t.Run("returns Bad Request on invalid cutoff", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithAdmin(true))
+
+		request := newPurgeCompletedRequest("yesterday")
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("returns Not Found when admin endpoints are disabled", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store)
+
+		request := newPurgeCompletedRequest(cutoff.Format(time.RFC3339))
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+		if len(store.tickets) != 4 {
+			t.Errorf("got %d tickets, want none purged", len(store.tickets))
+		}
+	})
+}
+
+func TestAdminRoutesArePrivate(t *testing.T) {
+	store := &StubKitchenStore{
+		tickets: []Ticket{{ID: 1, Status: STATUS_COMPLETED}},
+	}
+	server := NewKitchenServer(store, WithAdmin(true))
+
+	t.Run("returns Not Found for admin routes on the public handler", func(t *testing.T) {
+		request := newPurgeCompletedRequest(time.Now().Format(time.RFC3339))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+		if len(store.tickets) != 1 {
+			t.Errorf("got %d tickets, want none purged", len(store.tickets))
+		}
+	})
+
+	t.Run("returns Not Found for ticket routes on the admin handler", func(t *testing.T) {
+		request := newGetTicketRequest(1)
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func newPurgeCompletedRequest(before string) *http.Request {
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/tickets/completed?before="+before, nil)
+	req.Header.Set(nonceHeader, newNonce())
+	return req
+}
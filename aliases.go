@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func ParseItemAliases(pairs string) (map[string]string, error) {
+	aliases := map[string]string{}
+	for _, pair := range strings.Split(pairs, ",") {
+		alias, name, found := strings.Cut(pair, "=")
+		alias, name = strings.TrimSpace(alias), strings.TrimSpace(name)
+		if !found || alias == "" || name == "" {
+			return nil, fmt.Errorf("invalid item alias %q, want alias=name", pair)
+		}
+
+		aliases[alias] = name
+	}
+
+	return aliases, nil
+}
+
+func aliasKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func (k *KitchenServer) canonicalizeItemNames(items Items) {
+	if len(k.itemAliases) == 0 {
+		return
+	}
+
+	for i := range items {
+		if name, ok := k.itemAliases[aliasKey(items[i].Name)]; ok {
+			items[i].Name = name
+		}
+	}
+}
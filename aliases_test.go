@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestItemAliases(t *testing.T) {
+	aliases := map[string]string{
+		"coke":      "Coca-Cola",
+		"coca cola": "Coca-Cola",
+		"coca-cola": "Coca-Cola",
+	}
+
+	t.Run("canonicalizes aliases and leaves unknown names alone", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithItemAliases(aliases))
+
+		ticket := Ticket{Items: Items{
+			{Name: "coke", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "Coca  Cola", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "COCA-COLA", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "Coca-Cola", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "sprite", Quantity: 1, Unit: UNIT_EACH},
+		}}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		assertItems(t, store.tickets[0].Items, Items{
+			{Name: "Coca-Cola", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "Coca-Cola", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "Coca-Cola", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "Coca-Cola", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "sprite", Quantity: 1, Unit: UNIT_EACH},
+		})
+	})
+
+	t.Run("canonicalizes before merging duplicates", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithItemAliases(aliases), WithDuplicateItems(DUPLICATES_MERGE))
+
+		ticket := Ticket{Items: Items{
+			{Name: "coke", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "coca cola", Quantity: 2, Unit: UNIT_EACH},
+		}}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		assertItems(t, store.tickets[0].Items, Items{{Name: "Coca-Cola", Quantity: 3, Unit: UNIT_EACH}})
+	})
+}
+
+func TestParseItemAliases(t *testing.T) {
+	t.Run("parses pairs", func(t *testing.T) {
+		got, err := ParseItemAliases("coke=Coca-Cola, coca cola = Coca-Cola")
+		if err != nil {
+			t.Fatalf("got error %v, want none", err)
+		}
+
+		if len(got) != 2 || got["coke"] != "Coca-Cola" || got["coca cola"] != "Coca-Cola" {
+			t.Errorf("got aliases %v", got)
+		}
+	})
+
+	t.Run("rejects pairs without a name", func(t *testing.T) {
+		if _, err := ParseItemAliases("coke="); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type BlobStore interface {
+	PutBlob(key string, data []byte) error
+	GetBlob(key string) (io.ReadSeekCloser, error)
+}
+
+type AttachmentResponse struct {
+	ID   string
+	Size int
+}
+
+type FileBlobStore struct {
+	dir string
+}
+
+func NewFileBlobStore(dir string) *FileBlobStore {
+	return &FileBlobStore{dir: dir}
+}
+
+func (f *FileBlobStore) PutBlob(key string, data []byte) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(temp, path)
+}
+
+func (f *FileBlobStore) GetBlob(key string) (io.ReadSeekCloser, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(path)
+}
+
+func (f *FileBlobStore) path(key string) (string, error) {
+	if !filepath.IsLocal(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(f.dir, key), nil
+}
+
+func attachmentKey(ticketID int, attachmentID string) string {
+	return strconv.Itoa(ticketID) + "/" + attachmentID
+}
+
+func newAttachmentID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+func isAttachmentID(id string) bool {
+	_, err := hex.DecodeString(id)
+	return len(id) == 16 && err == nil
+}
+
+func (k *KitchenServer) uploadAttachment(w http.ResponseWriter, r *http.Request, ticketID int) {
+	ticket, err := k.storeFor(r).GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(k.limits.MaxAttachmentSize)))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil || len(data) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	id := newAttachmentID()
+	err = k.blobs.PutBlob(attachmentKey(ticketID, id), data)
+	if err != nil {
+		k.logger.Error("unable to store attachment", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusCreated, AttachmentResponse{ID: id, Size: len(data)})
+}
+
+func (k *KitchenServer) getAttachment(w http.ResponseWriter, r *http.Request) {
+	stringID, attachmentID, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/ticket/"), "/attachments/")
+	ticketID, ok := k.pathTicketID(w, stringID)
+	if !ok {
+		return
+	}
+
+	if !isAttachmentID(attachmentID) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := k.storeFor(r).GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	blob, err := k.blobs.GetBlob(attachmentKey(ticketID, attachmentID))
+	if errors.Is(err, os.ErrNotExist) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		k.logger.Error("unable to read attachment", "ticket_id", ticketID, "attachment_id", attachmentID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer blob.Close()
+
+	http.ServeContent(w, r, attachmentID, time.Time{}, blob)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAttachments(t *testing.T) {
+	photo := []byte("0123456789abcdefghij")
+	newServer := func(t testing.TB) *KitchenServer {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 1, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}}}
+		limits := defaultLimits
+		limits.MaxAttachmentSize = 32
+		return NewKitchenServer(store, WithBlobStore(NewFileBlobStore(t.TempDir())), WithLimits(limits))
+	}
+
+	t.Run("stores and serves an attachment", func(t *testing.T) {
+		server := newServer(t)
+		attachment := uploadAttachment(t, server, 1, photo)
+
+		if attachment.Size != len(photo) {
+			t.Errorf("got size %d, want %d", attachment.Size, len(photo))
+		}
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetAttachmentRequest(1, attachment.ID, ""))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertHeader(t, response, "Accept-Ranges", "bytes")
+		if !bytes.Equal(response.Body.Bytes(), photo) {
+			t.Errorf("got body %q, want %q", response.Body, photo)
+		}
+	})
+
+	t.Run("serves a range as Partial Content", func(t *testing.T) {
+		server := newServer(t)
+		attachment := uploadAttachment(t, server, 1, photo)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetAttachmentRequest(1, attachment.ID, "bytes=5-9"))
+
+		assertStatus(t, response.Code, http.StatusPartialContent)
+		assertHeader(t, response, "Content-Range", fmt.Sprintf("bytes 5-9/%d", len(photo)))
+		if got := response.Body.String(); got != "56789" {
+			t.Errorf("got body %q, want %q", got, "56789")
+		}
+	})
+
+	t.Run("returns Request Entity Too Large on oversized upload", func(t *testing.T) {
+		server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newUploadAttachmentRequest(1, bytes.Repeat([]byte("a"), 33)))
+
+		assertStatus(t, response.Code, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("returns Not Found uploading to nonexistant ticket", func(t *testing.T) {
+		server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newUploadAttachmentRequest(2, photo))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("returns Not Found on unknown attachment", func(t *testing.T) {
+		server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetAttachmentRequest(1, newAttachmentID(), ""))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("returns Bad Request on malformed attachment ID", func(t *testing.T) {
+		server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetAttachmentRequest(1, "..", ""))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func uploadAttachment(t testing.TB, server *KitchenServer, ticketID int, data []byte) AttachmentResponse {
+	t.Helper()
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, newUploadAttachmentRequest(ticketID, data))
+	assertStatus(t, response.Code, http.StatusCreated)
+
+	var attachment AttachmentResponse
+	if err := json.NewDecoder(response.Body).Decode(&attachment); err != nil {
+		t.Fatalf("unable to parse attachment response, %v", err)
+	}
+
+	return attachment
+}
+
+func newUploadAttachmentRequest(ticketID int, data []byte) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/%d/attachments", ticketID), bytes.NewReader(data))
+	return req
+}
+
+func newGetAttachmentRequest(ticketID int, attachmentID, byteRange string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/ticket/%d/attachments/%s", ticketID, attachmentID), nil)
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	return req
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const healthzPath = "/healthz"
+
+type Role string
+
+const (
+	ROLE_COOK    Role = "cook"
+	ROLE_MANAGER Role = "manager"
+)
+
+type APIKey struct {
+	Key  string
+	Role Role
+}
+
+func (k *KitchenServer) APIKeyAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(k.apiKeys) == 0 || isProbePath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, ok := k.apiKeyFromRequest(r)
+		if !ok {
+			k.writeError(w, http.StatusUnauthorized, CODE_MISSING_API_KEY, "missing API key")
+			return
+		}
+
+		role, ok := k.roleForAPIKey(key)
+		if !ok {
+			k.writeError(w, http.StatusUnauthorized, CODE_INVALID_API_KEY, "invalid API key")
+			return
+		}
+
+		if requiredRole(r) == ROLE_MANAGER && role != ROLE_MANAGER {
+			k.writeError(w, http.StatusForbidden, CODE_INSUFFICIENT_ROLE, "insufficient role")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleKey{}, role)))
+	})
+}
+
+func (k *KitchenServer) apiKeyFromRequest(r *http.Request) (string, bool) {
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return key, true
+	}
+
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key, true
+	}
+
+	if k.streamQueryToken && isStreamPath(r.URL.Path) && r.URL.Query().Has("token") {
+		return r.URL.Query().Get("token"), true
+	}
+
+	return "", false
+}
+
+func (k *KitchenServer) roleForAPIKey(key string) (Role, bool) {
+	var role Role
+	valid := false
+	for _, apiKey := range k.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey.Key)) == 1 {
+			role, valid = apiKey.Role, true
+		}
+	}
+
+	return role, valid
+}
+
+func requiredRole(r *http.Request) Role {
+	if r.Method == http.MethodDelete || strings.HasPrefix(r.URL.Path, "/admin/") {
+		return ROLE_MANAGER
+	}
+
+	return ROLE_COOK
+}
+
+func ParseAPIKeys(keys string) ([]APIKey, error) {
+	parsed := []APIKey{}
+	for _, entry := range strings.FieldsFunc(keys, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n'
+	}) {
+		key, role, found := strings.Cut(entry, ":")
+		if !found {
+			role = string(ROLE_COOK)
+		}
+
+		if Role(role) != ROLE_COOK && Role(role) != ROLE_MANAGER {
+			return nil, fmt.Errorf("unknown role %q for API key", role)
+		}
+
+		parsed = append(parsed, APIKey{Key: key, Role: Role(role)})
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	server := NewKitchenServer(&StubKitchenStore{}, WithAPIKeys(APIKey{Key: "cook-key", Role: ROLE_COOK}, APIKey{Key: "manager-key", Role: ROLE_MANAGER}))
+
+	t.Run("accepts a valid bearer key", func(t *testing.T) {
+		request := newListTicketsRequest("")
+		request.Header.Set("Authorization", "Bearer cook-key")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("accepts a valid X-API-Key", func(t *testing.T) {
+		request := newListTicketsRequest("")
+		request.Header.Set("X-API-Key", "manager-key")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("rejects an invalid key", func(t *testing.T) {
+		request := newListTicketsRequest("")
+		request.Header.Set("Authorization", "Bearer guess")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusUnauthorized)
+		assertErrorResponse(t, response, "invalid API key")
+	})
+
+	t.Run("rejects a missing key", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest(""))
+
+		assertStatus(t, response.Code, http.StatusUnauthorized)
+		assertErrorResponse(t, response, "missing API key")
+	})
+
+	t.Run("allows /healthz without a key", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+}
+
+func TestRoles(t *testing.T) {
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	keys := []APIKey{{Key: "cook-key", Role: ROLE_COOK}, {Key: "manager-key", Role: ROLE_MANAGER}}
+	newServer := func() *KitchenServer {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 0, Status: STATUS_ACCEPTED}}}
+		return NewKitchenServer(store, WithClock(clock), WithAdmin(true), WithAPIKeys(keys...))
+	}
+
+	withKey := func(request *http.Request, key string) *http.Request {
+		request.Header.Set("Authorization", "Bearer "+key)
+		return request
+	}
+
+	t.Run("forbids cooks from deleting", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		newServer().AdminHandler().ServeHTTP(response, withKey(newPurgeCompletedRequest(clock.now.Format(time.RFC3339)), "cook-key"))
+
+		assertStatus(t, response.Code, http.StatusForbidden)
+		assertErrorResponse(t, response, "insufficient role")
+	})
+
+	t.Run("lets cooks update ticket status", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		newServer().ServeHTTP(response, withKey(newCompleteTicketRequest(0), "cook-key"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("lets managers delete", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		newServer().AdminHandler().ServeHTTP(response, withKey(newPurgeCompletedRequest(clock.now.Format(time.RFC3339)), "manager-key"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	t.Run("parses keys with roles", func(t *testing.T) {
+		got, err := ParseAPIKeys("cook-key, manager-key:manager,,\nexpo-key:cook")
+		if err != nil {
+			t.Fatalf("unable to parse keys, %v", err)
+		}
+
+		want := []APIKey{
+			{Key: "cook-key", Role: ROLE_COOK},
+			{Key: "manager-key", Role: ROLE_MANAGER},
+			{Key: "expo-key", Role: ROLE_COOK},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("rejects unknown roles", func(t *testing.T) {
+		if _, err := ParseAPIKeys("owner-key:owner"); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+}
+
+func assertErrorResponse(t testing.TB, response *httptest.ResponseRecorder, want string) {
+	t.Helper()
+
+	got := ErrorResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+		t.Fatalf("unable to parse error response, %v", err)
+	}
+
+	if got.Message != want {
+		t.Errorf("got error message %q, want %q", got.Message, want)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type StubAuthorizer struct {
+	deny    string
+	actions []string
+	roles   []Role
+}
+
+func (s *StubAuthorizer) Authorize(ctx context.Context, action string, ticket Ticket) error {
+	s.actions = append(s.actions, action)
+	if role, ok := RoleFromContext(ctx); ok {
+		s.roles = append(s.roles, role)
+	}
+
+	if action == s.deny {
+		return errors.New("policy forbids " + action)
+	}
+
+	return nil
+}
+
+func TestAuthorizer(t *testing.T) {
+	ticket := Ticket{ID: 1, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+
+	t.Run("returns Forbidden when the policy denies a delete", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{ticket}}
+		server := NewKitchenServer(store, WithAuthorizer(&StubAuthorizer{deny: ACTION_DELETE}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newDeleteTicketRequest(1, ""))
+
+		assertStatus(t, response.Code, http.StatusForbidden)
+		assertErrorCode(t, response, CODE_FORBIDDEN)
+		if len(store.tickets) != 1 {
+			t.Errorf("got tickets %v, want ticket kept", store.tickets)
+		}
+	})
+
+	t.Run("consults the policy on create and update", func(t *testing.T) {
+		authorizer := &StubAuthorizer{deny: ACTION_DELETE}
+		server := NewKitchenServer(&StubKitchenStore{tickets: []Ticket{ticket}}, WithAuthorizer(authorizer))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+		assertStatus(t, response.Code, http.StatusAccepted)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(1, `{"Notes": "no onions"}`))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		want := []string{ACTION_CREATE, ACTION_UPDATE}
+		if len(authorizer.actions) != 2 || authorizer.actions[0] != want[0] || authorizer.actions[1] != want[1] {
+			t.Errorf("got actions %v, want %v", authorizer.actions, want)
+		}
+	})
+
+	t.Run("returns Forbidden when the policy denies a create", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithAuthorizer(&StubAuthorizer{deny: ACTION_CREATE}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusForbidden)
+		if len(store.tickets) != 0 {
+			t.Errorf("got tickets %v, want none stored", store.tickets)
+		}
+	})
+
+	t.Run("passes the caller's role to the policy", func(t *testing.T) {
+		authorizer := &StubAuthorizer{}
+		server := NewKitchenServer(&StubKitchenStore{tickets: []Ticket{ticket}},
+			WithAuthorizer(authorizer),
+			WithAPIKeys(APIKey{Key: "boss", Role: ROLE_MANAGER}),
+		)
+
+		request := newDeleteTicketRequest(1, "")
+		request.Header.Set("X-API-Key", "boss")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNoContent)
+		if len(authorizer.roles) != 1 || authorizer.roles[0] != ROLE_MANAGER {
+			t.Errorf("got roles %v, want the manager role", authorizer.roles)
+		}
+	})
+
+	t.Run("returns Forbidden when the policy denies an update", func(t *testing.T) {
+		cases := []struct {
+			name    string
+			request func() *http.Request
+			allowed int
+		}{
+			{"accept", func() *http.Request { return newAcceptTicketRequest(1) }, http.StatusOK},
+			{"complete", func() *http.Request { return newCompleteTicketRequest(4) }, http.StatusOK},
+			{"reopen", func() *http.Request { return newReopenTicketRequest(2, ReopenRequest{Reason: "cold"}) }, http.StatusOK},
+			{"substitute", func() *http.Request {
+				return newSubstituteRequest(4, Substitution{From: "burger", To: "salad"})
+			}, http.StatusOK},
+			{"step", func() *http.Request { return newCheckStepRequest(3, StepCheck{Item: 0, Step: "grill"}) }, http.StatusOK},
+			{"rush", func() *http.Request { return newRushTicketRequest(4, true) }, http.StatusOK},
+			{"bump", func() *http.Request { return newMoveTicketRequest(4, "bump") }, http.StatusOK},
+			{"demote", func() *http.Request { return newMoveTicketRequest(4, "demote") }, http.StatusOK},
+			{"merge", func() *http.Request { return newMergeTicketRequest(4, MergeRequest{TicketID: 5}) }, http.StatusOK},
+			{"attachment", func() *http.Request { return newUploadAttachmentRequest(4, []byte("photo")) }, http.StatusCreated},
+			{"next", func() *http.Request { return newNextTicketRequest("grill") }, http.StatusOK},
+		}
+
+		tickets := func() []Ticket {
+			items := Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}
+			return []Ticket{
+				{ID: 1, Station: "grill", Status: STATUS_PENDING, Items: items},
+				{ID: 2, Status: STATUS_COMPLETED, Items: items},
+				{ID: 3, Status: STATUS_ACCEPTED, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH, Steps: []Step{{Name: "grill"}}}}},
+				{ID: 4, Status: STATUS_ACCEPTED, Items: items},
+				{ID: 5, Status: STATUS_ACCEPTED, Items: Items{{Name: "fries", Quantity: 1, Unit: UNIT_EACH}}},
+			}
+		}
+
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				server := NewKitchenServer(&StubKitchenStore{tickets: tickets()})
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, c.request())
+				assertStatus(t, response.Code, c.allowed)
+
+				store := &StubKitchenStore{tickets: tickets()}
+				server = NewKitchenServer(store, WithAuthorizer(&StubAuthorizer{deny: ACTION_UPDATE}))
+				response = httptest.NewRecorder()
+				server.ServeHTTP(response, c.request())
+
+				assertStatus(t, response.Code, http.StatusForbidden)
+				assertErrorCode(t, response, CODE_FORBIDDEN)
+				if !reflect.DeepEqual(store.tickets, tickets()) {
+					t.Errorf("got tickets %v, want them unchanged", store.tickets)
+				}
+			})
+		}
+	})
+
+	t.Run("returns Forbidden for a batch item the policy denies", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithAuthorizer(&StubAuthorizer{deny: ACTION_CREATE}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newBatchRequest(`[{"Items": ["burger"]}]`))
+
+		results := getBatchResultsFromResponse(t, response)
+		if len(results) != 1 || results[0].Status != http.StatusForbidden || results[0].Code != CODE_FORBIDDEN {
+			t.Errorf("got results %+v, want one Forbidden result", results)
+		}
+		if len(store.tickets) != 0 {
+			t.Errorf("got tickets %v, want none stored", store.tickets)
+		}
+	})
+}
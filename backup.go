@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+)
+
+const (
+	BACKUP_TICKET = "ticket"
+	BACKUP_EVENT  = "event"
+)
+
+type BackupRecord struct {
+	Type   string
+	Ticket *Ticket      `json:",omitempty"`
+	Event  *TicketEvent `json:",omitempty"`
+}
+
+type ImportResponse struct {
+	Tickets int
+	Events  int
+}
+
+type Importer interface {
+	ImportTicket(Ticket) error
+}
+
+func (i *InMemoryKitchenStore) ImportTicket(ticket Ticket) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.tickets[ticket.ID]; ok {
+		return fmt.Errorf("ticket with ID = %d already exists", ticket.ID)
+	}
+	i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+
+	return nil
+}
+
+func (k *KitchenServer) exportBackup(w http.ResponseWriter, r *http.Request) {
+	tickets, err := k.store.GetTickets(TicketFilter{IncludeDeleted: true, Limit: math.MaxInt})
+	if err != nil {
+		k.logger.Error("unable to export tickets", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="backup.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	for _, ticket := range tickets {
+		if err := encoder.Encode(BackupRecord{Type: BACKUP_TICKET, Ticket: &ticket}); err != nil {
+			k.logger.Error("unable to write backup", "ticket_id", ticket.ID, "error", err)
+			return
+		}
+
+		events, err := k.store.GetTicketEvents(ticket.ID)
+		if err != nil {
+			k.logger.Error("unable to export ticket events", "ticket_id", ticket.ID, "error", err)
+			return
+		}
+
+		for _, event := range events {
+			if err := encoder.Encode(BackupRecord{Type: BACKUP_EVENT, Event: &event}); err != nil {
+				k.logger.Error("unable to write backup", "ticket_id", ticket.ID, "error", err)
+				return
+			}
+		}
+	}
+}
+
+func (k *KitchenServer) importBackup(w http.ResponseWriter, r *http.Request) {
+	if k.importer == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	if !k.claimNonce(w, r) {
+		return
+	}
+
+	count, err := k.store.CountTickets(TicketFilter{IncludeDeleted: true})
+	if err != nil {
+		k.logger.Error("unable to count tickets", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if count > 0 {
+		k.writeError(w, http.StatusConflict, CODE_STORE_NOT_EMPTY, fmt.Sprintf("store already has %d tickets", count))
+		return
+	}
+
+	tickets, events, err := readBackup(r.Body)
+	if err != nil {
+		k.writeError(w, http.StatusBadRequest, CODE_INVALID_BACKUP, err.Error())
+		return
+	}
+
+	for _, ticket := range tickets {
+		if err := k.importer.ImportTicket(ticket); err != nil {
+			k.logger.Error("unable to import ticket", "ticket_id", ticket.ID, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	for _, event := range events {
+		if err := k.store.StoreTicketEvent(event); err != nil {
+			k.logger.Error("unable to import ticket event", "ticket_id", event.TicketID, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	k.logger.Info("backup imported", "tickets", len(tickets), "events", len(events))
+	k.writeJSON(w, http.StatusOK, ImportResponse{Tickets: len(tickets), Events: len(events)})
+}
+
+func readBackup(body io.Reader) ([]Ticket, []TicketEvent, error) {
+	if body == nil {
+		return nil, nil, errEmptyBody
+	}
+
+	tickets := []Ticket{}
+	events := []TicketEvent{}
+	seen := map[int]bool{}
+
+	d := json.NewDecoder(body)
+	d.DisallowUnknownFields()
+	for line := 1; ; line++ {
+		record := BackupRecord{}
+		err := d.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to decode backup record %d, %v", line, err)
+		}
+
+		switch {
+		case record.Type == BACKUP_TICKET && record.Ticket != nil:
+			if record.Ticket.ID < 1 || seen[record.Ticket.ID] {
+				return nil, nil, fmt.Errorf("backup record %d has invalid or repeated ticket ID %d", line, record.Ticket.ID)
+			}
+			seen[record.Ticket.ID] = true
+			tickets = append(tickets, *record.Ticket)
+		case record.Type == BACKUP_EVENT && record.Event != nil:
+			if !seen[record.Event.TicketID] {
+				return nil, nil, fmt.Errorf("backup record %d is an event for unknown ticket %d", line, record.Event.TicketID)
+			}
+			events = append(events, *record.Event)
+		default:
+			return nil, nil, fmt.Errorf("backup record %d has unknown type %q", line, record.Type)
+		}
+	}
+
+	return tickets, events, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackup(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	newPopulatedServer := func() (*KitchenServer, *InMemoryKitchenStore) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store, WithClock(&StubClock{now}), WithAdmin(true))
+		for _, order := range []string{"order-1", "order-2", "order-3"} {
+			ticket := Ticket{OrderID: order, Notes: "no onions", Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+			server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(ticket))
+		}
+		server.ServeHTTP(httptest.NewRecorder(), newAcceptTicketRequest(1))
+		server.ServeHTTP(httptest.NewRecorder(), newCompleteTicketRequest(1))
+		server.ServeHTTP(httptest.NewRecorder(), newDeleteTicketRequest(3, ""))
+
+		return server, store
+	}
+
+	newImportRequest := func(body string) *http.Request {
+		request, _ := http.NewRequest(http.MethodPost, "/admin/import", strings.NewReader(body))
+		request.Header.Set(nonceHeader, newNonce())
+		return request
+	}
+
+	export := func(t testing.TB, server *KitchenServer) string {
+		t.Helper()
+
+		request, _ := http.NewRequest(http.MethodGet, "/admin/export", nil)
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertHeader(t, response, "Content-Type", ndjsonContentType)
+		return response.Body.String()
+	}
+
+	allTickets := func(store *InMemoryKitchenStore) []Ticket {
+		tickets, _ := store.GetTickets(TicketFilter{IncludeDeleted: true, Limit: 100})
+		sort.Slice(tickets, func(a, b int) bool { return tickets[a].ID < tickets[b].ID })
+		return tickets
+	}
+
+	t.Run("round trips tickets and events into a fresh store", func(t *testing.T) {
+		original, originalStore := newPopulatedServer()
+		backup := export(t, original)
+
+		restoredStore := NewInMemoryKitchenStore()
+		restored := NewKitchenServer(restoredStore, WithAdmin(true))
+		response := httptest.NewRecorder()
+		restored.AdminHandler().ServeHTTP(response, newImportRequest(backup))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		if got, want := allTickets(restoredStore), allTickets(originalStore); !reflect.DeepEqual(got, want) {
+			t.Errorf("got tickets %+v, want %+v", got, want)
+		}
+		for id := 1; id <= 3; id++ {
+			got, _ := restoredStore.GetTicketEvents(id)
+			want, _ := originalStore.GetTicketEvents(id)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got ticket %d events %+v, want %+v", id, got, want)
+			}
+		}
+
+		if id, _ := restoredStore.StoreTicket(Ticket{}); id != 4 {
+			t.Errorf("got new ticket ID %d, want 4", id)
+		}
+	})
+
+	t.Run("exports a restored store identically", func(t *testing.T) {
+		original, _ := newPopulatedServer()
+		backup := export(t, original)
+
+		restored := NewKitchenServer(NewInMemoryKitchenStore(), WithAdmin(true))
+		restored.AdminHandler().ServeHTTP(httptest.NewRecorder(), newImportRequest(backup))
+
+		if got := export(t, restored); got != backup {
+			t.Errorf("got backup %q, want %q", got, backup)
+		}
+	})
+
+	t.Run("refuses to import into a store with tickets", func(t *testing.T) {
+		original, _ := newPopulatedServer()
+		backup := export(t, original)
+
+		response := httptest.NewRecorder()
+		original.AdminHandler().ServeHTTP(response, newImportRequest(backup))
+
+		assertStatus(t, response.Code, http.StatusConflict)
+		assertErrorCode(t, response, CODE_STORE_NOT_EMPTY)
+	})
+
+	t.Run("rejects a malformed backup without importing any of it", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store, WithAdmin(true))
+
+		body := `{"Type": "ticket", "Ticket": {"ID": 1}}` + "\n" + `{"Type": "event", "Event": {"TicketID": 2}}`
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, newImportRequest(body))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_INVALID_BACKUP)
+		if count, _ := store.CountTickets(TicketFilter{IncludeDeleted: true}); count != 0 {
+			t.Errorf("got %d tickets imported, want 0", count)
+		}
+	})
+
+	t.Run("requires the manager role", func(t *testing.T) {
+		server := NewKitchenServer(NewInMemoryKitchenStore(), WithAdmin(true), WithAPIKeys(APIKey{Key: "cook-key", Role: ROLE_COOK}))
+
+		request, _ := http.NewRequest(http.MethodGet, "/admin/export", nil)
+		request.Header.Set("Authorization", "Bearer cook-key")
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusForbidden)
+	})
+
+	t.Run("is hidden without the admin flag", func(t *testing.T) {
+		server := NewKitchenServer(NewInMemoryKitchenStore())
+
+		request, _ := http.NewRequest(http.MethodGet, "/admin/export", nil)
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+type BatchResult struct {
+	Index   int
+	ID      int
+	Status  int
+	Code    ErrorCode
+	Message string
+}
+
+type batchTicket struct {
+	ticket   Ticket
+	original string
+}
+
+func (k *KitchenServer) createTicketBatch(w http.ResponseWriter, r *http.Request) {
+	atomic := r.URL.Query().Get("atomic") == "true"
+
+	d := json.NewDecoder(r.Body)
+	if token, err := d.Token(); err != nil || token != json.Delim('[') {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	results := []BatchResult{}
+	created := []batchTicket{}
+	for d.More() {
+		var item json.RawMessage
+		if len(results) == k.limits.MaxBatchSize || d.Decode(&item) != nil {
+			k.rollbackBatch(r, created)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		result, stored := k.createBatchItem(r, item)
+		result.Index = len(results)
+		if stored == nil && atomic {
+			k.rollbackBatch(r, created)
+			k.writeJSON(w, result.Status, result)
+			return
+		}
+		if stored != nil {
+			created = append(created, *stored)
+		}
+		results = append(results, result)
+	}
+
+	if _, err := d.Token(); err != nil || len(results) == 0 {
+		k.rollbackBatch(r, created)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, c := range created {
+		k.recordCreated(c.ticket.ID, c.ticket)
+		k.auditRedaction(c.ticket, c.original, c.ticket.CreatedAt)
+	}
+
+	k.writeBatchResults(w, results)
+}
+
+func (k *KitchenServer) createBatchItem(r *http.Request, item json.RawMessage) (BatchResult, *batchTicket) {
+	ticket, err := k.parseTicket(bytes.NewReader(item), Ticket{})
+	if err == nil {
+		err = k.checkDependencies(k.storeFor(r), newTicketID, ticket.DependsOn)
+	}
+	if err == nil {
+		err = k.checkAuthorized(r.Context(), ACTION_CREATE, *ticket)
+	}
+	if err != nil {
+		status, code := validationErrorStatus(err)
+		return BatchResult{Status: status, Code: code, Message: err.Error()}, nil
+	}
+
+	if r.Context().Err() != nil {
+		return BatchResult{Status: http.StatusServiceUnavailable, Message: r.Context().Err().Error()}, nil
+	}
+
+	original := k.stampNewTicket(k.storeFor(r), ticket)
+
+	var id int
+	if !k.doWrite(r.Context(), func() { id, err = k.storeFor(r).StoreTicket(*ticket) }) {
+		return BatchResult{Status: http.StatusServiceUnavailable, Message: "write queue full"}, nil
+	}
+	if err != nil {
+		k.logger.Error("unable to store ticket", "error", err)
+		return BatchResult{Status: http.StatusInternalServerError, Message: "unable to store ticket"}, nil
+	}
+
+	ticket.ID = id
+	return BatchResult{ID: id, Status: http.StatusCreated}, &batchTicket{ticket: *ticket, original: original}
+}
+
+func (k *KitchenServer) rollbackBatch(r *http.Request, created []batchTicket) {
+	for _, c := range created {
+		err := k.storeFor(r).RemoveTicket(c.ticket.ID)
+		if err != nil {
+			k.logger.Error("unable to roll back batch ticket", "ticket_id", c.ticket.ID, "error", err)
+		}
+	}
+}
+
+func (k *KitchenServer) writeBatchResults(w http.ResponseWriter, results []BatchResult) {
+	k.writeJSON(w, http.StatusMultiStatus, results)
+}
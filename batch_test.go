@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateTicketBatch(t *testing.T) {
+	t.Run("returns Multi-Status with per item results", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newBatchRequest(`[
+			{"Items": ["burger"]},
+			{"Items": [{"Name": "burger", "Quantity": -1}]},
+			{"Items": ["fries"]}
+		]`))
+
+		assertStatus(t, response.Code, http.StatusMultiStatus)
+
+		got := getBatchResultsFromResponse(t, response)
+		gotCodes := []int{}
+		for i, result := range got {
+			if result.Index != i {
+				t.Errorf("got index %d at position %d", result.Index, i)
+			}
+			gotCodes = append(gotCodes, result.Status)
+		}
+
+		wantCodes := []int{http.StatusCreated, http.StatusBadRequest, http.StatusCreated}
+		if !reflect.DeepEqual(gotCodes, wantCodes) {
+			t.Errorf("got codes %v, want %v", gotCodes, wantCodes)
+		}
+
+		if got[1].Message == "" {
+			t.Errorf("expected a message for the invalid item but didn't get one")
+		}
+		if got[0].ID != 0 || got[2].ID != 1 {
+			t.Errorf("got IDs %d and %d, want 0 and 1", got[0].ID, got[2].ID)
+		}
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets stored, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("returns Bad Request on a body that isn't an array", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newBatchRequest(`{"Items": ["burger"]}`))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("returns Bad Request on an oversized batch", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		items := strings.Repeat(`{"Items": ["burger"]},`, defaultLimits.MaxBatchSize+1)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newBatchRequest("["+strings.TrimSuffix(items, ",")+"]"))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestStreamingTicketBatch(t *testing.T) {
+	t.Run("stores tickets while the body is still being read", func(t *testing.T) {
+		const count = 5000
+		store := &StubKitchenStore{}
+		limits := defaultLimits
+		limits.MaxBatchSize = count
+		server := NewKitchenServer(store, WithLimits(limits))
+
+		storedBeforeLast := 0
+		body := &batchReader{count: count, onItem: func(i int) {
+			if i == count-1 {
+				storedBeforeLast = len(store.tickets)
+			}
+		}}
+		request, _ := http.NewRequest(http.MethodPost, "/ticket/batch", body)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusMultiStatus)
+		if len(store.tickets) != count {
+			t.Errorf("got %d tickets stored, want %d", len(store.tickets), count)
+		}
+		if storedBeforeLast < count-10 {
+			t.Errorf("got %d tickets stored before the last one was read, want the batch stored as it streams", storedBeforeLast)
+		}
+	})
+
+	t.Run("rolls back an atomic batch at the first invalid ticket", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		request, _ := http.NewRequest(http.MethodPost, "/ticket/batch?atomic=true", bytes.NewBufferString(`[
+			{"Items": ["burger"]},
+			{"Items": ["fries"]},
+			{"Items": [{"Name": "burger", "Quantity": -1}]},
+			{"Items": ["salad"]}
+		]`))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+
+		got := BatchResult{}
+		json.NewDecoder(response.Body).Decode(&got)
+		if got.Index != 2 || got.Code != CODE_INVALID_QUANTITY {
+			t.Errorf("got failure %+v, want index 2 with code %s", got, CODE_INVALID_QUANTITY)
+		}
+		if len(store.tickets) != 0 {
+			t.Errorf("got %d tickets stored, want the batch rolled back", len(store.tickets))
+		}
+		if len(store.events) != 0 {
+			t.Errorf("got events %v, want none recorded", store.events)
+		}
+	})
+
+	t.Run("leaves no deleted tickets behind after a rollback", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store)
+
+		request, _ := http.NewRequest(http.MethodPost, "/ticket/batch?atomic=true", bytes.NewBufferString(`[
+			{"Items": ["burger"]},
+			{"Items": [{"Name": "burger", "Quantity": -1}]}
+		]`))
+		server.ServeHTTP(httptest.NewRecorder(), request)
+
+		got, _ := store.GetTickets(TicketFilter{IncludeDeleted: true, Limit: 10})
+		if len(got) != 0 {
+			t.Errorf("got tickets %v, want the rolled back ticket gone", got)
+		}
+	})
+
+	t.Run("rolls back on a body that breaks off midway", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newBatchRequest(`[{"Items": ["burger"]}, {"Items": ["fri`))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		if len(store.tickets) != 0 {
+			t.Errorf("got %d tickets stored, want the batch rolled back", len(store.tickets))
+		}
+		if len(store.events) != 0 {
+			t.Errorf("got events %v, want none recorded", store.events)
+		}
+	})
+}
+
+type batchReader struct {
+	count   int
+	next    int
+	pending []byte
+	onItem  func(i int)
+}
+
+func (b *batchReader) Read(p []byte) (int, error) {
+	if len(b.pending) == 0 {
+		if b.next > b.count {
+			return 0, io.EOF
+		}
+
+		switch {
+		case b.next == b.count:
+			b.pending = []byte("]")
+		case b.next == 0:
+			b.pending = []byte(`[{"Items": ["burger"]}`)
+		default:
+			b.pending = []byte(`,{"Items": ["burger"]}`)
+		}
+		if b.next < b.count {
+			b.onItem(b.next)
+		}
+		b.next++
+	}
+
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+func newBatchRequest(body string) *http.Request {
+	request, _ := http.NewRequest(http.MethodPost, "/ticket/batch", bytes.NewBufferString(body))
+	return request
+}
+
+func getBatchResultsFromResponse(t testing.TB, response *httptest.ResponseRecorder) []BatchResult {
+	t.Helper()
+
+	var results []BatchResult
+	if err := json.NewDecoder(response.Body).Decode(&results); err != nil {
+		t.Fatalf("unable to parse batch results, %v", err)
+	}
+
+	return results
+}
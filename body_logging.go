@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const defaultBodyLogMaxBytes = 4096
+
+type BodyLogging struct {
+	MaxBytes     int
+	RedactFields []string
+}
+
+func (b BodyLogging) Validate() error {
+	if b.MaxBytes <= 0 {
+		return errors.New("body log size cap must be more than 0")
+	}
+
+	return nil
+}
+
+type bodyRecorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	maxBytes int
+}
+
+func (b *bodyRecorder) WriteHeader(status int) {
+	b.status = status
+	b.ResponseWriter.WriteHeader(status)
+}
+
+func (b *bodyRecorder) Write(data []byte) (int, error) {
+	if room := b.maxBytes - b.body.Len(); room > 0 {
+		b.body.Write(data[:min(room, len(data))])
+	}
+
+	return b.ResponseWriter.Write(data)
+}
+
+func (b *bodyRecorder) Unwrap() http.ResponseWriter {
+	return b.ResponseWriter
+}
+
+func isTicketPath(path string) bool {
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "ticket" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k *KitchenServer) logBodies(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if k.bodyLogging == nil || !isTicketPath(r.URL.Path) || isStreamPath(r.URL.Path) || r.URL.Query().Get("stream") == "true" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Body != nil {
+			logged, _ := io.ReadAll(io.LimitReader(r.Body, int64(k.bodyLogging.MaxBytes)))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(logged), r.Body), r.Body}
+
+			k.logger.Debug("request body", "method", r.Method, "path", r.URL.Path, "body", k.bodyRedactor.redact(logged))
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: w, status: http.StatusOK, maxBytes: k.bodyLogging.MaxBytes}
+		next.ServeHTTP(recorder, r)
+
+		k.logger.Debug("response body", "method", r.Method, "path", r.URL.Path, "status", recorder.status, "body", k.bodyRedactor.redact(recorder.body.Bytes()))
+	})
+}
+
+type bodyRedactor struct {
+	fields  map[string]bool
+	pattern *regexp.Regexp
+}
+
+func newBodyRedactor(fields []string) *bodyRedactor {
+	b := &bodyRedactor{fields: map[string]bool{}}
+	if len(fields) == 0 {
+		return b
+	}
+
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		b.fields[strings.ToLower(field)] = true
+		quoted[i] = regexp.QuoteMeta(field)
+	}
+	b.pattern = regexp.MustCompile(`(?i)"(` + strings.Join(quoted, "|") + `)"\s*:\s*("(?:[^"\\]|\\.)*"?|[^,}\]]*)`)
+
+	return b
+}
+
+func (b *bodyRedactor) redact(body []byte) string {
+	if b.pattern == nil || len(body) == 0 {
+		return string(body)
+	}
+
+	var value any
+	if json.Unmarshal(body, &value) == nil {
+		if redacted, err := json.Marshal(b.redactValue(value)); err == nil {
+			return string(redacted)
+		}
+	}
+
+	return b.pattern.ReplaceAllString(string(body), `"$1":"`+redactedText+`"`)
+}
+
+func (b *bodyRedactor) redactValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if b.fields[strings.ToLower(key)] {
+				value[key] = redactedText
+				continue
+			}
+			value[key] = b.redactValue(field)
+		}
+	case []any:
+		for i, element := range value {
+			value[i] = b.redactValue(element)
+		}
+	}
+
+	return value
+}
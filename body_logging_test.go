@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLogging(t *testing.T) {
+	ticket := Ticket{Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}, Notes: "call +44 20 7946 0958"}
+
+	newServer := func(logs *bytes.Buffer, options ...Option) *KitchenServer {
+		logger := slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		return NewKitchenServer(&StubKitchenStore{}, append([]Option{WithLogger(logger)}, options...)...)
+	}
+
+	t.Run("doesn't log bodies by default", func(t *testing.T) {
+		logs := &bytes.Buffer{}
+		server := newServer(logs)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		if strings.Contains(logs.String(), "burger") {
+			t.Errorf("got %q, want no bodies logged", logs.String())
+		}
+	})
+
+	t.Run("logs request and response bodies when enabled", func(t *testing.T) {
+		logs := &bytes.Buffer{}
+		server := newServer(logs, WithBodyLogging(BodyLogging{MaxBytes: defaultBodyLogMaxBytes}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if !strings.Contains(logs.String(), "msg=\"request body\"") || !strings.Contains(logs.String(), "burger") {
+			t.Errorf("got %q, want the request body logged", logs.String())
+		}
+		if !strings.Contains(logs.String(), "msg=\"response body\"") || !strings.Contains(logs.String(), "status=202") {
+			t.Errorf("got %q, want the response body logged", logs.String())
+		}
+	})
+
+	t.Run("still hands the whole body to the handler", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		server := NewKitchenServer(store, WithLogger(logger), WithBodyLogging(BodyLogging{MaxBytes: 8}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if len(store.tickets) != 1 || store.tickets[0].Items[0].Name != "burger" {
+			t.Errorf("got tickets %v, want the burger ticket stored", store.tickets)
+		}
+	})
+
+	t.Run("caps logged bodies at the size limit", func(t *testing.T) {
+		logs := &bytes.Buffer{}
+		server := newServer(logs, WithBodyLogging(BodyLogging{MaxBytes: 8}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		if strings.Contains(logs.String(), "burger") {
+			t.Errorf("got %q, want bodies cut to 8 bytes", logs.String())
+		}
+	})
+
+	t.Run("masks redacted fields", func(t *testing.T) {
+		logs := &bytes.Buffer{}
+		server := newServer(logs, WithBodyLogging(BodyLogging{MaxBytes: defaultBodyLogMaxBytes, RedactFields: []string{"notes"}}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		if strings.Contains(logs.String(), "7946") {
+			t.Errorf("got %q, want Notes masked", logs.String())
+		}
+		if !strings.Contains(logs.String(), redactedText) || !strings.Contains(logs.String(), "burger") {
+			t.Errorf("got %q, want the body logged with Notes masked", logs.String())
+		}
+	})
+
+	t.Run("masks redacted fields in a cut off body", func(t *testing.T) {
+		redactor := newBodyRedactor([]string{"Notes"})
+
+		got := redactor.redact([]byte(`{"Items":["burger"],"Notes":"call +44 20 79`))
+
+		want := `{"Items":["burger"],"Notes":"` + redactedText + `"`
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	ticket   Ticket
+	cachedAt time.Time
+}
+
+type CachingKitchenStore struct {
+	KitchenStore
+	clock   Clock
+	size    int
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[int]*list.Element
+	order   *list.List
+	version int
+}
+
+func NewCachingKitchenStore(store KitchenStore, size int, ttl time.Duration) *CachingKitchenStore {
+	return &CachingKitchenStore{
+		KitchenStore: store,
+		clock:        realClock{},
+		size:         size,
+		ttl:          ttl,
+		entries:      map[int]*list.Element{},
+		order:        list.New(),
+	}
+}
+
+// Unwrap returns the store the cache reads through to.
+func (c *CachingKitchenStore) Unwrap() KitchenStore {
+	return c.KitchenStore
+}
+
+func (c *CachingKitchenStore) GetTicketByID(ticketID int) (Ticket, error) {
+	c.mu.Lock()
+	if element, ok := c.entries[ticketID]; ok {
+		entry := element.Value.(cacheEntry)
+		if c.ttl <= 0 || c.clock.Now().Sub(entry.cachedAt) < c.ttl {
+			c.order.MoveToFront(element)
+			c.mu.Unlock()
+			return entry.ticket, nil
+		}
+		c.remove(element)
+	}
+	version := c.version
+	c.mu.Unlock()
+
+	ticket, err := c.KitchenStore.GetTicketByID(ticketID)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version == c.version {
+		c.add(ticket)
+	}
+
+	return ticket, nil
+}
+
+func (c *CachingKitchenStore) UpdateTicket(ticket Ticket) error {
+	defer c.invalidate(ticket.ID)
+	return c.KitchenStore.UpdateTicket(ticket)
+}
+
+func (c *CachingKitchenStore) UpdateTicketWithOutbox(ticket Ticket, event TicketEvent) error {
+	writer, ok := c.KitchenStore.(OutboxWriter)
+	if !ok {
+		return errNoOutboxWriter
+	}
+
+	defer c.invalidate(ticket.ID)
+	return writer.UpdateTicketWithOutbox(ticket, event)
+}
+
+func (c *CachingKitchenStore) StoreTicketWithOutbox(ticket Ticket, events []TicketEvent) (int, error) {
+	writer, ok := c.KitchenStore.(OutboxWriter)
+	if !ok {
+		return 0, errNoOutboxWriter
+	}
+
+	return writer.StoreTicketWithOutbox(ticket, events)
+}
+
+func (c *CachingKitchenStore) StoreTicketIfNotExistsWithOutbox(ticket Ticket, events []TicketEvent) (Ticket, bool, error) {
+	writer, ok := c.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, false, errNoOutboxWriter
+	}
+
+	return writer.StoreTicketIfNotExistsWithOutbox(ticket, events)
+}
+
+func (c *CachingKitchenStore) DeleteTicketWithOutbox(ticketID int, deletedAt time.Time, event TicketEvent) error {
+	writer, ok := c.KitchenStore.(OutboxWriter)
+	if !ok {
+		return errNoOutboxWriter
+	}
+
+	defer c.invalidate(ticketID)
+	return writer.DeleteTicketWithOutbox(ticketID, deletedAt, event)
+}
+
+func (c *CachingKitchenStore) ClaimNextTicketWithOutbox(filter TicketFilter, claim Claim, event TicketEvent) (Ticket, bool, error) {
+	writer, ok := c.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, false, errNoOutboxWriter
+	}
+
+	ticket, found, err := writer.ClaimNextTicketWithOutbox(filter, claim, event)
+	if found {
+		c.invalidate(ticket.ID)
+	}
+
+	return ticket, found, err
+}
+
+func (c *CachingKitchenStore) MoveTicketWithOutbox(ticketID int, delta int, movedAt time.Time, event TicketEvent) (Ticket, error) {
+	writer, ok := c.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, errNoOutboxWriter
+	}
+
+	defer c.invalidate(ticketID)
+	return writer.MoveTicketWithOutbox(ticketID, delta, movedAt, event)
+}
+
+func (c *CachingKitchenStore) ExpireTicketWithOutbox(ticketID int, now time.Time, event TicketEvent) (Ticket, bool, error) {
+	writer, ok := c.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, false, errNoOutboxWriter
+	}
+
+	defer c.invalidate(ticketID)
+	return writer.ExpireTicketWithOutbox(ticketID, now, event)
+}
+
+func (c *CachingKitchenStore) DeleteTicket(ticketID int, deletedAt time.Time) error {
+	defer c.invalidate(ticketID)
+	return c.KitchenStore.DeleteTicket(ticketID, deletedAt)
+}
+
+func (c *CachingKitchenStore) RemoveTicket(ticketID int) error {
+	defer c.invalidate(ticketID)
+	return c.KitchenStore.RemoveTicket(ticketID)
+}
+
+func (c *CachingKitchenStore) ClaimNextTicket(filter TicketFilter, claim Claim) (Ticket, bool, error) {
+	ticket, found, err := c.KitchenStore.ClaimNextTicket(filter, claim)
+	if found {
+		c.invalidate(ticket.ID)
+	}
+
+	return ticket, found, err
+}
+
+func (c *CachingKitchenStore) MoveTicket(ticketID int, delta int, movedAt time.Time) (Ticket, error) {
+	defer c.invalidate(ticketID)
+	return c.KitchenStore.MoveTicket(ticketID, delta, movedAt)
+}
+
+func (c *CachingKitchenStore) ExpireTicket(ticketID int, now time.Time) (Ticket, bool, error) {
+	defer c.invalidate(ticketID)
+	return c.KitchenStore.ExpireTicket(ticketID, now)
+}
+
+func (c *CachingKitchenStore) PurgeCompletedBefore(before, now time.Time) (int, error) {
+	defer c.invalidateAll()
+	return c.KitchenStore.PurgeCompletedBefore(before, now)
+}
+
+func (c *CachingKitchenStore) add(ticket Ticket) {
+	if c.size <= 0 {
+		return
+	}
+
+	if element, ok := c.entries[ticket.ID]; ok {
+		c.remove(element)
+	}
+
+	c.entries[ticket.ID] = c.order.PushFront(cacheEntry{ticket: ticket, cachedAt: c.clock.Now()})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *CachingKitchenStore) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(cacheEntry).ticket.ID)
+}
+
+func (c *CachingKitchenStore) invalidate(ticketID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	if element, ok := c.entries[ticketID]; ok {
+		c.remove(element)
+	}
+}
+
+func (c *CachingKitchenStore) invalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	c.entries = map[int]*list.Element{}
+	c.order.Init()
+}
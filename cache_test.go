@@ -0,0 +1,123 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type CountingKitchenStore struct {
+	*StubKitchenStore
+	reads int
+}
+
+func (c *CountingKitchenStore) GetTicketByID(ticketID int) (Ticket, error) {
+	c.reads++
+	return c.StubKitchenStore.GetTicketByID(ticketID)
+}
+
+func TestCachingKitchenStore(t *testing.T) {
+	newStore := func() *CountingKitchenStore {
+		return &CountingKitchenStore{StubKitchenStore: &StubKitchenStore{
+			tickets: []Ticket{{ID: 0, Status: STATUS_PENDING}, {ID: 1, Status: STATUS_PENDING}, {ID: 2, Status: STATUS_PENDING}},
+		}}
+	}
+
+	t.Run("serves repeated reads from the cache", func(t *testing.T) {
+		store := newStore()
+		cache := NewCachingKitchenStore(store, 10, time.Minute)
+
+		cache.GetTicketByID(1)
+		got, _ := cache.GetTicketByID(1)
+
+		assertTicket(t, got, store.tickets[1])
+		assertReads(t, store.reads, 1)
+	})
+
+	t.Run("busts the entry on update", func(t *testing.T) {
+		store := newStore()
+		cache := NewCachingKitchenStore(store, 10, time.Minute)
+
+		cache.GetTicketByID(1)
+		cache.UpdateTicket(Ticket{ID: 1, Status: STATUS_ACCEPTED})
+		got, _ := cache.GetTicketByID(1)
+
+		if got.Status != STATUS_ACCEPTED {
+			t.Errorf("got status %v, want %v", got.Status, STATUS_ACCEPTED)
+		}
+		assertReads(t, store.reads, 2)
+	})
+
+	t.Run("expires entries after the TTL", func(t *testing.T) {
+		store := newStore()
+		clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+		cache := NewCachingKitchenStore(store, 10, time.Minute)
+		cache.clock = clock
+
+		cache.GetTicketByID(1)
+		clock.Advance(time.Minute)
+		cache.GetTicketByID(1)
+
+		assertReads(t, store.reads, 2)
+	})
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		store := newStore()
+		cache := NewCachingKitchenStore(store, 2, time.Minute)
+
+		cache.GetTicketByID(0)
+		cache.GetTicketByID(1)
+		cache.GetTicketByID(0)
+		cache.GetTicketByID(2)
+		store.reads = 0
+
+		cache.GetTicketByID(0)
+		cache.GetTicketByID(1)
+
+		assertReads(t, store.reads, 1)
+	})
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		cache := NewCachingKitchenStore(NewInMemoryKitchenStore(), 2, time.Minute)
+		id, _ := cache.StoreTicket(Ticket{Status: STATUS_PENDING})
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				cache.GetTicketByID(id)
+			}()
+			go func() {
+				defer wg.Done()
+				cache.UpdateTicket(Ticket{ID: id, Status: STATUS_ACCEPTED})
+			}()
+		}
+		wg.Wait()
+
+		got, _ := cache.GetTicketByID(id)
+		if got.Status != STATUS_ACCEPTED {
+			t.Errorf("got status %v, want %v", got.Status, STATUS_ACCEPTED)
+		}
+	})
+
+	t.Run("keeps the importer and outbox of the store it wraps", func(t *testing.T) {
+		cache := NewCachingKitchenStore(NewInMemoryKitchenStore(), 2, time.Minute)
+		server := NewKitchenServer(cache, WithOutbox(true))
+
+		if server.importer == nil {
+			t.Errorf("expected the importer to be found behind the cache but it wasn't")
+		}
+		if server.outbox == nil {
+			t.Errorf("expected the outbox to be found behind the cache but it wasn't")
+		}
+	})
+}
+
+func assertReads(t testing.TB, got, want int) {
+	t.Helper()
+
+	if got != want {
+		t.Errorf("got %d reads from the store, want %d", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+type ChaosConfig struct {
+	DelayPercent float64
+	Delay        time.Duration
+	FailPercent  float64
+}
+
+func (c ChaosConfig) Validate() error {
+	if c.DelayPercent < 0 || c.DelayPercent > 100 || c.FailPercent < 0 || c.FailPercent > 100 {
+		return fmt.Errorf("chaos percentages must be between 0 and 100, got delay %v and fail %v", c.DelayPercent, c.FailPercent)
+	}
+
+	if c.Delay < 0 {
+		return fmt.Errorf("chaos delay can't be negative, got %v", c.Delay)
+	}
+
+	return nil
+}
+
+func chance(percent float64) bool {
+	return rand.Float64()*100 < percent
+}
+
+func (k *KitchenServer) injectChaos(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isProbePath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if chance(k.chaos.DelayPercent) {
+			select {
+			case <-time.After(k.chaos.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if chance(k.chaos.FailPercent) {
+			k.setRetryAfter(w, time.Second)
+			k.writeError(w, http.StatusServiceUnavailable, CODE_INJECTED_FAILURE, "injected failure")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
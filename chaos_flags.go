@@ -0,0 +1,30 @@
+//go:build chaos
+
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+)
+
+func chaosFlags() func() []Option {
+	enabled := flag.Bool("chaos", false, "inject latency and failures into requests for resilience testing")
+	delayPercent := flag.Float64("chaos-delay-percent", 10, "percentage of requests delayed by -chaos-delay")
+	delay := flag.Duration("chaos-delay", 500*time.Millisecond, "latency added to delayed requests")
+	failPercent := flag.Float64("chaos-fail-percent", 5, "percentage of requests failed with 503")
+
+	return func() []Option {
+		if !*enabled {
+			return nil
+		}
+
+		config := ChaosConfig{DelayPercent: *delayPercent, Delay: *delay, FailPercent: *failPercent}
+		if err := config.Validate(); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("chaos enabled, delaying %v%% and failing %v%% of requests", config.DelayPercent, config.FailPercent)
+
+		return []Option{WithChaos(config)}
+	}
+}
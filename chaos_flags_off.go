@@ -0,0 +1,7 @@
+//go:build !chaos
+
+package main
+
+func chaosFlags() func() []Option {
+	return func() []Option { return nil }
+}
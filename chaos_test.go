@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChaos(t *testing.T) {
+	ticket := Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+
+	t.Run("fails every request at 100%", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithChaos(ChaosConfig{FailPercent: 100}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+		assertHeader(t, response, "Retry-After", "1")
+		assertErrorCode(t, response, CODE_INJECTED_FAILURE)
+		if len(store.tickets) != 0 {
+			t.Errorf("got tickets %v, want none stored", store.tickets)
+		}
+	})
+
+	t.Run("behaves normally at 0%", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithChaos(ChaosConfig{DelayPercent: 0, Delay: time.Hour, FailPercent: 0}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+	})
+
+	t.Run("delays every request at 100%", func(t *testing.T) {
+		delay := 20 * time.Millisecond
+		server := NewKitchenServer(&StubKitchenStore{}, WithChaos(ChaosConfig{DelayPercent: 100, Delay: delay}))
+
+		start := time.Now()
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest(""))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if elapsed := time.Since(start); elapsed < delay {
+			t.Errorf("got response after %v, want at least %v", elapsed, delay)
+		}
+	})
+
+	t.Run("leaves health checks alone", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithChaos(ChaosConfig{FailPercent: 100}))
+
+		request, _ := http.NewRequest(http.MethodGet, healthzPath, nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("rejects percentages over 100", func(t *testing.T) {
+		if err := (ChaosConfig{FailPercent: 101}).Validate(); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+}
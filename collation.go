@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+func stringLess(r *http.Request) func(a, b string) bool {
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return func(a, b string) bool { return a < b }
+	}
+
+	collator := collate.New(tags[0])
+
+	return func(a, b string) bool { return collator.CompareString(a, b) < 0 }
+}
+
+func sortTicketItems(tickets []Ticket, less func(a, b string) bool) {
+	for i := range tickets {
+		items := make(Items, len(tickets[i].Items))
+		copy(items, tickets[i].Items)
+		sort.SliceStable(items, func(a, b int) bool {
+			return less(items[a].Name, items[b].Name)
+		})
+		tickets[i].Items = items
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSortTicketItems(t *testing.T) {
+	store := &StubKitchenStore{
+		tickets: []Ticket{{ID: 1, Items: []Item{
+			{Name: "zucchini", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "ärtsoppa", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "éclair", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "apple", Quantity: 1, Unit: UNIT_EACH},
+		}}},
+	}
+	server := NewKitchenServer(store)
+
+	cases := []struct {
+		language string
+		want     []string
+	}{
+		{"", []string{"apple", "zucchini", "ärtsoppa", "éclair"}},
+		{"de-DE,de;q=0.9", []string{"apple", "ärtsoppa", "éclair", "zucchini"}},
+		{"sv-SE", []string{"apple", "éclair", "zucchini", "ärtsoppa"}},
+	}
+
+	for _, c := range cases {
+		t.Run("sorts items for Accept-Language "+c.language, func(t *testing.T) {
+			request := newListTicketsRequest("?sortItems=true")
+			if c.language != "" {
+				request.Header.Set("Accept-Language", c.language)
+			}
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusOK)
+			got := []string{}
+			for _, item := range getTicketPageFromResponse(t, response.Body).Tickets[0].Items {
+				got = append(got, item.Name)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got items %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	t.Run("leaves stored items in their original order", func(t *testing.T) {
+		request := newListTicketsRequest("?sortItems=true")
+		server.ServeHTTP(httptest.NewRecorder(), request)
+
+		if got := store.tickets[0].Items[0].Name; got != "zucchini" {
+			t.Errorf("got first stored item %q, want %q", got, "zucchini")
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	clientIDHeader   = "X-Client-ID"
+	maxDedupBodySize = 1 << 20
+)
+
+type dedupEntry struct {
+	ticketID int
+	seenAt   time.Time
+}
+
+type dedupCache struct {
+	mu      sync.Mutex
+	entries map[string]dedupEntry
+}
+
+func newDedupCache() *dedupCache {
+	return &dedupCache{entries: map[string]dedupEntry{}}
+}
+
+func (d *dedupCache) lookup(key string, now time.Time, window time.Duration) (int, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.entries[key]
+	if !ok || now.Sub(entry.seenAt) >= window {
+		return 0, false
+	}
+
+	return entry.ticketID, true
+}
+
+func (d *dedupCache) record(key string, ticketID int, now time.Time, window time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for k, entry := range d.entries {
+		if now.Sub(entry.seenAt) >= window {
+			delete(d.entries, k)
+		}
+	}
+
+	d.entries[key] = dedupEntry{ticketID: ticketID, seenAt: now}
+}
+
+// dedupKey hashes a create request for the dedup window. Only requests that
+// identify their client with an API key or an X-Client-ID header are
+// deduplicated, since clients behind a shared NAT or proxy share an address.
+func (k *KitchenServer) dedupKey(w http.ResponseWriter, r *http.Request) (string, bool, error) {
+	if k.dedupWindow <= 0 {
+		return "", false, nil
+	}
+
+	client, ok := dedupClient(k, r)
+	if !ok {
+		return "", false, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDedupBodySize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return "", false, fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit)
+	}
+	if err != nil {
+		return "", false, nil
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	normalized := &bytes.Buffer{}
+	if json.Compact(normalized, body) != nil {
+		return "", false, nil
+	}
+
+	kitchenID, _ := r.Context().Value(kitchenIDKey{}).(string)
+	hash := sha256.New()
+	for _, part := range []string{client, kitchenID, r.URL.RawQuery} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(normalized.Bytes())
+
+	return hex.EncodeToString(hash.Sum(nil)), true, nil
+}
+
+func dedupClient(k *KitchenServer, r *http.Request) (string, bool) {
+	if key, ok := k.apiKeyFromRequest(r); ok {
+		return "key:" + key, true
+	}
+
+	if id := r.Header.Get(clientIDHeader); id != "" {
+		return "client:" + id, true
+	}
+
+	return "", false
+}
+
+func (k *KitchenServer) serveDuplicate(w http.ResponseWriter, r *http.Request, key string) bool {
+	ticketID, ok := k.dedup.lookup(key, k.clock.Now(), k.dedupWindow)
+	if !ok {
+		return false
+	}
+
+	ticket, err := k.storeFor(r).GetTicketByID(ticketID)
+	if err != nil {
+		return false
+	}
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+	return true
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDedupWindow = 2 * time.Second
+
+func newClientPostRequest(client, body string) *http.Request {
+	request := newRawPostRequest("/ticket/", body)
+	request.Header.Set(clientIDHeader, client)
+	return request
+}
+
+func TestDedupWindow(t *testing.T) {
+	newServer := func() (*KitchenServer, *StubKitchenStore, *StubClock) {
+		store := &StubKitchenStore{}
+		clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+		return NewKitchenServer(store, WithClock(clock), WithDedupWindow(testDedupWindow)), store, clock
+	}
+
+	t.Run("returns the original ticket for a rapid duplicate", func(t *testing.T) {
+		server, store, clock := newServer()
+
+		server.ServeHTTP(httptest.NewRecorder(), newClientPostRequest("till-1", `{"Items": ["burger"]}`))
+		clock.now = clock.now.Add(500 * time.Millisecond)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newClientPostRequest("till-1", `{ "Items" : [ "burger" ] }`))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if len(store.tickets) != 1 {
+			t.Errorf("got %d tickets stored, want 1", len(store.tickets))
+		}
+
+		got := TicketResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+		if got.ID != store.tickets[0].ID {
+			t.Errorf("got ticket %d, want original ticket %d", got.ID, store.tickets[0].ID)
+		}
+	})
+
+	t.Run("creates a new ticket once the window has passed", func(t *testing.T) {
+		server, store, clock := newServer()
+
+		server.ServeHTTP(httptest.NewRecorder(), newClientPostRequest("till-1", `{"Items": ["burger"]}`))
+		clock.now = clock.now.Add(testDedupWindow)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newClientPostRequest("till-1", `{"Items": ["burger"]}`))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets stored, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("creates a ticket for a different client", func(t *testing.T) {
+		server, store, _ := newServer()
+
+		server.ServeHTTP(httptest.NewRecorder(), newClientPostRequest("till-1", `{"Items": ["burger"]}`))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newClientPostRequest("till-2", `{"Items": ["burger"]}`))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets stored, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("creates a ticket for a different payload", func(t *testing.T) {
+		server, store, _ := newServer()
+
+		server.ServeHTTP(httptest.NewRecorder(), newClientPostRequest("till-1", `{"Items": ["burger"]}`))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newClientPostRequest("till-1", `{"Items": ["fries"]}`))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets stored, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("creates a ticket for a client without an identifier", func(t *testing.T) {
+		server, store, _ := newServer()
+
+		server.ServeHTTP(httptest.NewRecorder(), newRawPostRequest("/ticket/", `{"Items": ["burger"]}`))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRawPostRequest("/ticket/", `{"Items": ["burger"]}`))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets stored, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("rejects an oversized body", func(t *testing.T) {
+		server, store, _ := newServer()
+
+		body := `{"Items": ["burger"], "Note": "` + strings.Repeat("a", maxDedupBodySize) + `"}`
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newClientPostRequest("till-1", body))
+
+		assertStatus(t, response.Code, http.StatusRequestEntityTooLarge)
+		assertErrorCode(t, response, CODE_BODY_TOO_LARGE)
+		if len(store.tickets) != 0 {
+			t.Errorf("got %d tickets stored, want 0", len(store.tickets))
+		}
+	})
+
+	t.Run("is off by default", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		server.ServeHTTP(httptest.NewRecorder(), newClientPostRequest("till-1", `{"Items": ["burger"]}`))
+		server.ServeHTTP(httptest.NewRecorder(), newClientPostRequest("till-1", `{"Items": ["burger"]}`))
+
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets stored, want 2", len(store.tickets))
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+func ticketETag(ticket Ticket) string {
+	data, _ := json.Marshal(ticket)
+	sum := sha256.Sum256(data)
+
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+func ifMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k *KitchenServer) deleteTicket(w http.ResponseWriter, r *http.Request) {
+	ticketID, ok := k.pathTicketID(w, strings.TrimPrefix(r.URL.Path, "/ticket/"))
+	if !ok {
+		return
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" && k.requireIfMatch {
+		w.WriteHeader(http.StatusPreconditionRequired)
+		return
+	}
+
+	store := k.storeFor(r)
+	ticket, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if ifMatch != "" && !ifMatches(ifMatch, ticketETag(ticket)) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_DELETE, ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	err = k.tombstoneTicket(store, ticketID, now, TicketEvent{
+		Type:       EVENT_DELETED,
+		TicketID:   ticketID,
+		Status:     ticket.Status,
+		OccurredAt: now,
+	})
+	if err != nil {
+		k.logger.Error("unable to delete ticket", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeleteTicket(t *testing.T) {
+	ticket := Ticket{ID: 1, Status: STATUS_PENDING, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{tickets: []Ticket{ticket}}
+	}
+
+	t.Run("serves the ticket ETag", func(t *testing.T) {
+		server := NewKitchenServer(newStore())
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(1))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertHeader(t, response, "ETag", ticketETag(ticket))
+	})
+
+	t.Run("deletes ticket on matching If-Match", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newDeleteTicketRequest(1, ticketETag(ticket)))
+
+		assertStatus(t, response.Code, http.StatusNoContent)
+		if len(store.tickets) != 0 {
+			t.Errorf("got tickets %v, want ticket deleted", store.tickets)
+		}
+	})
+
+	t.Run("returns Precondition Failed on changed ticket", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store)
+
+		changed := ticket
+		changed.Notes = "no onions"
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newDeleteTicketRequest(1, ticketETag(changed)))
+
+		assertStatus(t, response.Code, http.StatusPreconditionFailed)
+		if len(store.tickets) != 1 {
+			t.Errorf("got tickets %v, want ticket kept", store.tickets)
+		}
+	})
+
+	t.Run("deletes without If-Match by default", func(t *testing.T) {
+		server := NewKitchenServer(newStore())
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newDeleteTicketRequest(1, ""))
+
+		assertStatus(t, response.Code, http.StatusNoContent)
+	})
+
+	t.Run("returns Precondition Required without If-Match when required", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithRequireIfMatchOnDelete(true))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newDeleteTicketRequest(1, ""))
+
+		assertStatus(t, response.Code, http.StatusPreconditionRequired)
+		if len(store.tickets) != 1 {
+			t.Errorf("got tickets %v, want ticket kept", store.tickets)
+		}
+	})
+
+	t.Run("deletes on wildcard If-Match", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithRequireIfMatchOnDelete(true))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newDeleteTicketRequest(1, "*"))
+
+		assertStatus(t, response.Code, http.StatusNoContent)
+	})
+
+	t.Run("returns Not Found on nonexistant ticket ID", func(t *testing.T) {
+		server := NewKitchenServer(newStore())
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newDeleteTicketRequest(2, "*"))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func newDeleteTicketRequest(ticketID int, ifMatch string) *http.Request {
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/ticket/%d", ticketID), nil)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	return req
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const newTicketID = -1
+
+var (
+	errDependencyCycle   = errors.New("dependency cycle")
+	errUnknownDependency = errors.New("unknown dependency")
+)
+
+func (k *KitchenServer) checkDependencies(store KitchenStore, ticketID int, dependsOn []int) error {
+	for _, id := range dependsOn {
+		if id == ticketID {
+			return fmt.Errorf("%w, ticket %d depends on itself", errDependencyCycle, id)
+		}
+
+		if _, err := store.GetTicketByID(id); err != nil {
+			return fmt.Errorf("%w, no ticket with ID = %d", errUnknownDependency, id)
+		}
+	}
+
+	visiting := map[int]bool{}
+	visited := map[int]bool{}
+
+	var visit func(id int) error
+	visit = func(id int) error {
+		if id == ticketID || visiting[id] {
+			return fmt.Errorf("%w through ticket %d", errDependencyCycle, id)
+		}
+		if visited[id] {
+			return nil
+		}
+
+		visiting[id] = true
+		if ticket, err := store.GetTicketByID(id); err == nil {
+			for _, dependency := range ticket.DependsOn {
+				if err := visit(dependency); err != nil {
+					return err
+				}
+			}
+		}
+		visiting[id] = false
+		visited[id] = true
+
+		return nil
+	}
+
+	for _, id := range dependsOn {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func incompleteDependencies(store KitchenStore, ticket Ticket) ([]int, error) {
+	if len(ticket.DependsOn) == 0 {
+		return nil, nil
+	}
+
+	dependencies, err := store.GetTicketsByIDs(ticket.DependsOn)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := map[int]bool{}
+	for _, dependency := range dependencies {
+		if dependency.Status == STATUS_COMPLETED {
+			completed[dependency.ID] = true
+		}
+	}
+
+	incomplete := []int{}
+	for _, id := range ticket.DependsOn {
+		if !completed[id] {
+			incomplete = append(incomplete, id)
+		}
+	}
+
+	return incomplete, nil
+}
+
+func blockedTicketIDs(store KitchenStore, filter TicketFilter) ([]int, error) {
+	filter.Limit = math.MaxInt
+	candidates, err := store.GetTickets(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := []int{}
+	for _, ticket := range candidates {
+		incomplete, err := incompleteDependencies(store, ticket)
+		if err != nil {
+			return nil, err
+		}
+		if len(incomplete) > 0 {
+			blocked = append(blocked, ticket.ID)
+		}
+	}
+
+	return blocked, nil
+}
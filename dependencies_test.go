@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTicketDependencies(t *testing.T) {
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	items := []Item{{Name: "soup", Quantity: 1, Unit: UNIT_EACH}}
+
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Station: "pass", Status: STATUS_PENDING, Items: items},
+				{ID: 2, Station: "pass", Status: STATUS_PENDING, Items: items, DependsOn: []int{1}},
+			},
+		}
+	}
+
+	t.Run("returns Conflict accepting a ticket with open dependencies", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newAcceptTicketRequest(2))
+
+		assertStatus(t, response.Code, http.StatusConflict)
+		assertErrorCode(t, response, CODE_DEPENDENCIES_INCOMPLETE)
+		if len(store.events) != 0 {
+			t.Errorf("got events %v, want none recorded", store.events)
+		}
+	})
+
+	t.Run("accepts a ticket once its dependencies are completed", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(1))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newAcceptTicketRequest(2))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if got := getTicketFromResponse(t, response.Body); got.Status != STATUS_ACCEPTED {
+			t.Errorf("got status %v, want %v", got.Status, STATUS_ACCEPTED)
+		}
+	})
+
+	t.Run("skips blocked tickets when claiming the next one", func(t *testing.T) {
+		store := &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Station: "grill", Status: STATUS_PENDING, DependsOn: []int{3}},
+				{ID: 2, Station: "grill", Status: STATUS_PENDING},
+				{ID: 3, Station: "fryer", Status: STATUS_PENDING},
+			},
+		}
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newNextTicketRequest("grill"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if got := getTicketFromResponse(t, response.Body); got.ID != 2 {
+			t.Errorf("got ticket %d, want ticket 2", got.ID)
+		}
+	})
+
+	t.Run("keeps a blocked new ticket pending", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock), WithDefaultStatus(STATUS_ACCEPTED))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(Ticket{Items: items, DependsOn: []int{1}}))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if got := store.tickets[len(store.tickets)-1]; got.Status != STATUS_PENDING {
+			t.Errorf("got status %v, want %v", got.Status, STATUS_PENDING)
+		}
+	})
+
+	t.Run("returns Unprocessable Entity on an unknown dependency", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(Ticket{Items: items, DependsOn: []int{7}}))
+
+		assertStatus(t, response.Code, http.StatusUnprocessableEntity)
+		assertErrorCode(t, response, CODE_UNKNOWN_DEPENDENCY)
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("returns Unprocessable Entity creating a ticket on a cycle", func(t *testing.T) {
+		store := newStore()
+		store.tickets[0].DependsOn = []int{2}
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(Ticket{Items: items, DependsOn: []int{2}}))
+
+		assertStatus(t, response.Code, http.StatusUnprocessableEntity)
+		assertErrorCode(t, response, CODE_DEPENDENCY_CYCLE)
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("returns Unprocessable Entity patching in a cycle", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(1, `{"DependsOn": [2]}`))
+
+		assertStatus(t, response.Code, http.StatusUnprocessableEntity)
+		assertErrorCode(t, response, CODE_DEPENDENCY_CYCLE)
+		if got := store.tickets[0].DependsOn; len(got) != 0 {
+			t.Errorf("got dependencies %v, want none", got)
+		}
+	})
+}
+
+func newAcceptTicketRequest(ticketID int) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/%d/accept", ticketID), nil)
+	return req
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const maxDisplayIDWidth = 20
+
+type IDFormat struct {
+	Prefix string
+	Width  int
+}
+
+func (f IDFormat) Validate() error {
+	if f.Width < 0 || f.Width > maxDisplayIDWidth {
+		return fmt.Errorf("display ID width must be between 0 and %d, got %d", maxDisplayIDWidth, f.Width)
+	}
+
+	if strings.ContainsFunc(f.Prefix, func(r rune) bool { return !unicode.IsPrint(r) || unicode.IsSpace(r) }) {
+		return fmt.Errorf("display ID prefix %q must be printable without spaces", f.Prefix)
+	}
+
+	return nil
+}
+
+func (f IDFormat) Format(id int) string {
+	return fmt.Sprintf("%s%0*d", f.Prefix, f.Width, id)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIDFormat(t *testing.T) {
+	cases := []struct {
+		name   string
+		format IDFormat
+		id     int
+		want   string
+	}{
+		{"the bare ID by default", IDFormat{}, 123, "123"},
+		{"a prefixed zero padded ID", IDFormat{Prefix: "LON-", Width: 6}, 123, "LON-000123"},
+		{"an ID wider than the padding", IDFormat{Prefix: "NYC", Width: 2}, 12345, "NYC12345"},
+	}
+
+	for _, c := range cases {
+		t.Run("formats "+c.name, func(t *testing.T) {
+			if got := c.format.Format(c.id); got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+
+	t.Run("rejects a negative width", func(t *testing.T) {
+		if err := (IDFormat{Width: -1}).Validate(); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("rejects a prefix with spaces", func(t *testing.T) {
+		if err := (IDFormat{Prefix: "LON "}).Validate(); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("returns the display ID with the ticket", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 42, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}}}
+		server := NewKitchenServer(store, WithIDFormat(IDFormat{Prefix: "LON-", Width: 6}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(42))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := TicketResponse{}
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("unable to parse response %v", err)
+		}
+
+		if got.DisplayID != "LON-000042" {
+			t.Errorf("got display ID %q, want %q", got.DisplayID, "LON-000042")
+		}
+
+		if got.ID != 42 {
+			t.Errorf("got ID %d, want 42", got.ID)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+type DuplicateItems int
+
+const (
+	DUPLICATES_ALLOW DuplicateItems = iota
+	DUPLICATES_REJECT
+	DUPLICATES_MERGE
+)
+
+var duplicateItemsModes = map[string]DuplicateItems{
+	"allow":  DUPLICATES_ALLOW,
+	"reject": DUPLICATES_REJECT,
+	"merge":  DUPLICATES_MERGE,
+}
+
+var errDuplicateItem = errors.New("duplicate item")
+
+func ParseDuplicateItems(name string) (DuplicateItems, error) {
+	mode, ok := duplicateItemsModes[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown duplicate items mode %q", name)
+	}
+
+	return mode, nil
+}
+
+func (k *KitchenServer) handleDuplicateItems(items Items) (Items, error) {
+	switch k.duplicateItems {
+	case DUPLICATES_REJECT:
+		seen := map[string]bool{}
+		for _, item := range items {
+			if seen[item.Name] {
+				return nil, fmt.Errorf("%w %q", errDuplicateItem, item.Name)
+			}
+			seen[item.Name] = true
+		}
+	case DUPLICATES_MERGE:
+		return mergeDuplicateItems(items), nil
+	}
+
+	return items, nil
+}
+
+func mergeDuplicateItems(items Items) Items {
+	merged := Items{}
+	index := map[string]int{}
+	for _, item := range items {
+		key := item.Name + "/" + item.Unit
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, item)
+			continue
+		}
+
+		merged[i].Quantity += item.Quantity
+		merged[i].Price = addPrices(merged[i].Price, item.Price)
+		for _, allergen := range item.Allergens {
+			if !containsString(merged[i].Allergens, allergen) {
+				merged[i].Allergens = append(merged[i].Allergens, allergen)
+			}
+		}
+	}
+
+	return merged
+}
+
+func addPrices(a, b *Money) *Money {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	total, err := SumMoney(*a, *b)
+	if err != nil {
+		return a
+	}
+
+	return &total
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDuplicateItems(t *testing.T) {
+	ticket := Ticket{Items: []Item{
+		{Name: "burger", Quantity: 1, Unit: UNIT_EACH},
+		{Name: "fries", Quantity: 1, Unit: UNIT_EACH},
+		{Name: "burger", Quantity: 2, Unit: UNIT_EACH, Allergens: []string{"gluten"}},
+	}}
+
+	t.Run("keeps duplicates by default", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		assertItems(t, store.tickets[0].Items, ticket.Items)
+	})
+
+	t.Run("rejects duplicates with Unprocessable Entity", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithDuplicateItems(DUPLICATES_REJECT))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusUnprocessableEntity)
+		if len(store.tickets) != 0 {
+			t.Errorf("got %d tickets stored, want none", len(store.tickets))
+		}
+	})
+
+	t.Run("merges duplicates by summing quantities", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithDuplicateItems(DUPLICATES_MERGE))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		assertItems(t, store.tickets[0].Items, Items{
+			{Name: "burger", Quantity: 3, Unit: UNIT_EACH, Allergens: []string{"gluten"}},
+			{Name: "fries", Quantity: 1, Unit: UNIT_EACH},
+		})
+	})
+
+	t.Run("merges only items with the same unit", func(t *testing.T) {
+		got := mergeDuplicateItems(Items{
+			{Name: "chips", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "chips", Quantity: 0.5, Unit: UNIT_KG},
+		})
+
+		assertItems(t, got, Items{
+			{Name: "chips", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "chips", Quantity: 0.5, Unit: UNIT_KG},
+		})
+	})
+}
+
+func assertItems(t testing.TB, got, want Items) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got items %v, want %v", got, want)
+	}
+}
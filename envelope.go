@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+type Envelope struct {
+	Data  any            `json:"data"`
+	Error *EnvelopeError `json:"error"`
+}
+
+type EnvelopeError struct {
+	Status  int       `json:"status"`
+	Code    ErrorCode `json:"code,omitempty"`
+	Message string    `json:"message"`
+}
+
+type envelopeWriter struct {
+	http.ResponseWriter
+	failed bool
+}
+
+func (e *envelopeWriter) WriteHeader(status int) {
+	if status < http.StatusBadRequest || e.failed {
+		e.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	e.writeError(status, "", http.StatusText(status))
+}
+
+func (e *envelopeWriter) writeError(status int, code ErrorCode, message string) {
+	e.failed = true
+	e.ResponseWriter.Header().Set("Content-Type", "application/json")
+	e.ResponseWriter.WriteHeader(status)
+	writeEnvelope(e.ResponseWriter, Envelope{Error: &EnvelopeError{Status: status, Code: code, Message: message}})
+}
+
+func (e *envelopeWriter) Write(data []byte) (int, error) {
+	if e.failed {
+		return len(data), nil
+	}
+
+	return e.ResponseWriter.Write(data)
+}
+
+func (e *envelopeWriter) Unwrap() http.ResponseWriter {
+	return e.ResponseWriter
+}
+
+func writeEnvelope(w http.ResponseWriter, envelope Envelope) {
+	data, _ := json.Marshal(envelope)
+	w.Write(append(data, '\n'))
+}
+
+func (k *KitchenServer) envelopeResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !k.envelope && !acceptsEnvelope(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(&envelopeWriter{ResponseWriter: w}, r)
+	})
+}
+
+func acceptsEnvelope(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && params["envelope"] == "true" {
+			return true
+		}
+	}
+
+	return false
+}
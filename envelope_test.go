@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	store := &StubKitchenStore{tickets: []Ticket{{ID: 0, Status: STATUS_PENDING}}}
+
+	t.Run("wraps success bodies in data", func(t *testing.T) {
+		server := NewKitchenServer(store, WithEnvelope(true))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(0))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := struct {
+			Data  TicketResponse
+			Error *EnvelopeError
+		}{}
+		json.NewDecoder(response.Body).Decode(&got)
+
+		if got.Error != nil {
+			t.Errorf("got error %v, want null", got.Error)
+		}
+		assertTicket(t, got.Data.Ticket, store.tickets[0])
+	})
+
+	t.Run("wraps errors with unchanged status codes", func(t *testing.T) {
+		server := NewKitchenServer(store, WithEnvelope(true))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(7))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+
+		got := map[string]any{}
+		json.NewDecoder(response.Body).Decode(&got)
+
+		want := map[string]any{
+			"data":  nil,
+			"error": map[string]any{"status": float64(http.StatusNotFound), "message": "Not Found"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("wraps per request with an Accept parameter", func(t *testing.T) {
+		server := NewKitchenServer(store)
+
+		request := newGetTicketRequest(0)
+		request.Header.Set("Accept", "application/json; envelope=true")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		if !strings.HasPrefix(response.Body.String(), `{"data":`) {
+			t.Errorf("got body %q, want an envelope", response.Body.String())
+		}
+	})
+
+	t.Run("keeps bodies bare by default", func(t *testing.T) {
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(0))
+
+		assertTicket(t, getTicketFromResponse(t, response.Body), store.tickets[0])
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type ErrorCode string
+
+const (
+	CODE_INVALID_JSON            ErrorCode = "INVALID_JSON"
+	CODE_BODY_REQUIRED           ErrorCode = "BODY_REQUIRED"
+	CODE_BODY_TOO_LARGE          ErrorCode = "BODY_TOO_LARGE"
+	CODE_INVALID_ID              ErrorCode = "INVALID_ID"
+	CODE_ID_OUT_OF_RANGE         ErrorCode = "ID_OUT_OF_RANGE"
+	CODE_ITEMS_EMPTY             ErrorCode = "ITEMS_EMPTY"
+	CODE_TOO_MANY_ITEMS          ErrorCode = "TOO_MANY_ITEMS"
+	CODE_FIELD_TOO_LONG          ErrorCode = "FIELD_TOO_LONG"
+	CODE_ITEM_NAME_EMPTY         ErrorCode = "ITEM_NAME_EMPTY"
+	CODE_INVALID_QUANTITY        ErrorCode = "INVALID_QUANTITY"
+	CODE_INVALID_UNIT            ErrorCode = "INVALID_UNIT"
+	CODE_UNKNOWN_ALLERGEN        ErrorCode = "UNKNOWN_ALLERGEN"
+	CODE_UNKNOWN_TAX_CATEGORY    ErrorCode = "UNKNOWN_TAX_CATEGORY"
+	CODE_UNKNOWN_STATION         ErrorCode = "UNKNOWN_STATION"
+	CODE_INVALID_PRICE           ErrorCode = "INVALID_PRICE"
+	CODE_INVALID_TIP             ErrorCode = "INVALID_TIP"
+	CODE_MIXED_CURRENCIES        ErrorCode = "MIXED_CURRENCIES"
+	CODE_STEP_NAME_EMPTY         ErrorCode = "STEP_NAME_EMPTY"
+	CODE_DUPLICATE_ITEM          ErrorCode = "DUPLICATE_ITEM"
+	CODE_IDEMPOTENCY_KEY_REUSED  ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CODE_INVALID_EXPIRY          ErrorCode = "INVALID_EXPIRY"
+	CODE_INVALID_SCHEDULE        ErrorCode = "INVALID_SCHEDULE"
+	CODE_FUTURE_TIMESTAMP        ErrorCode = "FUTURE_TIMESTAMP"
+	CODE_FIELD_NOT_PATCHABLE     ErrorCode = "FIELD_NOT_PATCHABLE"
+	CODE_INVALID_TRANSITION      ErrorCode = "INVALID_TRANSITION"
+	CODE_NOT_EDITABLE            ErrorCode = "NOT_EDITABLE"
+	CODE_STEPS_INCOMPLETE        ErrorCode = "STEPS_INCOMPLETE"
+	CODE_MISSING_API_KEY         ErrorCode = "MISSING_API_KEY"
+	CODE_INVALID_API_KEY         ErrorCode = "INVALID_API_KEY"
+	CODE_INSUFFICIENT_ROLE       ErrorCode = "INSUFFICIENT_ROLE"
+	CODE_FORBIDDEN               ErrorCode = "FORBIDDEN"
+	CODE_INJECTED_FAILURE        ErrorCode = "INJECTED_FAILURE"
+	CODE_TEMPLATE_NOT_FOUND      ErrorCode = "TEMPLATE_NOT_FOUND"
+	CODE_TEMPLATE_NAME_EMPTY     ErrorCode = "TEMPLATE_NAME_EMPTY"
+	CODE_MAINTENANCE             ErrorCode = "MAINTENANCE"
+	CODE_UNKNOWN_DEPENDENCY      ErrorCode = "UNKNOWN_DEPENDENCY"
+	CODE_DEPENDENCY_CYCLE        ErrorCode = "DEPENDENCY_CYCLE"
+	CODE_DEPENDENCIES_INCOMPLETE ErrorCode = "DEPENDENCIES_INCOMPLETE"
+	CODE_STATION_FULL            ErrorCode = "STATION_FULL"
+	CODE_OVERLOADED              ErrorCode = "OVERLOADED"
+	CODE_NONCE_REQUIRED          ErrorCode = "NONCE_REQUIRED"
+	CODE_NONCE_REUSED            ErrorCode = "NONCE_REUSED"
+	CODE_STORE_NOT_EMPTY         ErrorCode = "STORE_NOT_EMPTY"
+	CODE_INVALID_BACKUP          ErrorCode = "INVALID_BACKUP"
+	CODE_UNSUPPORTED_VERSION     ErrorCode = "UNSUPPORTED_VERSION"
+)
+
+type ErrorResponse struct {
+	Code    ErrorCode
+	Message string
+}
+
+type ValidationError struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (v *ValidationError) Error() string {
+	return v.Message
+}
+
+func newValidationError(code ErrorCode, format string, args ...any) *ValidationError {
+	return &ValidationError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func validationErrorStatus(err error) (int, ErrorCode) {
+	if errors.Is(err, errDuplicateItem) {
+		return http.StatusUnprocessableEntity, CODE_DUPLICATE_ITEM
+	}
+
+	if errors.Is(err, errUnknownDependency) {
+		return http.StatusUnprocessableEntity, CODE_UNKNOWN_DEPENDENCY
+	}
+
+	if errors.Is(err, errDependencyCycle) {
+		return http.StatusUnprocessableEntity, CODE_DEPENDENCY_CYCLE
+	}
+
+	if errors.Is(err, errFutureTimestamp) {
+		return http.StatusUnprocessableEntity, CODE_FUTURE_TIMESTAMP
+	}
+
+	if errors.Is(err, errTemplateNotFound) {
+		return http.StatusNotFound, CODE_TEMPLATE_NOT_FOUND
+	}
+
+	if errors.Is(err, errForbidden) {
+		return http.StatusForbidden, CODE_FORBIDDEN
+	}
+
+	var validation *ValidationError
+	if errors.As(err, &validation) {
+		return http.StatusBadRequest, validation.Code
+	}
+
+	return http.StatusBadRequest, CODE_INVALID_JSON
+}
+
+func (k *KitchenServer) writeValidationError(w http.ResponseWriter, err error) {
+	status, code := validationErrorStatus(err)
+	k.writeError(w, status, code, err.Error())
+}
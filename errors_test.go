@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidationErrorCodes(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		status int
+		code   ErrorCode
+	}{
+		{"malformed JSON", `{"Items": [`, http.StatusBadRequest, CODE_INVALID_JSON},
+		{"empty body", ``, http.StatusBadRequest, CODE_BODY_REQUIRED},
+		{"whitespace body", " \n\t ", http.StatusBadRequest, CODE_BODY_REQUIRED},
+		{"missing items", `{"Notes": "no onions"}`, http.StatusBadRequest, CODE_ITEMS_EMPTY},
+		{"empty item name", `{"Items": [{"Name": ""}]}`, http.StatusBadRequest, CODE_ITEM_NAME_EMPTY},
+		{"negative quantity", `{"Items": [{"Name": "burger", "Quantity": -1}]}`, http.StatusBadRequest, CODE_INVALID_QUANTITY},
+		{"fractional quantity", `{"Items": [{"Name": "burger", "Quantity": 1.5}]}`, http.StatusBadRequest, CODE_INVALID_QUANTITY},
+		{"unknown unit", `{"Items": [{"Name": "burger", "Unit": "crate"}]}`, http.StatusBadRequest, CODE_INVALID_UNIT},
+		{"unknown allergen", `{"Items": [{"Name": "burger", "Allergens": ["pollen"]}]}`, http.StatusBadRequest, CODE_UNKNOWN_ALLERGEN},
+		{"invalid price", `{"Items": [{"Name": "burger", "Price": {"Amount": 100, "Currency": "XXY"}}]}`, http.StatusBadRequest, CODE_INVALID_PRICE},
+		{"mixed currencies", `{"Items": [{"Name": "burger", "Price": {"Amount": 100, "Currency": "EUR"}}, {"Name": "fries", "Price": {"Amount": 100, "Currency": "USD"}}]}`, http.StatusBadRequest, CODE_MIXED_CURRENCIES},
+		{"unnamed step", `{"Items": [{"Name": "burger", "Steps": [{"Name": ""}]}]}`, http.StatusBadRequest, CODE_STEP_NAME_EMPTY},
+		{"too long notes", `{"Items": ["burger"], "Notes": "` + strings.Repeat("a", defaultLimits.MaxNoteLength+1) + `"}`, http.StatusBadRequest, CODE_FIELD_TOO_LONG},
+		{"past expiry", `{"Items": ["burger"], "ExpiresAt": "2000-01-01T00:00:00Z"}`, http.StatusBadRequest, CODE_INVALID_EXPIRY},
+		{"duplicate item", `{"Items": ["burger", "burger"]}`, http.StatusUnprocessableEntity, CODE_DUPLICATE_ITEM},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := NewKitchenServer(&StubKitchenStore{}, WithDuplicateItems(DUPLICATES_REJECT))
+
+			request, _ := http.NewRequest(http.MethodPost, "/ticket/", strings.NewReader(c.body))
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, c.status)
+			assertErrorCode(t, response, c.code)
+		})
+	}
+
+	t.Run("explains a missing body", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		request, _ := http.NewRequest(http.MethodPost, "/ticket/", http.NoBody)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorResponse(t, response, "request body is required")
+	})
+
+	t.Run("reports too many items", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithMaxItems(1))
+
+		request, _ := http.NewRequest(http.MethodPost, "/ticket/", strings.NewReader(`{"Items": ["burger", "fries"]}`))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_TOO_MANY_ITEMS)
+	})
+
+	t.Run("reports unpatchable fields", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 0, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}}}
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(0, `{"Status": 2}`))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_FIELD_NOT_PATCHABLE)
+	})
+
+	t.Run("reports codes per batch item", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newBatchRequest(`[{"Items": ["burger"]}, {"Notes": "no items"}]`))
+
+		got := getBatchResultsFromResponse(t, response)
+		if got[0].Code != "" || got[1].Code != CODE_ITEMS_EMPTY {
+			t.Errorf("got codes %q and %q, want none and %q", got[0].Code, got[1].Code, CODE_ITEMS_EMPTY)
+		}
+	})
+}
+
+func TestTransitionErrorCodes(t *testing.T) {
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{tickets: []Ticket{
+			{ID: 1, Status: STATUS_PENDING, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH, Steps: []Step{{Name: "grill"}}}}},
+			{ID: 2, Status: STATUS_CANCELLED, Items: Items{{Name: "fries", Quantity: 1, Unit: UNIT_EACH}}},
+		}}
+	}
+
+	cases := []struct {
+		name    string
+		request *http.Request
+		code    ErrorCode
+	}{
+		{"reopening a pending ticket", newReopenTicketRequest(1, ReopenRequest{Reason: "wrong order"}), CODE_INVALID_TRANSITION},
+		{"completing a cancelled ticket", newCompleteTicketRequest(2), CODE_INVALID_TRANSITION},
+		{"completing with steps left", newCompleteTicketRequest(1), CODE_STEPS_INCOMPLETE},
+		{"bumping a cancelled ticket", newMoveTicketRequest(2, "bump"), CODE_INVALID_TRANSITION},
+		{"merging a cancelled ticket", newMergeTicketRequest(1, MergeRequest{TicketID: 2}), CODE_INVALID_TRANSITION},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := NewKitchenServer(newStore())
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, c.request)
+
+			assertStatus(t, response.Code, http.StatusConflict)
+			assertErrorCode(t, response, c.code)
+		})
+	}
+}
+
+func assertErrorCode(t testing.TB, response *httptest.ResponseRecorder, want ErrorCode) {
+	t.Helper()
+
+	got := ErrorResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+		t.Fatalf("unable to parse error response %q, %v", response.Body, err)
+	}
+
+	if got.Code != want {
+		t.Errorf("got error code %q, want %q", got.Code, want)
+	}
+	if got.Message == "" {
+		t.Errorf("expected an error message but didn't get one")
+	}
+}
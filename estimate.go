@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultAveragePrepTime = 10 * time.Minute
+
+var activeStatuses = []Status{STATUS_PENDING, STATUS_ACCEPTED}
+
+type QueueState struct {
+	Depth           int
+	AveragePrepTime time.Duration
+	MinPrepTime     time.Duration
+}
+
+type EstimateResponse struct {
+	ReadyAt    time.Time
+	QueueDepth int
+}
+
+func (k *KitchenServer) estimateTicket(w http.ResponseWriter, r *http.Request) {
+	ticket, err := k.getTicketFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	depth, err := k.storeFor(r).CountTickets(TicketFilter{Statuses: activeStatuses, ActiveAt: k.clock.Now()})
+	if err != nil {
+		k.logger.Error("unable to count active tickets", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	queue := QueueState{Depth: depth, AveragePrepTime: k.avgPrepTime, MinPrepTime: k.minPrepTime(ticket.Items)}
+	readyAt := estimateReadyAt(k.clock.Now(), queue)
+
+	k.writeJSON(w, http.StatusOK, EstimateResponse{ReadyAt: readyAt, QueueDepth: depth})
+}
+
+func estimateReadyAt(now time.Time, queue QueueState) time.Time {
+	wait := time.Duration(queue.Depth+1) * queue.AveragePrepTime
+	return now.Add(max(wait, queue.MinPrepTime))
+}
+
+func ParseMinPrepTimes(pairs string) (map[string]time.Duration, error) {
+	times := map[string]time.Duration{}
+	for _, pair := range strings.Split(pairs, ",") {
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		minutes, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || name == "" || err != nil || minutes < 0 {
+			return nil, fmt.Errorf("invalid minimum prep time %q, want item=minutes", pair)
+		}
+
+		times[name] = time.Duration(minutes) * time.Minute
+	}
+
+	return times, nil
+}
+
+func (k *KitchenServer) minPrepTime(items Items) time.Duration {
+	slowest := time.Duration(0)
+	for _, item := range items {
+		minPrepTime, ok := k.minPrepTimes[aliasKey(item.Name)]
+		if !ok {
+			minPrepTime = k.defaultMinPrepTime
+		}
+		slowest = max(slowest, minPrepTime)
+	}
+
+	return slowest
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEstimateReadyAt(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("takes one prep time on an empty queue", func(t *testing.T) {
+		got := estimateReadyAt(now, QueueState{Depth: 0, AveragePrepTime: 10 * time.Minute})
+		want := now.Add(10 * time.Minute)
+
+		assertTime(t, got, want)
+	})
+
+	t.Run("waits for the queue ahead on a busy queue", func(t *testing.T) {
+		got := estimateReadyAt(now, QueueState{Depth: 5, AveragePrepTime: 10 * time.Minute})
+		want := now.Add(60 * time.Minute)
+
+		assertTime(t, got, want)
+	})
+
+	t.Run("waits for the minimum prep time on a short queue", func(t *testing.T) {
+		got := estimateReadyAt(now, QueueState{Depth: 1, AveragePrepTime: 10 * time.Minute, MinPrepTime: 45 * time.Minute})
+		want := now.Add(45 * time.Minute)
+
+		assertTime(t, got, want)
+	})
+}
+
+func TestEstimateTicket(t *testing.T) {
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	ticket := Ticket{Items: []Item{{Name: "burger", Quantity: 40, Unit: UNIT_EACH}}}
+
+	t.Run("estimates from active tickets without creating one", func(t *testing.T) {
+		store := &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_PENDING},
+				{ID: 2, Status: STATUS_ACCEPTED},
+				{ID: 3, Status: STATUS_COMPLETED},
+			},
+		}
+		server := NewKitchenServer(store, WithClock(clock), WithAveragePrepTime(5*time.Minute))
+
+		request := newEstimateTicketRequest(ticket)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := EstimateResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+
+		if got.QueueDepth != 2 {
+			t.Errorf("got queue depth %d, want 2", got.QueueDepth)
+		}
+		assertTime(t, got.ReadyAt, clock.now.Add(15*time.Minute))
+
+		if len(store.tickets) != 3 {
+			t.Errorf("estimate created a ticket, got %d tickets want 3", len(store.tickets))
+		}
+	})
+
+	t.Run("estimates no sooner than the slowest item", func(t *testing.T) {
+		times := map[string]time.Duration{"Burger": 8 * time.Minute, "brisket": 90 * time.Minute}
+		server := NewKitchenServer(&StubKitchenStore{}, WithClock(clock), WithAveragePrepTime(5*time.Minute), WithMinPrepTimes(times, 2*time.Minute))
+
+		cases := []struct {
+			items []Item
+			want  time.Duration
+		}{
+			{[]Item{{Name: "fries", Quantity: 1, Unit: UNIT_EACH}}, 5 * time.Minute},
+			{[]Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}, {Name: "fries", Quantity: 1, Unit: UNIT_EACH}}, 8 * time.Minute},
+			{[]Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}, {Name: "brisket", Quantity: 1, Unit: UNIT_KG}}, 90 * time.Minute},
+		}
+
+		for _, c := range cases {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newEstimateTicketRequest(Ticket{Items: c.items}))
+
+			got := EstimateResponse{}
+			json.NewDecoder(response.Body).Decode(&got)
+			assertTime(t, got.ReadyAt, clock.now.Add(c.want))
+		}
+	})
+
+	t.Run("returns Bad Request on invalid ticket", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithClock(clock))
+
+		request := newEstimateTicketRequest(Ticket{})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestParseMinPrepTimes(t *testing.T) {
+	t.Run("parses item minutes", func(t *testing.T) {
+		got, err := ParseMinPrepTimes("burger=8, brisket = 90")
+		if err != nil {
+			t.Fatalf("didn't expect an error but got one, %v", err)
+		}
+
+		want := map[string]time.Duration{"burger": 8 * time.Minute, "brisket": 90 * time.Minute}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	for _, pairs := range []string{"burger", "burger=soon", "burger=-1", "=8"} {
+		t.Run("rejects "+pairs, func(t *testing.T) {
+			if _, err := ParseMinPrepTimes(pairs); err == nil {
+				t.Errorf("expected an error but didn't get one")
+			}
+		})
+	}
+}
+
+func newEstimateTicketRequest(ticket Ticket) *http.Request {
+	return newPostTicketRequest("/ticket/estimate", ticket)
+}
+
+func assertTime(t testing.TB, got, want time.Time) {
+	t.Helper()
+
+	if !got.Equal(want) {
+		t.Errorf("got time %v, want %v", got, want)
+	}
+}
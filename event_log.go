@@ -0,0 +1,120 @@
+package main
+
+const (
+	CHANGE_TICKET = "ticket"
+	CHANGE_EVENT  = "event"
+	CHANGE_DELETE = "delete"
+
+	CHANGE_TEMPLATE        = "template"
+	CHANGE_DELETE_TEMPLATE = "delete-template"
+	CHANGE_MAINTENANCE     = "maintenance"
+	CHANGE_OUTBOX          = "outbox"
+	CHANGE_DELETE_OUTBOX   = "delete-outbox"
+)
+
+type StoreChange struct {
+	Type     string
+	Ticket   Ticket
+	Event    TicketEvent
+	Template Template
+
+	Maintenance Maintenance
+	Outbox      OutboxEntry
+}
+
+// NewEventSourcedKitchenStore returns a store that keeps every change in an
+// append-only log. Ticket changes are logged as whole rows next to their
+// TicketEvents, since an event alone doesn't carry the ticket's items.
+func NewEventSourcedKitchenStore() *InMemoryKitchenStore {
+	store := NewInMemoryKitchenStore()
+	store.eventSourced = true
+
+	return store
+}
+
+func (i *InMemoryKitchenStore) apply(change StoreChange) {
+	if i.eventSourced {
+		i.log = append(i.log, change)
+	}
+
+	i.fold(change)
+}
+
+func (i *InMemoryKitchenStore) fold(change StoreChange) {
+	switch change.Type {
+	case CHANGE_TICKET:
+		ticket := change.Ticket
+		if old, ok := i.tickets[ticket.ID]; ok {
+			if orderKey(old) != orderKey(ticket) {
+				delete(i.byOrderID, orderKey(old))
+			}
+			delete(i.byStatus[old.Status], old.ID)
+		}
+
+		i.tickets[ticket.ID] = ticket
+		i.indexStatus(ticket)
+		if ticket.OrderID != "" {
+			i.byOrderID[orderKey(ticket)] = ticket.ID
+		}
+		i.lastID = max(i.lastID, ticket.ID)
+	case CHANGE_EVENT:
+		i.events[change.Event.TicketID] = append(i.events[change.Event.TicketID], change.Event)
+	case CHANGE_DELETE:
+		delete(i.tickets, change.Ticket.ID)
+		delete(i.events, change.Ticket.ID)
+		delete(i.byOrderID, orderKey(change.Ticket))
+		delete(i.byStatus[change.Ticket.Status], change.Ticket.ID)
+	case CHANGE_TEMPLATE:
+		i.templates[templateKey(change.Template.KitchenID, change.Template.Name)] = change.Template
+	case CHANGE_DELETE_TEMPLATE:
+		delete(i.templates, templateKey(change.Template.KitchenID, change.Template.Name))
+	case CHANGE_MAINTENANCE:
+		i.maintenance = change.Maintenance
+	case CHANGE_OUTBOX, CHANGE_DELETE_OUTBOX:
+		i.foldOutbox(change)
+	}
+}
+
+func (i *InMemoryKitchenStore) Log() []StoreChange {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	log := make([]StoreChange, len(i.log))
+	copy(log, i.log)
+
+	return log
+}
+
+func (i *InMemoryKitchenStore) Rebuild() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.replay(i.log)
+}
+
+func (i *InMemoryKitchenStore) ReplayTo(n int) *InMemoryKitchenStore {
+	log := i.Log()
+
+	store := NewEventSourcedKitchenStore()
+	store.log = log[:min(n, len(log))]
+	store.replay(store.log)
+
+	return store
+}
+
+// replay rebuilds state from log. The ID counters are left alone rather than
+// folded back to the highest ID still in the log, so IDs of tickets dropped
+// before a snapshot restore are never handed out again.
+func (i *InMemoryKitchenStore) replay(log []StoreChange) {
+	i.tickets = map[int]Ticket{}
+	i.events = map[int][]TicketEvent{}
+	i.byOrderID = map[string]int{}
+	i.byStatus = map[Status]map[int]bool{}
+	i.templates = map[string]Template{}
+	i.maintenance = Maintenance{}
+	i.outbox = nil
+
+	for _, change := range log {
+		i.fold(change)
+	}
+}
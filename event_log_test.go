@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestEventSourcedKitchenStore(t *testing.T) {
+	cutoff := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	newStore := func() *InMemoryKitchenStore {
+		store := NewEventSourcedKitchenStore()
+
+		first, _ := store.StoreTicket(Ticket{Status: STATUS_PENDING, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		store.StoreTicketEvent(TicketEvent{Type: EVENT_CREATED, TicketID: first})
+		second, _, _ := store.StoreTicketIfNotExists(Ticket{OrderID: "order-42", Status: STATUS_PENDING})
+		store.StoreTicketEvent(TicketEvent{Type: EVENT_CREATED, TicketID: second.ID})
+		third, _ := store.StoreTicket(Ticket{Status: STATUS_COMPLETED, UpdatedAt: cutoff.Add(-time.Hour)})
+
+		store.UpdateTicket(Ticket{ID: first, Status: STATUS_ACCEPTED, Items: []Item{{Name: "burger", Quantity: 2, Unit: UNIT_EACH}}})
+		store.MoveTicket(second.ID, -1, cutoff)
+		store.PurgeCompletedBefore(cutoff, cutoff)
+		store.StoreTicketEvent(TicketEvent{Type: EVENT_CREATED, TicketID: third})
+
+		return store
+	}
+
+	t.Run("rebuilding from the log reconstructs the same tickets", func(t *testing.T) {
+		store := newStore()
+		want := NewInMemoryKitchenStore()
+		want.tickets, want.events, want.byOrderID, want.lastID = store.tickets, store.events, store.byOrderID, store.lastID
+
+		store.Rebuild()
+
+		assertStoresEqual(t, store, want)
+	})
+
+	t.Run("replaying part of the log shows earlier state", func(t *testing.T) {
+		store := newStore()
+
+		past := store.ReplayTo(5)
+
+		got, err := past.GetTicketByID(3)
+		if err != nil {
+			t.Fatalf("didn't find ticket purged later, %v", err)
+		}
+		if got.Status != STATUS_COMPLETED {
+			t.Errorf("got status %v, want %v", got.Status, STATUS_COMPLETED)
+		}
+
+		first, _ := past.GetTicketByID(1)
+		if first.Status != STATUS_PENDING {
+			t.Errorf("got status %v, want %v before the update", first.Status, STATUS_PENDING)
+		}
+	})
+
+	t.Run("keeps handing out new IDs after a rebuild", func(t *testing.T) {
+		snapshot := &bytes.Buffer{}
+		source := NewInMemoryKitchenStore()
+		source.StoreTicket(Ticket{Status: STATUS_PENDING})
+		source.StoreTicket(Ticket{Status: STATUS_COMPLETED, UpdatedAt: cutoff.Add(-time.Hour)})
+		source.PurgeCompletedBefore(cutoff, cutoff)
+		source.PurgeCompletedBefore(cutoff.Add(time.Hour), cutoff.Add(time.Hour))
+		source.Snapshot(snapshot)
+
+		store := NewEventSourcedKitchenStore()
+		store.Restore(snapshot)
+		store.Rebuild()
+
+		id, _ := store.StoreTicket(Ticket{Status: STATUS_PENDING})
+		if id != 3 {
+			t.Errorf("got ID %d, want 3 after the purged ticket 2", id)
+		}
+	})
+
+	t.Run("doesn't keep a log by default", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		store.StoreTicket(Ticket{Status: STATUS_PENDING})
+
+		if len(store.Log()) != 0 {
+			t.Errorf("got %d changes logged, want none", len(store.Log()))
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+const expiredReason = "expired"
+
+func isScheduledAfter(ticket Ticket, now time.Time) bool {
+	return ticket.ScheduledFor != nil && ticket.ScheduledFor.After(now)
+}
+
+func isExpired(ticket Ticket, now time.Time) bool {
+	return ticket.ExpiresAt != nil && !now.Before(*ticket.ExpiresAt)
+}
+
+func (k *KitchenServer) SweepExpired() (int, error) {
+	now := k.clock.Now()
+
+	candidates := []int{}
+	err := k.store.StreamTickets(context.Background(), func(ticket Ticket) error {
+		if containsStatus(activeStatuses, ticket.Status) && isExpired(ticket, now) {
+			candidates = append(candidates, ticket.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	swept := 0
+	for _, id := range candidates {
+		_, expired, err := k.expireTicket(k.store, id, now, TicketEvent{Type: EVENT_CANCELLED, Reason: expiredReason, OccurredAt: now})
+		if err != nil {
+			return swept, err
+		}
+		if expired {
+			swept++
+		}
+	}
+
+	return swept, nil
+}
+
+func (k *KitchenServer) SweepExpiredEvery(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := k.SweepExpired(); err != nil {
+					k.logger.Error("unable to sweep expired tickets", "error", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type CompletingKitchenStore struct {
+	*InMemoryKitchenStore
+}
+
+// StreamTickets completes every ticket once it has streamed them, like a cook
+// finishing a ticket while the sweep is running.
+func (c *CompletingKitchenStore) StreamTickets(ctx context.Context, fn func(Ticket) error) error {
+	streamed := []Ticket{}
+	err := c.InMemoryKitchenStore.StreamTickets(ctx, func(ticket Ticket) error {
+		streamed = append(streamed, ticket)
+		return fn(ticket)
+	})
+
+	for _, ticket := range streamed {
+		ticket.Status = STATUS_COMPLETED
+		c.UpdateTicket(ticket)
+	}
+
+	return err
+}
+
+func TestSweepExpired(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_PENDING, ExpiresAt: &expiresAt},
+				{ID: 2, Status: STATUS_COMPLETED, ExpiresAt: &expiresAt},
+				{ID: 3, Status: STATUS_ACCEPTED},
+			},
+		}
+	}
+
+	t.Run("keeps tickets before they expire", func(t *testing.T) {
+		store := newStore()
+		clock := &StubClock{now}
+		server := NewKitchenServer(store, WithClock(clock))
+
+		clock.Advance(time.Hour - time.Nanosecond)
+		removed, _ := server.SweepExpired()
+
+		if removed != 0 || store.tickets[0].Status != STATUS_PENDING {
+			t.Errorf("got %d swept and status %v, want ticket kept pending", removed, store.tickets[0].Status)
+		}
+	})
+
+	t.Run("cancels active tickets once they expire", func(t *testing.T) {
+		store := newStore()
+		clock := &StubClock{now}
+		server := NewKitchenServer(store, WithClock(clock))
+
+		clock.Advance(time.Hour)
+		removed, _ := server.SweepExpired()
+
+		if removed != 1 {
+			t.Errorf("got %d tickets swept, want 1", removed)
+		}
+		if store.tickets[0].Status != STATUS_CANCELLED {
+			t.Errorf("got status %v, want %v", store.tickets[0].Status, STATUS_CANCELLED)
+		}
+		if store.tickets[1].Status != STATUS_COMPLETED {
+			t.Errorf("got status %v for completed ticket, want it untouched", store.tickets[1].Status)
+		}
+
+		assertEvents(t, store.events, []TicketEvent{
+			{Type: EVENT_CANCELLED, TicketID: 1, Status: STATUS_CANCELLED, Reason: expiredReason, OccurredAt: clock.now},
+		})
+	})
+
+	t.Run("leaves a ticket completed during the sweep alone", func(t *testing.T) {
+		store := &CompletingKitchenStore{NewInMemoryKitchenStore()}
+		id, _ := store.StoreTicket(Ticket{Status: STATUS_ACCEPTED, ExpiresAt: &expiresAt})
+		server := NewKitchenServer(store, WithClock(&StubClock{expiresAt}))
+
+		removed, _ := server.SweepExpired()
+
+		got, _ := store.GetTicketByID(id)
+		if removed != 0 || got.Status != STATUS_COMPLETED {
+			t.Errorf("got %d swept and status %v, want the ticket left completed", removed, got.Status)
+		}
+	})
+
+	t.Run("rejects tickets that have already expired", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithClock(&StubClock{now}))
+
+		ticket := Ticket{ExpiresAt: &now, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestClockSkew(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	skew := 5 * time.Second
+	newTicket := func(scheduledFor, expiresAt *time.Time) Ticket {
+		return Ticket{ScheduledFor: scheduledFor, ExpiresAt: expiresAt, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+	}
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	cases := []struct {
+		name   string
+		ticket Ticket
+		want   int
+	}{
+		{"accepts expiry just inside the tolerance", newTicket(nil, at(-skew+time.Nanosecond)), http.StatusAccepted},
+		{"rejects expiry at the tolerance", newTicket(nil, at(-skew)), http.StatusBadRequest},
+		{"accepts schedule at the tolerance", newTicket(at(-skew), nil), http.StatusAccepted},
+		{"rejects schedule past the tolerance", newTicket(at(-skew-time.Nanosecond), nil), http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := NewKitchenServer(&StubKitchenStore{}, WithClock(&StubClock{now}), WithClockSkew(skew))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newCreateTicketRequest(c.ticket))
+
+			assertStatus(t, response.Code, c.want)
+		})
+	}
+
+	t.Run("rejects any past schedule without a tolerance", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithClock(&StubClock{now}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(newTicket(at(-time.Nanosecond), nil)))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestScheduledTickets(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	scheduledFor := now.Add(30 * time.Minute)
+
+	store := &StubKitchenStore{
+		tickets: []Ticket{
+			{ID: 1, Status: STATUS_PENDING},
+			{ID: 2, Status: STATUS_PENDING, ScheduledFor: &scheduledFor},
+		},
+	}
+
+	listIDs := func(server *KitchenServer, query string) []int {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest(query))
+
+		ids := []int{}
+		for _, ticket := range getTicketPageFromResponse(t, response.Body).Tickets {
+			ids = append(ids, ticket.ID)
+		}
+		return ids
+	}
+
+	t.Run("hides pre-orders until their scheduled time", func(t *testing.T) {
+		server := NewKitchenServer(store, WithClock(&StubClock{scheduledFor.Add(-time.Nanosecond)}))
+
+		got := listIDs(server, "")
+		if len(got) != 1 || got[0] != 1 {
+			t.Errorf("got IDs %v, want only the unscheduled ticket", got)
+		}
+	})
+
+	t.Run("shows pre-orders from their scheduled time", func(t *testing.T) {
+		server := NewKitchenServer(store, WithClock(&StubClock{scheduledFor}))
+
+		got := listIDs(server, "")
+		if len(got) != 2 {
+			t.Errorf("got IDs %v, want both tickets", got)
+		}
+	})
+
+	t.Run("lists pre-orders when asked for scheduled tickets", func(t *testing.T) {
+		server := NewKitchenServer(store, WithClock(&StubClock{now}))
+
+		got := listIDs(server, "?scheduled=true")
+		if len(got) != 2 {
+			t.Errorf("got IDs %v, want both tickets", got)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/csv"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{"id", "status", "created_at", "items"}
+
+func acceptsCSV(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err == nil && mediaType == "text/csv" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k *KitchenServer) writeTicketsCSV(w http.ResponseWriter, tickets []Ticket) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="tickets.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, ticket := range tickets {
+		writer.Write(ticketCSVRecord(ticket))
+	}
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		k.logger.Error("unable to write tickets CSV", "error", err)
+	}
+}
+
+func ticketCSVRecord(ticket Ticket) []string {
+	names := make([]string, 0, len(ticket.Items))
+	for _, item := range ticket.Items {
+		names = append(names, item.Name)
+	}
+
+	return []string{
+		strconv.Itoa(ticket.ID),
+		strconv.Itoa(int(ticket.Status)),
+		ticket.CreatedAt.UTC().Format(time.RFC3339),
+		strings.Join(names, ", "),
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListTicketsCSV(t *testing.T) {
+	createdAt := time.Date(2023, time.June, 1, 12, 30, 0, 0, time.UTC)
+	store := &StubKitchenStore{
+		tickets: []Ticket{
+			{
+				ID:        1,
+				Status:    STATUS_ACCEPTED,
+				Items:     []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}, {Name: "fries", Quantity: 1, Unit: UNIT_EACH}},
+				CreatedAt: createdAt,
+			},
+		},
+	}
+	server := NewKitchenServer(store)
+
+	t.Run("returns CSV when requested", func(t *testing.T) {
+		request := newListTicketsRequest("")
+		request.Header.Set("Accept", "text/csv")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertHeader(t, response, "Content-Type", "text/csv; charset=utf-8")
+		assertHeader(t, response, "Content-Disposition", `attachment; filename="tickets.csv"`)
+
+		records, err := csv.NewReader(response.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("unable to parse CSV response, %v", err)
+		}
+
+		want := [][]string{
+			{"id", "status", "created_at", "items"},
+			{"1", "1", "2023-06-01T12:30:00Z", "burger, fries"},
+		}
+		if !reflect.DeepEqual(records, want) {
+			t.Errorf("got records %v, want %v", records, want)
+		}
+	})
+
+	t.Run("returns JSON by default", func(t *testing.T) {
+		request := newListTicketsRequest("")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := getTicketPageFromResponse(t, response.Body)
+		assertTickets(t, got.Tickets, store.tickets)
+	})
+}
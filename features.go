@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	FEATURE_STREAM    = "stream"
+	FEATURE_BATCH     = "batch"
+	FEATURE_ESTIMATE  = "estimate"
+	FEATURE_TEMPLATES = "templates"
+)
+
+var knownFeatures = []string{FEATURE_STREAM, FEATURE_BATCH, FEATURE_ESTIMATE, FEATURE_TEMPLATES}
+
+func ParseFeatureFlags(pairs string) (map[string]bool, error) {
+	flags := map[string]bool{}
+	for _, pair := range strings.Split(pairs, ",") {
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if !found || err != nil {
+			return nil, fmt.Errorf("invalid feature flag %q, want feature=true or feature=false", pair)
+		}
+
+		if !isFeatureKnown(name) {
+			return nil, fmt.Errorf("unknown feature %q, want one of %s", name, strings.Join(knownFeatures, ", "))
+		}
+
+		flags[name] = enabled
+	}
+
+	return flags, nil
+}
+
+func isFeatureKnown(name string) bool {
+	for _, feature := range knownFeatures {
+		if feature == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k *KitchenServer) featureEnabled(name string) bool {
+	enabled, ok := k.features[name]
+	return !ok || enabled
+}
+
+func (k *KitchenServer) requireFeature(w http.ResponseWriter, name string) bool {
+	if !k.featureEnabled(name) {
+		w.WriteHeader(http.StatusNotFound)
+		return false
+	}
+
+	return true
+}
+
+func (k *KitchenServer) serveFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	flags := map[string]bool{}
+	for _, feature := range knownFeatures {
+		flags[feature] = k.featureEnabled(feature)
+	}
+
+	k.writeJSON(w, http.StatusOK, flags)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	batch := `[{"Items": "burger"}]`
+
+	t.Run("returns Not Found for a disabled feature", func(t *testing.T) {
+		flags, err := ParseFeatureFlags("batch=false")
+		if err != nil {
+			t.Fatalf("unable to parse feature flags, %v", err)
+		}
+		server := NewKitchenServer(&StubKitchenStore{}, WithFeatureFlags(flags))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newBatchRequest(batch))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("serves a feature enabled in config", func(t *testing.T) {
+		flags, err := ParseFeatureFlags("batch=true,stream=false")
+		if err != nil {
+			t.Fatalf("unable to parse feature flags, %v", err)
+		}
+		server := NewKitchenServer(&StubKitchenStore{}, WithFeatureFlags(flags))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newBatchRequest(batch))
+
+		assertStatus(t, response.Code, http.StatusMultiStatus)
+	})
+
+	t.Run("leaves unlisted features enabled", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithFeatureFlags(map[string]bool{FEATURE_STREAM: false}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newEstimateTicketRequest(Ticket{Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}))
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("exposes flag state to admins", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithAdmin(true), WithFeatureFlags(map[string]bool{FEATURE_BATCH: false}))
+
+		request, _ := http.NewRequest(http.MethodGet, "/admin/flags", nil)
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := map[string]bool{}
+		json.NewDecoder(response.Body).Decode(&got)
+		want := map[string]bool{FEATURE_STREAM: true, FEATURE_BATCH: false, FEATURE_ESTIMATE: true, FEATURE_TEMPLATES: true}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got flags %v, want %v", got, want)
+		}
+	})
+
+	t.Run("rejects unknown features", func(t *testing.T) {
+		if _, err := ParseFeatureFlags("teleport=true"); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("rejects malformed flags", func(t *testing.T) {
+		if _, err := ParseFeatureFlags("batch"); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+}
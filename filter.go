@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TicketFilter struct {
+	KitchenID      string
+	AfterID        int
+	AfterRank      int
+	AfterRush      bool
+	Limit          int
+	Allergen       string
+	Station        string
+	ActiveAt       time.Time
+	UpdatedAfter   time.Time
+	Statuses       []Status
+	ExcludeIDs     []int
+	IncludeDeleted bool
+	Expression     ticketPredicate
+}
+
+func (f TicketFilter) Matches(ticket Ticket) bool {
+	if ticket.Deleted && !f.IncludeDeleted {
+		return false
+	}
+
+	if f.AfterID > 0 && !queueBefore(Ticket{ID: f.AfterID, QueueRank: f.AfterRank, Rush: f.AfterRush}, ticket) {
+		return false
+	}
+
+	if f.KitchenID != "" && ticket.KitchenID != f.KitchenID {
+		return false
+	}
+
+	if len(f.Statuses) > 0 && !containsStatus(f.Statuses, ticket.Status) {
+		return false
+	}
+
+	if !f.ActiveAt.IsZero() && isScheduledAfter(ticket, f.ActiveAt) {
+		return false
+	}
+
+	if !f.UpdatedAfter.IsZero() && !ticket.UpdatedAt.After(f.UpdatedAfter) {
+		return false
+	}
+
+	if f.Station != "" && !involvesStation(ticket, f.Station) {
+		return false
+	}
+
+	if f.Allergen != "" && !ticketHasAllergen(ticket, f.Allergen) {
+		return false
+	}
+
+	if containsID(f.ExcludeIDs, ticket.ID) {
+		return false
+	}
+
+	if f.Expression != nil && !f.Expression(ticket) {
+		return false
+	}
+
+	return true
+}
+
+func getTicketFilter(r *http.Request, pageLimit int) (TicketFilter, error) {
+	query := r.URL.Query()
+	filter := TicketFilter{Limit: defaultPageLimit}
+
+	if after := query.Get("after"); after != "" {
+		if id, err := strconv.Atoi(after); err == nil {
+			filter.AfterID = id
+		} else if err := decodeCursor(after, &filter); err != nil {
+			return TicketFilter{}, err
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return TicketFilter{}, fmt.Errorf("invalid limit %q, %v", limit, err)
+		}
+	}
+
+	if filter.Limit < 1 || filter.Limit > pageLimit {
+		return TicketFilter{}, fmt.Errorf("limit must be between 1 and %d, got %d", pageLimit, filter.Limit)
+	}
+
+	if statuses := query.Get("status"); statuses != "" {
+		for _, name := range strings.Split(statuses, ",") {
+			status, err := ParseStatus(name)
+			if err != nil {
+				return TicketFilter{}, err
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if since := query.Get("updatedSince"); since != "" {
+		updatedSince, err := time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			return TicketFilter{}, fmt.Errorf("invalid updatedSince %q, %v", since, err)
+		}
+		filter.UpdatedAfter = updatedSince
+		filter.IncludeDeleted = true
+	}
+
+	filter.Station = query.Get("station")
+
+	if allergen := query.Get("allergen"); allergen != "" {
+		if !isAllergenKnown(allergen) {
+			return TicketFilter{}, fmt.Errorf("unknown allergen %q", allergen)
+		}
+		filter.Allergen = allergen
+	}
+
+	if expression := query.Get("filter"); expression != "" {
+		var err error
+		filter.Expression, err = ParseFilterExpression(expression)
+		if err != nil {
+			return TicketFilter{}, err
+		}
+	}
+
+	return filter, nil
+}
+
+// resolveCursor places a bare ticket ID cursor wherever that ticket sits in
+// the queue now. Cursors from NextCursor already carry their position.
+func (k *KitchenServer) resolveCursor(r *http.Request, filter *TicketFilter) {
+	if _, err := strconv.Atoi(r.URL.Query().Get("after")); err != nil {
+		return
+	}
+
+	if cursor, err := k.storeFor(r).GetTicketByID(filter.AfterID); err == nil {
+		filter.AfterRank = cursor.QueueRank
+		filter.AfterRush = cursor.Rush
+	}
+}
+
+// encodeCursor captures where ticket sits in the queue, so the next page
+// starts from the same place even if ticket is moved, rushed or deleted in
+// the meantime.
+func encodeCursor(ticket Ticket) string {
+	cursor := fmt.Sprintf("%d:%t:%d", ticket.QueueRank, ticket.Rush, ticket.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+func decodeCursor(cursor string, filter *TicketFilter) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("invalid after cursor %q", cursor)
+	}
+
+	parts := strings.Split(string(data), ":")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid after cursor %q", cursor)
+	}
+
+	rank, rankErr := strconv.Atoi(parts[0])
+	rush, rushErr := strconv.ParseBool(parts[1])
+	id, idErr := strconv.Atoi(parts[2])
+	if rankErr != nil || rushErr != nil || idErr != nil || id < 1 {
+		return fmt.Errorf("invalid after cursor %q", cursor)
+	}
+
+	filter.AfterID, filter.AfterRank, filter.AfterRush = id, rank, rush
+	return nil
+}
+
+func queueBefore(a, b Ticket) bool {
+	if a.QueueRank != b.QueueRank {
+		return a.QueueRank < b.QueueRank
+	}
+
+	if a.Rush != b.Rush {
+		return a.Rush
+	}
+
+	return a.ID < b.ID
+}
+
+func containsStatus(statuses []Status, status Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsID(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const maxFilterExpressionLength = 1024
+
+type ticketPredicate func(Ticket) bool
+
+type filterField func(op, value string) (ticketPredicate, error)
+
+var filterFields = map[string]filterField{
+	"id":        intFilterField(func(t Ticket) int64 { return int64(t.ID) }),
+	"status":    statusFilterField,
+	"station":   stringFilterField(func(t Ticket) string { return t.Station }),
+	"orderid":   stringFilterField(func(t Ticket) string { return t.OrderID }),
+	"rush":      boolFilterField(func(t Ticket) bool { return t.Rush }),
+	"queuerank": intFilterField(func(t Ticket) int64 { return int64(t.QueueRank) }),
+	"tipcents":  intFilterField(func(t Ticket) int64 { return t.TipCents }),
+	"items":     intFilterField(func(t Ticket) int64 { return int64(len(t.Items)) }),
+}
+
+func intFilterField(get func(Ticket) int64) filterField {
+	return func(op, value string) (ticketPredicate, error) {
+		want, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", value)
+		}
+
+		switch op {
+		case "eq":
+			return func(t Ticket) bool { return get(t) == want }, nil
+		case "ne":
+			return func(t Ticket) bool { return get(t) != want }, nil
+		case "gt":
+			return func(t Ticket) bool { return get(t) > want }, nil
+		case "lt":
+			return func(t Ticket) bool { return get(t) < want }, nil
+		}
+
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+}
+
+func stringFilterField(get func(Ticket) string) filterField {
+	return func(op, value string) (ticketPredicate, error) {
+		return equalityPredicate(op, func(t Ticket) bool { return get(t) == value })
+	}
+}
+
+func boolFilterField(get func(Ticket) bool) filterField {
+	return func(op, value string) (ticketPredicate, error) {
+		want, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", value)
+		}
+
+		return equalityPredicate(op, func(t Ticket) bool { return get(t) == want })
+	}
+}
+
+func statusFilterField(op, value string) (ticketPredicate, error) {
+	want, err := ParseStatus(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return equalityPredicate(op, func(t Ticket) bool { return t.Status == want })
+}
+
+func equalityPredicate(op string, equal ticketPredicate) (ticketPredicate, error) {
+	switch op {
+	case "eq":
+		return equal, nil
+	case "ne":
+		return func(t Ticket) bool { return !equal(t) }, nil
+	}
+
+	return nil, fmt.Errorf("unknown operator %q, want eq or ne", op)
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func (t filterToken) is(keyword string) bool {
+	return !t.quoted && strings.EqualFold(t.text, keyword)
+}
+
+func ParseFilterExpression(expression string) (ticketPredicate, error) {
+	if len(expression) > maxFilterExpressionLength {
+		return nil, fmt.Errorf("filter is longer than %d characters", maxFilterExpressionLength)
+	}
+
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &filterParser{tokens: tokens}
+	predicate, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if token, ok := parser.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q in filter", token.text)
+	}
+
+	return predicate, nil
+}
+
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	tokens := []filterToken{}
+	runes := []rune(expression)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+		case r == '(' || r == ')':
+			tokens = append(tokens, filterToken{text: string(r)})
+		case r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string in filter")
+			}
+			tokens = append(tokens, filterToken{text: string(runes[i+1 : end]), quoted: true})
+			i = end
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("()'", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, filterToken{text: string(runes[i:end])})
+			i = end - 1
+		}
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() (filterToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *filterParser) next() (filterToken, error) {
+	token, ok := p.peek()
+	if !ok {
+		return filterToken{}, fmt.Errorf("filter ended unexpectedly")
+	}
+	p.pos++
+
+	return token, nil
+}
+
+func (p *filterParser) parseOr() (ticketPredicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for token, ok := p.peek(); ok && token.is("or"); token, ok = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		either := left
+		left = func(t Ticket) bool { return either(t) || right(t) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (ticketPredicate, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for token, ok := p.peek(); ok && token.is("and"); token, ok = p.peek() {
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		both := left
+		left = func(t Ticket) bool { return both(t) && right(t) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseTerm() (ticketPredicate, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if token.is("(") {
+		predicate, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		closing, err := p.next()
+		if err != nil || !closing.is(")") {
+			return nil, fmt.Errorf("missing closing parenthesis in filter")
+		}
+
+		return predicate, nil
+	}
+
+	field, ok := filterFields[strings.ToLower(token.text)]
+	if token.quoted || !ok {
+		return nil, fmt.Errorf("unknown filter field %q", token.text)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if op.quoted {
+		return nil, fmt.Errorf("unknown operator %q", op.text)
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if value.is("(") || value.is(")") {
+		return nil, fmt.Errorf("missing value for %q", token.text)
+	}
+
+	predicate, err := field(strings.ToLower(op.text), value.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter on %q, %v", token.text, err)
+	}
+
+	return predicate, nil
+}
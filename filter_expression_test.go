@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	tickets := []Ticket{
+		{ID: 1, Status: STATUS_ACCEPTED, Station: "grill", QueueRank: 2},
+		{ID: 2, Status: STATUS_ACCEPTED, Station: "fryer", Rush: true},
+		{ID: 3, Status: STATUS_PENDING, Station: "grill", TipCents: 500},
+		{ID: 4, Status: STATUS_COMPLETED, Station: "salad bar", Items: Items{{Name: "salad"}, {Name: "soup"}}},
+	}
+
+	cases := map[string][]int{
+		"status eq accepted":                                {1, 2},
+		"status ne accepted":                                {3, 4},
+		"status eq accepted and queueRank gt 0":             {1},
+		"station eq grill or rush eq true":                  {1, 2, 3},
+		"tipCents gt 100 or items gt 1":                     {3, 4},
+		"id lt 3 and (station eq fryer or queueRank eq 2)":  {1, 2},
+		"station eq 'salad bar'":                            {4},
+		"STATUS EQ pending":                                 {3},
+		"station eq grill and status eq pending or id eq 4": {3, 4},
+	}
+
+	for expression, want := range cases {
+		t.Run(expression, func(t *testing.T) {
+			predicate, err := ParseFilterExpression(expression)
+			if err != nil {
+				t.Fatalf("didn't expect an error but got one, %v", err)
+			}
+
+			got := []int{}
+			for _, ticket := range tickets {
+				if predicate(ticket) {
+					got = append(got, ticket.ID)
+				}
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got IDs %v, want %v", got, want)
+			}
+		})
+	}
+
+	malformed := []string{
+		"",
+		"status",
+		"status eq",
+		"priority gt 0",
+		"status gt accepted",
+		"status like accepted",
+		"status eq done",
+		"queueRank gt high",
+		"rush eq maybe",
+		"status eq accepted and",
+		"status eq accepted or or id eq 1",
+		"(status eq accepted",
+		"status eq accepted)",
+		"station eq 'grill",
+		"'status' eq accepted",
+	}
+
+	for _, expression := range malformed {
+		t.Run("rejects "+expression, func(t *testing.T) {
+			if _, err := ParseFilterExpression(expression); err == nil {
+				t.Errorf("expected an error but didn't get one")
+			}
+		})
+	}
+}
+
+func TestFilterParameter(t *testing.T) {
+	store := NewInMemoryKitchenStore()
+	for _, ticket := range []Ticket{
+		{Status: STATUS_ACCEPTED, Station: "grill"},
+		{Status: STATUS_ACCEPTED, Station: "fryer"},
+		{Status: STATUS_PENDING, Station: "grill"},
+	} {
+		ticket.Items = Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}
+		store.StoreTicket(ticket)
+	}
+	server := NewKitchenServer(store)
+
+	t.Run("lists tickets matching the filter", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?filter="+url.QueryEscape("status eq accepted and station eq grill")))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := []int{}
+		for _, ticket := range getTicketPageFromResponse(t, response.Body).Tickets {
+			got = append(got, ticket.ID)
+		}
+		if want := []int{1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("returns Bad Request on an unknown field", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?filter="+url.QueryEscape("priority gt 0")))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("returns Bad Request on a malformed filter", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?filter="+url.QueryEscape("status eq (accepted")))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
@@ -1,3 +1,14 @@
 module github.com/VitoNaychev/bt-kitchen-svc
 
-go 1.19
+go 1.23.0
+
+require (
+	github.com/segmentio/kafka-go v0.4.51
+	golang.org/x/net v0.38.0
+	golang.org/x/text v0.23.0
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+)
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+func (k *KitchenServer) getTicketHistory(w http.ResponseWriter, r *http.Request) {
+	stringID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/ticket/"), "/history")
+	ticketID, ok := k.pathTicketID(w, stringID)
+	if !ok {
+		return
+	}
+
+	store := k.storeFor(r)
+	if _, err := store.GetTicketByID(ticketID); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	events, err := store.GetTicketEvents(ticketID)
+	if err != nil {
+		k.logger.Error("unable to get ticket history", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		k.writeJSON(w, http.StatusOK, events)
+	case "ndjson":
+		k.writeNDJSON(w, events)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (k *KitchenServer) writeNDJSON(w http.ResponseWriter, events []TicketEvent) {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	for _, event := range events {
+		data, err := k.marshalJSON(event)
+		if err != nil {
+			k.logger.Error("unable to encode ticket event", "ticket_id", event.TicketID, "error", err)
+			return
+		}
+
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return
+		}
+
+		controller.Flush()
+	}
+}
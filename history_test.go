@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTicketHistory(t *testing.T) {
+	store := &StubKitchenStore{
+		tickets: []Ticket{{ID: 0, Status: STATUS_ACCEPTED}},
+		events: []TicketEvent{
+			{Type: EVENT_CREATED, TicketID: 0, Status: STATUS_PENDING},
+			{Type: EVENT_ACCEPTED, TicketID: 0, Status: STATUS_ACCEPTED},
+		},
+	}
+	server := NewKitchenServer(store)
+
+	t.Run("returns history as a JSON array by default", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest("/ticket/0/history"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var got []TicketEvent
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("unable to parse history, %v", err)
+		}
+		assertEvents(t, got, store.events)
+	})
+
+	t.Run("streams history as NDJSON", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest("/ticket/0/history?format=ndjson"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertHeader(t, response, "Content-Type", ndjsonContentType)
+
+		got := []TicketEvent{}
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			event := TicketEvent{}
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				t.Fatalf("unable to parse line %q into an event, %v", scanner.Text(), err)
+			}
+			got = append(got, event)
+		}
+		assertEvents(t, got, store.events)
+	})
+
+	t.Run("flushes NDJSON past buffering middleware", func(t *testing.T) {
+		server := NewKitchenServer(store, WithRequestTimeout(time.Second), WithDefaultVersion(VERSION_V2))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest("/ticket/0/history?format=ndjson&pretty=true"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if !response.Flushed {
+			t.Error("expected NDJSON history to flush as it goes but it was buffered")
+		}
+	})
+
+	t.Run("returns 404 on missing ticket", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest("/ticket/7/history"))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("returns 400 on unknown format", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest("/ticket/0/history?format=xml"))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func newHistoryRequest(path string) *http.Request {
+	request, _ := http.NewRequest(http.MethodGet, path, nil)
+	return request
+}
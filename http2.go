@@ -0,0 +1,16 @@
+package main
+
+import (
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func newPublicServer(addr string, handler http.Handler, cleartextHTTP2 bool) *http.Server {
+	if cleartextHTTP2 {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+
+	return &http.Server{Addr: addr, Handler: handler}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/http2"
+)
+
+func TestCleartextHTTP2(t *testing.T) {
+	store := &StubKitchenStore{}
+	public := newPublicServer("", NewKitchenServer(store), true)
+	server := httptest.NewServer(public.Handler)
+	defer server.Close()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+
+	t.Run("creates a ticket over h2c", func(t *testing.T) {
+		response, err := client.Post(server.URL+"/ticket/", "application/json", strings.NewReader(`{"Items": ["burger"]}`))
+		if err != nil {
+			t.Fatalf("unable to create ticket, %v", err)
+		}
+		defer response.Body.Close()
+
+		assertStatus(t, response.StatusCode, http.StatusAccepted)
+		if response.ProtoMajor != 2 {
+			t.Errorf("got protocol %s, want HTTP/2", response.Proto)
+		}
+	})
+
+	t.Run("streams events over h2c", func(t *testing.T) {
+		response, err := client.Get(server.URL + "/ticket/stream")
+		if err != nil {
+			t.Fatalf("unable to open stream, %v", err)
+		}
+		defer response.Body.Close()
+
+		client.Post(server.URL+"/ticket/", "application/json", strings.NewReader(`{"Items": ["fries"]}`))
+
+		line, _ := bufio.NewReader(response.Body).ReadString('\n')
+		if line != "event: created\n" {
+			t.Errorf("got line %q, want a created event", line)
+		}
+	})
+}
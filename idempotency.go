@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader  = "Idempotency-Key"
+	defaultIdempotencyTTL = 24 * time.Hour
+)
+
+// idempotencyEntry is reserved by the first request with a key and done once
+// that request has created its ticket, or given up. The ticket ID is only
+// read after done is closed.
+type idempotencyEntry struct {
+	key         string
+	payloadHash string
+	seenAt      time.Time
+	done        chan struct{}
+	created     bool
+	ticketID    int
+}
+
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	order   []*idempotencyEntry
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{entries: map[string]*idempotencyEntry{}}
+}
+
+// reserve returns the live entry for key, or reserves a new one for the
+// caller to complete or release when reserved is true.
+func (c *idempotencyCache) reserve(key, payloadHash string, now time.Time, ttl time.Duration) (entry *idempotencyEntry, reserved bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(now, ttl)
+
+	if entry, ok := c.entries[key]; ok {
+		return entry, false
+	}
+
+	entry = &idempotencyEntry{key: key, payloadHash: payloadHash, seenAt: now, done: make(chan struct{})}
+	c.entries[key] = entry
+	c.order = append(c.order, entry)
+	return entry, true
+}
+
+// expire drops entries older than ttl. Entries are kept in the order they
+// were reserved, so only the expired ones at the front are visited.
+func (c *idempotencyCache) expire(now time.Time, ttl time.Duration) {
+	expired := 0
+	for _, entry := range c.order {
+		if now.Sub(entry.seenAt) < ttl {
+			break
+		}
+		if c.entries[entry.key] == entry {
+			delete(c.entries, entry.key)
+		}
+		expired++
+	}
+
+	c.order = c.order[expired:]
+}
+
+func (c *idempotencyCache) complete(entry *idempotencyEntry, ticketID int) {
+	entry.ticketID = ticketID
+	entry.created = true
+	close(entry.done)
+}
+
+// release gives up a reservation that didn't create a ticket, letting the
+// next request with its key try again.
+func (c *idempotencyCache) release(entry *idempotencyEntry) {
+	c.forget(entry)
+	close(entry.done)
+}
+
+func (c *idempotencyCache) forget(entry *idempotencyEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[entry.key] == entry {
+		delete(c.entries, entry.key)
+	}
+}
+
+func (k *KitchenServer) idempotencyKey(r *http.Request) (string, string, bool) {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" || r.Body == nil {
+		return "", "", false
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", "", false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	normalized := &bytes.Buffer{}
+	if json.Compact(normalized, body) != nil {
+		normalized = bytes.NewBuffer(body)
+	}
+
+	kitchenID, _ := r.Context().Value(kitchenIDKey{}).(string)
+	payload := sha256.New()
+	payload.Write([]byte(r.URL.RawQuery))
+	payload.Write([]byte{0})
+	payload.Write(normalized.Bytes())
+
+	return idempotencyScope(k, r) + "\x00" + kitchenID + "\x00" + key, hex.EncodeToString(payload.Sum(nil)), true
+}
+
+func idempotencyScope(k *KitchenServer, r *http.Request) string {
+	if client, ok := dedupClient(k, r); ok {
+		return client
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
+
+// serveIdempotentReplay answers a request whose key was already used, waiting
+// for a concurrent request with the same key to finish first. Otherwise it
+// returns the reservation the caller must complete or release.
+func (k *KitchenServer) serveIdempotentReplay(w http.ResponseWriter, r *http.Request, key, payloadHash string) (*idempotencyEntry, bool) {
+	for {
+		entry, reserved := k.idempotency.reserve(key, payloadHash, k.clock.Now(), k.idempotencyTTL)
+		if reserved {
+			return entry, false
+		}
+
+		if entry.payloadHash != payloadHash {
+			k.writeError(w, http.StatusUnprocessableEntity, CODE_IDEMPOTENCY_KEY_REUSED, "idempotency key reused with different request")
+			return nil, true
+		}
+
+		select {
+		case <-entry.done:
+		case <-r.Context().Done():
+			return nil, true
+		}
+
+		if !entry.created {
+			continue
+		}
+
+		if _, err := k.storeFor(r).GetTicketByID(entry.ticketID); err != nil {
+			k.idempotency.forget(entry)
+			continue
+		}
+
+		w.Header().Set("Idempotent-Replayed", "true")
+		k.writeJSON(w, http.StatusAccepted, CreateTicketResponse{ID: entry.ticketID})
+		return nil, true
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	burger := Ticket{Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+	fries := Ticket{Items: Items{{Name: "fries", Quantity: 1, Unit: UNIT_EACH}}}
+
+	create := func(t testing.TB, server *KitchenServer, ticket Ticket, key string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		request := newCreateTicketRequest(ticket)
+		request.Header.Set(idempotencyKeyHeader, key)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("replays the original ticket for an identical request", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		first := create(t, server, burger, "order-1")
+		assertStatus(t, first.Code, http.StatusAccepted)
+
+		replay := create(t, server, burger, "order-1")
+		assertStatus(t, replay.Code, http.StatusAccepted)
+		assertHeader(t, replay, "Idempotent-Replayed", "true")
+		assertTicketResponse(t, replay.Body, CreateTicketResponse{ID: 0})
+
+		if len(store.tickets) != 1 {
+			t.Errorf("got %d tickets, want 1", len(store.tickets))
+		}
+	})
+
+	t.Run("rejects a reused key with a different request", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		create(t, server, burger, "order-1")
+		response := create(t, server, fries, "order-1")
+
+		assertStatus(t, response.Code, http.StatusUnprocessableEntity)
+		assertErrorCode(t, response, CODE_IDEMPOTENCY_KEY_REUSED)
+		if len(store.tickets) != 1 {
+			t.Errorf("got %d tickets, want 1", len(store.tickets))
+		}
+	})
+
+	t.Run("creates separate tickets for separate keys", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		create(t, server, burger, "order-1")
+		create(t, server, burger, "order-2")
+
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("forgets a key after its TTL", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		clock := &StubClock{now: time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+		server := NewKitchenServer(store, WithClock(clock), WithIdempotencyTTL(time.Hour))
+
+		create(t, server, burger, "order-1")
+		clock.now = clock.now.Add(time.Hour)
+		response := create(t, server, fries, "order-1")
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("creates one ticket for concurrent requests with the same key", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				create(t, server, burger, "order-1")
+			}()
+		}
+		wg.Wait()
+
+		if count, _ := store.CountTickets(TicketFilter{}); count != 1 {
+			t.Errorf("got %d tickets, want 1", count)
+		}
+	})
+
+	t.Run("lets a key be retried after a failed request", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		response := create(t, server, Ticket{}, "order-1")
+		assertStatus(t, response.Code, http.StatusBadRequest)
+
+		response = create(t, server, burger, "order-1")
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if len(store.tickets) != 1 {
+			t.Errorf("got %d tickets, want 1", len(store.tickets))
+		}
+	})
+}
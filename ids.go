@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func parseTicketID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, newValidationError(CODE_ID_OUT_OF_RANGE, "ticket ID out of range")
+	}
+	if err != nil {
+		return 0, newValidationError(CODE_INVALID_ID, "invalid ticket ID %q", s)
+	}
+
+	return id, nil
+}
+
+func (k *KitchenServer) pathTicketID(w http.ResponseWriter, s string) (int, bool) {
+	id, err := parseTicketID(s)
+	if err != nil {
+		k.writeValidationError(w, err)
+		return 0, false
+	}
+
+	return id, true
+}
+
+func parseTicketIDs(list string, max int) ([]int, error) {
+	ids := []int{}
+	seen := map[int]bool{}
+	for _, field := range strings.Split(list, ",") {
+		id, err := parseTicketID(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) > max {
+		return nil, fmt.Errorf("at most %d ticket IDs are allowed, got %d", max, len(ids))
+	}
+
+	return ids, nil
+}
+
+func (k *KitchenServer) getTicketsByIDs(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseTicketIDs(r.URL.Query().Get("ids"), k.limits.MaxPageLimit)
+	if err != nil {
+		k.writeValidationError(w, err)
+		return
+	}
+
+	tickets, err := k.storeFor(r).GetTicketsByIDs(ids)
+	if err != nil {
+		k.logger.Error("unable to get tickets by ID", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	found := map[int]bool{}
+	for _, ticket := range tickets {
+		found[ticket.ID] = true
+	}
+
+	missing := []string{}
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, strconv.Itoa(id))
+		}
+	}
+
+	if len(missing) > 0 {
+		w.Header().Set("X-Missing-IDs", strings.Join(missing, ","))
+	}
+
+	k.writeJSON(w, http.StatusOK, TicketPage{Tickets: tickets})
+}
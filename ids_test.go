@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetTicketsByIDs(t *testing.T) {
+	store := &StubKitchenStore{tickets: []Ticket{
+		{ID: 1, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}},
+		{ID: 5, Items: Items{{Name: "fries", Quantity: 1, Unit: UNIT_EACH}}},
+		{ID: 9, Items: Items{{Name: "shake", Quantity: 1, Unit: UNIT_EACH}}},
+	}}
+
+	getIDs := func(t testing.TB, query string) (*httptest.ResponseRecorder, []int) {
+		t.Helper()
+
+		server := NewKitchenServer(store)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest(query))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		ids := []int{}
+		for _, ticket := range getTicketPageFromResponse(t, response.Body).Tickets {
+			ids = append(ids, ticket.ID)
+		}
+		return response, ids
+	}
+
+	t.Run("returns tickets in request order", func(t *testing.T) {
+		response, got := getIDs(t, "?ids=9,1,5")
+
+		assertIDs(t, got, []int{9, 1, 5})
+		assertHeader(t, response, "X-Missing-IDs", "")
+	})
+
+	t.Run("omits missing tickets and lists their IDs", func(t *testing.T) {
+		response, got := getIDs(t, "?ids=5,2,1,7")
+
+		assertIDs(t, got, []int{5, 1})
+		assertHeader(t, response, "X-Missing-IDs", "2,7")
+	})
+
+	t.Run("returns duplicate IDs once", func(t *testing.T) {
+		response, got := getIDs(t, "?ids=5,1,5,3,3")
+
+		assertIDs(t, got, []int{5, 1})
+		assertHeader(t, response, "X-Missing-IDs", "3")
+	})
+
+	t.Run("returns Bad Request on malformed IDs", func(t *testing.T) {
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?ids=1,burger"))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestTicketIDOutOfRange(t *testing.T) {
+	outOfRange := "99999999999999999999"
+	requests := map[string]*http.Request{
+		"get":     newGetTicketRequestForPath("/ticket/" + outOfRange),
+		"history": newGetTicketRequestForPath("/ticket/" + outOfRange + "/history"),
+		"action":  newPostTicketRequest("/ticket/"+outOfRange+"/complete", Ticket{}),
+		"ids":     newListTicketsRequest("?ids=1," + outOfRange),
+	}
+
+	for name, request := range requests {
+		t.Run("returns Bad Request with a clear error on "+name, func(t *testing.T) {
+			server := NewKitchenServer(&StubKitchenStore{})
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusBadRequest)
+			assertErrorCode(t, response, CODE_ID_OUT_OF_RANGE)
+		})
+	}
+
+	t.Run("distinguishes malformed IDs", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequestForPath("/ticket/burger"))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_INVALID_ID)
+	})
+}
+
+func newGetTicketRequestForPath(path string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	return req
+}
+
+func assertIDs(t testing.TB, got, want []int) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got IDs %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+var errTicketIDsExhausted = errors.New("ticket IDs exhausted")
+
+type InMemoryKitchenStore struct {
+	mu        sync.RWMutex
+	tickets   map[int]Ticket
+	events    map[int][]TicketEvent
+	byOrderID map[string]int
+	byStatus  map[Status]map[int]bool
+	templates map[string]Template
+	lastID    int
+
+	maintenance Maintenance
+
+	outbox       []OutboxEntry
+	lastOutboxID int
+
+	eventSourced bool
+	log          []StoreChange
+}
+
+func NewInMemoryKitchenStore() *InMemoryKitchenStore {
+	return &InMemoryKitchenStore{
+		tickets:   map[int]Ticket{},
+		events:    map[int][]TicketEvent{},
+		byOrderID: map[string]int{},
+		byStatus:  map[Status]map[int]bool{},
+		templates: map[string]Template{},
+	}
+}
+
+func (i *InMemoryKitchenStore) GetTicketByID(ticketID int) (Ticket, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	ticket, ok := i.tickets[ticketID]
+	if !ok || ticket.Deleted {
+		return Ticket{}, fmt.Errorf("no ticket with ID = %d", ticketID)
+	}
+
+	return ticket, nil
+}
+
+func (i *InMemoryKitchenStore) StoreTicket(ticket Ticket) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.storeTicket(ticket)
+}
+
+func (i *InMemoryKitchenStore) StoreTicketIfNotExists(ticket Ticket) (Ticket, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.storeTicketIfNotExists(ticket)
+}
+
+func (i *InMemoryKitchenStore) storeTicketIfNotExists(ticket Ticket) (Ticket, bool, error) {
+	if id, ok := i.byOrderID[orderKey(ticket)]; ok && !i.tickets[id].Deleted {
+		return i.tickets[id], false, nil
+	}
+
+	id, err := i.storeTicket(ticket)
+	if err != nil {
+		return Ticket{}, false, err
+	}
+	ticket.ID = id
+
+	return ticket, true, nil
+}
+
+func (i *InMemoryKitchenStore) storeTicket(ticket Ticket) (int, error) {
+	if i.lastID == math.MaxInt {
+		return 0, errTicketIDsExhausted
+	}
+
+	ticket.ID = i.lastID + 1
+	i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+
+	return ticket.ID, nil
+}
+
+func orderKey(ticket Ticket) string {
+	return ticket.KitchenID + "/" + ticket.OrderID
+}
+
+func (i *InMemoryKitchenStore) UpdateTicket(ticket Ticket) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if existing, ok := i.tickets[ticket.ID]; !ok || existing.Deleted {
+		return fmt.Errorf("no ticket with ID = %d", ticket.ID)
+	}
+	i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+
+	return nil
+}
+
+func (i *InMemoryKitchenStore) DeleteTicket(ticketID int, deletedAt time.Time) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.deleteTicket(ticketID, deletedAt)
+}
+
+func (i *InMemoryKitchenStore) deleteTicket(ticketID int, deletedAt time.Time) error {
+	ticket, ok := i.tickets[ticketID]
+	if !ok || ticket.Deleted {
+		return fmt.Errorf("no ticket with ID = %d", ticketID)
+	}
+
+	ticket.Deleted = true
+	ticket.UpdatedAt = deletedAt
+	i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+
+	return nil
+}
+
+// RemoveTicket drops a ticket without leaving a tombstone, for writes that are
+// undone before anyone could have seen them.
+func (i *InMemoryKitchenStore) RemoveTicket(ticketID int) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ticket, ok := i.tickets[ticketID]
+	if !ok {
+		return fmt.Errorf("no ticket with ID = %d", ticketID)
+	}
+	i.apply(StoreChange{Type: CHANGE_DELETE, Ticket: ticket})
+
+	return nil
+}
+
+func (i *InMemoryKitchenStore) StoreTicketEvent(event TicketEvent) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.apply(StoreChange{Type: CHANGE_EVENT, Event: event})
+
+	return nil
+}
+
+func (i *InMemoryKitchenStore) GetTicketEvents(ticketID int) ([]TicketEvent, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	events := make([]TicketEvent, len(i.events[ticketID]))
+	copy(events, i.events[ticketID])
+
+	return events, nil
+}
+
+func (i *InMemoryKitchenStore) GetTickets(filter TicketFilter) ([]Ticket, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	tickets := []Ticket{}
+	i.eachCandidate(filter, func(ticket Ticket) {
+		if filter.Matches(ticket) {
+			tickets = append(tickets, ticket)
+		}
+	})
+	sort.Slice(tickets, func(a, b int) bool {
+		return queueBefore(tickets[a], tickets[b])
+	})
+
+	if len(tickets) > filter.Limit {
+		tickets = tickets[:filter.Limit]
+	}
+
+	return tickets, nil
+}
+
+func (i *InMemoryKitchenStore) GetTicketsByIDs(ticketIDs []int) ([]Ticket, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	tickets := []Ticket{}
+	for _, id := range ticketIDs {
+		if ticket, ok := i.tickets[id]; ok && !ticket.Deleted {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	return tickets, nil
+}
+
+func (i *InMemoryKitchenStore) StreamTickets(ctx context.Context, fn func(Ticket) error) error {
+	i.mu.RLock()
+	ids := make([]int, 0, len(i.tickets))
+	for id := range i.tickets {
+		ids = append(ids, id)
+	}
+	i.mu.RUnlock()
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		i.mu.RLock()
+		ticket, ok := i.tickets[id]
+		i.mu.RUnlock()
+		if !ok || ticket.Deleted {
+			continue
+		}
+
+		if err := fn(ticket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *InMemoryKitchenStore) CountTickets(filter TicketFilter) (int, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	count := 0
+	i.eachCandidate(filter, func(ticket Ticket) {
+		if filter.Matches(ticket) {
+			count++
+		}
+	})
+
+	return count, nil
+}
+
+func (i *InMemoryKitchenStore) eachCandidate(filter TicketFilter, fn func(Ticket)) {
+	if len(filter.Statuses) == 0 {
+		for _, ticket := range i.tickets {
+			fn(ticket)
+		}
+		return
+	}
+
+	seen := map[Status]bool{}
+	for _, status := range filter.Statuses {
+		if seen[status] {
+			continue
+		}
+		seen[status] = true
+
+		for id := range i.byStatus[status] {
+			fn(i.tickets[id])
+		}
+	}
+}
+
+func (i *InMemoryKitchenStore) indexStatus(ticket Ticket) {
+	if i.byStatus[ticket.Status] == nil {
+		i.byStatus[ticket.Status] = map[int]bool{}
+	}
+	i.byStatus[ticket.Status][ticket.ID] = true
+}
+
+func (i *InMemoryKitchenStore) ClaimNextTicket(filter TicketFilter, claim Claim) (Ticket, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.claimNextTicket(filter, claim)
+}
+
+func (i *InMemoryKitchenStore) claimNextTicket(filter TicketFilter, claim Claim) (Ticket, bool, error) {
+	if claim.Capacity > 0 {
+		accepted := TicketFilter{KitchenID: filter.KitchenID, Station: filter.Station, Statuses: []Status{STATUS_ACCEPTED}}
+		count := 0
+		i.eachCandidate(accepted, func(ticket Ticket) {
+			if accepted.Matches(ticket) {
+				count++
+			}
+		})
+		if count >= claim.Capacity {
+			return Ticket{}, false, ErrStationFull
+		}
+	}
+
+	next, found := Ticket{}, false
+	if claim.TicketID > 0 {
+		only := filter
+		only.Station = ""
+		next, found = i.tickets[claim.TicketID]
+		found = found && only.Matches(next)
+	} else {
+		i.eachCandidate(filter, func(ticket Ticket) {
+			if filter.Matches(ticket) && (!found || queueBefore(ticket, next)) {
+				next, found = ticket, true
+			}
+		})
+	}
+
+	if !found {
+		return Ticket{}, false, nil
+	}
+
+	next.Status = STATUS_ACCEPTED
+	next.ClaimedBy = claim.By
+	next.UpdatedAt = claim.At
+	i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: next})
+
+	return next, true, nil
+}
+
+func (i *InMemoryKitchenStore) MoveTicket(ticketID int, delta int, movedAt time.Time) (Ticket, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.moveTicket(ticketID, delta, movedAt)
+}
+
+func (i *InMemoryKitchenStore) moveTicket(ticketID int, delta int, movedAt time.Time) (Ticket, error) {
+	ticket, ok := i.tickets[ticketID]
+	if !ok || ticket.Deleted {
+		return Ticket{}, fmt.Errorf("no ticket with ID = %d", ticketID)
+	}
+
+	ticket.QueueRank += delta
+	ticket.UpdatedAt = movedAt
+	i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+
+	return ticket, nil
+}
+
+// ExpireTicket cancels the ticket if, read under the store lock, it is still
+// active and past its ExpiresAt.
+func (i *InMemoryKitchenStore) ExpireTicket(ticketID int, now time.Time) (Ticket, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.expireTicket(ticketID, now)
+}
+
+func (i *InMemoryKitchenStore) expireTicket(ticketID int, now time.Time) (Ticket, bool, error) {
+	ticket, ok := i.tickets[ticketID]
+	if !ok {
+		return Ticket{}, false, fmt.Errorf("no ticket with ID = %d", ticketID)
+	}
+
+	if ticket.Deleted || !containsStatus(activeStatuses, ticket.Status) || !isExpired(ticket, now) {
+		return ticket, false, nil
+	}
+
+	ticket.Status = STATUS_CANCELLED
+	ticket.UpdatedAt = now
+	i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+
+	return ticket, true, nil
+}
+
+// PurgeCompletedBefore turns completed tickets last updated before the cutoff
+// into tombstones stamped with now, so updatedSince sync still reports them,
+// and drops tombstones that are themselves older than the cutoff. A sync
+// cursor older than the last purge cutoff can miss deletions and must resync
+// from scratch.
+func (i *InMemoryKitchenStore) PurgeCompletedBefore(before, now time.Time) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	removed := 0
+	for _, ticket := range i.tickets {
+		if !ticket.UpdatedAt.Before(before) {
+			continue
+		}
+
+		switch {
+		case ticket.Deleted:
+			i.apply(StoreChange{Type: CHANGE_DELETE, Ticket: ticket})
+		case ticket.Status == STATUS_COMPLETED:
+			ticket.Deleted = true
+			ticket.UpdatedAt = now
+			i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+func (i *InMemoryKitchenStore) StoreTemplate(template Template) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.apply(StoreChange{Type: CHANGE_TEMPLATE, Template: template})
+
+	return nil
+}
+
+func (i *InMemoryKitchenStore) GetTemplate(kitchenID, name string) (Template, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	template, ok := i.templates[templateKey(kitchenID, name)]
+	if !ok {
+		return Template{}, fmt.Errorf("%w %q", errTemplateNotFound, name)
+	}
+
+	return template, nil
+}
+
+func (i *InMemoryKitchenStore) DeleteTemplate(kitchenID, name string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	template, ok := i.templates[templateKey(kitchenID, name)]
+	if !ok {
+		return fmt.Errorf("%w %q", errTemplateNotFound, name)
+	}
+	i.apply(StoreChange{Type: CHANGE_DELETE_TEMPLATE, Template: template})
+
+	return nil
+}
+
+func (i *InMemoryKitchenStore) GetMaintenance() (Maintenance, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.maintenance, nil
+}
+
+func (i *InMemoryKitchenStore) SetMaintenance(maintenance Maintenance) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.apply(StoreChange{Type: CHANGE_MAINTENANCE, Maintenance: maintenance})
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInMemoryKitchenStore(t *testing.T) {
+	t.Run("stores tickets with increasing IDs", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+
+		first, _ := store.StoreTicket(Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		second, _ := store.StoreTicket(Ticket{Items: []Item{{Name: "fries", Quantity: 1, Unit: UNIT_EACH}}})
+
+		if second <= first {
+			t.Errorf("got IDs %d and %d, want increasing IDs", first, second)
+		}
+
+		got, err := store.GetTicketByID(second)
+		if err != nil {
+			t.Fatalf("didn't find stored ticket, %v", err)
+		}
+
+		want := Ticket{ID: second, Items: []Item{{Name: "fries", Quantity: 1, Unit: UNIT_EACH}}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("returns error instead of wrapping the ID counter", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		store.lastID = math.MaxInt
+
+		_, err := store.StoreTicket(Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		if !errors.Is(err, errTicketIDsExhausted) {
+			t.Errorf("got error %v, want %v", err, errTicketIDsExhausted)
+		}
+
+		_, _, err = store.StoreTicketIfNotExists(Ticket{OrderID: "order-1", Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		if !errors.Is(err, errTicketIDsExhausted) {
+			t.Errorf("got error %v, want %v", err, errTicketIDsExhausted)
+		}
+	})
+
+	t.Run("returns error on nonexistant ticket ID", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+
+		_, err := store.GetTicketByID(1)
+		if err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("returns tickets after ID in order up to limit", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		for _, item := range []string{"burger", "fries", "pizza", "water"} {
+			store.StoreTicket(Ticket{Items: []Item{{Name: item, Quantity: 1, Unit: UNIT_EACH}}})
+		}
+
+		tickets, _ := store.GetTickets(TicketFilter{AfterID: 1, Limit: 2})
+
+		got := []int{}
+		for _, ticket := range tickets {
+			got = append(got, ticket.ID)
+		}
+
+		want := []int{2, 3}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("purges completed tickets updated before cutoff", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		cutoff := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+		oldCompleted, _ := store.StoreTicket(Ticket{Status: STATUS_COMPLETED, UpdatedAt: cutoff.Add(-time.Minute)})
+		newCompleted, _ := store.StoreTicket(Ticket{Status: STATUS_COMPLETED, UpdatedAt: cutoff})
+		oldPending, _ := store.StoreTicket(Ticket{Status: STATUS_PENDING, UpdatedAt: cutoff.Add(-time.Minute)})
+
+		removed, _ := store.PurgeCompletedBefore(cutoff, cutoff)
+		if removed != 1 {
+			t.Errorf("got %d tickets removed, want 1", removed)
+		}
+
+		if _, err := store.GetTicketByID(oldCompleted); err == nil {
+			t.Errorf("old completed ticket wasn't purged")
+		}
+
+		for _, id := range []int{newCompleted, oldPending} {
+			if _, err := store.GetTicketByID(id); err != nil {
+				t.Errorf("ticket %d was purged, want it kept", id)
+			}
+		}
+	})
+
+	t.Run("updates existing tickets only", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		id, _ := store.StoreTicket(Ticket{Status: STATUS_PENDING})
+
+		err := store.UpdateTicket(Ticket{ID: id, Status: STATUS_ACCEPTED})
+		if err != nil {
+			t.Fatalf("unable to update ticket, %v", err)
+		}
+
+		got, _ := store.GetTicketByID(id)
+		if got.Status != STATUS_ACCEPTED {
+			t.Errorf("got status %v, want %v", got.Status, STATUS_ACCEPTED)
+		}
+
+		err = store.UpdateTicket(Ticket{ID: id + 1})
+		if err == nil {
+			t.Errorf("expected an error updating nonexistant ticket but didn't get one")
+		}
+	})
+
+	t.Run("returns ticket events in order", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		store.StoreTicketEvent(TicketEvent{Type: EVENT_CREATED, TicketID: 1})
+		store.StoreTicketEvent(TicketEvent{Type: EVENT_CREATED, TicketID: 2})
+		store.StoreTicketEvent(TicketEvent{Type: EVENT_REOPENED, TicketID: 1})
+
+		got, _ := store.GetTicketEvents(1)
+		want := []TicketEvent{
+			{Type: EVENT_CREATED, TicketID: 1},
+			{Type: EVENT_REOPENED, TicketID: 1},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got events %v, want %v", got, want)
+		}
+	})
+
+	t.Run("stores at most one ticket per order ID", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+
+		first, created, _ := store.StoreTicketIfNotExists(Ticket{OrderID: "order-42", Status: STATUS_PENDING})
+		if !created {
+			t.Errorf("didn't create ticket for new order ID")
+		}
+
+		second, created, _ := store.StoreTicketIfNotExists(Ticket{OrderID: "order-42", Status: STATUS_ACCEPTED})
+		if created {
+			t.Errorf("created duplicate ticket for existing order ID")
+		}
+
+		if !reflect.DeepEqual(second, first) {
+			t.Errorf("got %v, want existing ticket %v", second, first)
+		}
+	})
+}
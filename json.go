@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+var errEmptyBody = errors.New("request body is required")
+
+var idFields = map[string]bool{
+	"ID":        true,
+	"TicketID":  true,
+	"DependsOn": true,
+	"Lingering": true,
+}
+
+func (k *KitchenServer) writeJSON(w http.ResponseWriter, status int, v any) {
+	if _, ok := w.(*envelopeWriter); ok {
+		v = Envelope{Data: v}
+	}
+
+	data, err := k.marshalJSON(v)
+	if err != nil {
+		k.logger.Error("unable to encode response", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+func (k *KitchenServer) writeError(w http.ResponseWriter, status int, code ErrorCode, message string) {
+	if e, ok := w.(*envelopeWriter); ok {
+		e.writeError(status, code, message)
+		return
+	}
+
+	k.writeJSON(w, status, ErrorResponse{Code: code, Message: message})
+}
+
+func (k *KitchenServer) marshalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err == nil && k.stringIDs {
+		data, err = convertIDs(data, stringifyID)
+	}
+
+	return data, err
+}
+
+func decodeRequestBody(body io.Reader, v any) error {
+	if body == nil {
+		return errEmptyBody
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return errEmptyBody
+	}
+
+	data, err = convertIDs(data, numberifyID)
+	if err != nil {
+		return err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+
+	return d.Decode(v)
+}
+
+func convertIDs(data []byte, convert func(any) any) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var value any
+	err := d.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(walkIDs(value, convert))
+}
+
+func walkIDs(value any, convert func(any) any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if idFields[key] {
+				v[key] = convertID(field, convert)
+			} else {
+				v[key] = walkIDs(field, convert)
+			}
+		}
+	case []any:
+		for i, element := range v {
+			v[i] = walkIDs(element, convert)
+		}
+	}
+
+	return value
+}
+
+// convertID converts a single ID or each ID in a list of them. Anything else,
+// like the Lingering flag on a single ticket, is left as it is.
+func convertID(value any, convert func(any) any) any {
+	if ids, ok := value.([]any); ok {
+		for i, id := range ids {
+			ids[i] = convert(id)
+		}
+		return ids
+	}
+
+	return convert(value)
+}
+
+func stringifyID(value any) any {
+	if number, ok := value.(json.Number); ok {
+		return number.String()
+	}
+
+	return value
+}
+
+func numberifyID(value any) any {
+	if s, ok := value.(string); ok {
+		number := json.Number(s)
+		if _, err := number.Int64(); err == nil {
+			return number
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStringIDs(t *testing.T) {
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{{ID: 9007199254740993, Status: STATUS_PENDING, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}},
+		}
+	}
+
+	t.Run("serializes IDs as integers by default", func(t *testing.T) {
+		server := NewKitchenServer(newStore())
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(9007199254740993))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertJSONField(t, response.Body.Bytes(), "ID", json.Number("9007199254740993"))
+	})
+
+	t.Run("serializes IDs as strings when configured", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithStringIDs(true))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(9007199254740993))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertJSONField(t, response.Body.Bytes(), "ID", "9007199254740993")
+	})
+
+	t.Run("serializes created ticket ID as string when configured", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithStringIDs(true))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(Ticket{Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		assertJSONField(t, response.Body.Bytes(), "ID", "0")
+	})
+
+	t.Run("parses string and integer IDs on input", func(t *testing.T) {
+		for _, body := range []string{`{"ID":"5","Items":["burger"]}`, `{"ID":5,"Items":["burger"]}`} {
+			server := NewKitchenServer(&StubKitchenStore{})
+
+			request, _ := http.NewRequest(http.MethodPost, "/ticket/", bytes.NewBufferString(body))
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusAccepted)
+		}
+	})
+
+	t.Run("round-trips dependencies as strings when configured", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithStringIDs(true))
+
+		request, _ := http.NewRequest(http.MethodPost, "/ticket/", bytes.NewBufferString(`{"Items":["fries"],"DependsOn":["9007199254740993"]}`))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response.Code, http.StatusAccepted)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(store.tickets[len(store.tickets)-1].ID))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertJSONList(t, response.Body.Bytes(), "DependsOn", []any{"9007199254740993"})
+	})
+
+	t.Run("serializes lingering IDs as strings when configured", func(t *testing.T) {
+		clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+		server := NewKitchenServer(newStore(), WithStringIDs(true), WithClock(clock), WithCompletedLinger(time.Minute))
+		server.ServeHTTP(httptest.NewRecorder(), newCompleteTicketRequest(9007199254740993))
+
+		request, _ := http.NewRequest(http.MethodGet, "/ticket/active", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertJSONList(t, response.Body.Bytes(), "Lingering", []any{"9007199254740993"})
+	})
+}
+
+func assertJSONField(t testing.TB, data []byte, field string, want any) {
+	t.Helper()
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	object := map[string]any{}
+	err := d.Decode(&object)
+	if err != nil {
+		t.Fatalf("unable to parse response %q, %v", data, err)
+	}
+
+	if object[field] != want {
+		t.Errorf("got %s %#v, want %#v", field, object[field], want)
+	}
+}
+
+func assertJSONList(t testing.TB, data []byte, field string, want []any) {
+	t.Helper()
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	object := map[string]any{}
+	err := d.Decode(&object)
+	if err != nil {
+		t.Fatalf("unable to parse response %q, %v", data, err)
+	}
+
+	if !reflect.DeepEqual(object[field], want) {
+		t.Errorf("got %s %#v, want %#v", field, object[field], want)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	kafkaPublisherBuffer = 1024
+	kafkaDeliverTimeout  = 10 * time.Second
+)
+
+type KafkaPublisher struct {
+	writer *kafka.Writer
+	logger *slog.Logger
+	events chan TicketEvent
+	done   chan struct{}
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewKafkaPublisher(brokers []string, topic string, logger *slog.Logger) *KafkaPublisher {
+	k := &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		},
+		logger: logger,
+		events: make(chan TicketEvent, kafkaPublisherBuffer),
+		done:   make(chan struct{}),
+	}
+
+	go k.run()
+
+	return k
+}
+
+func (k *KafkaPublisher) Publish(event TicketEvent) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.closed {
+		k.logger.Warn("kafka publisher closed, dropping event", "type", event.Type, "ticket_id", event.TicketID)
+		return
+	}
+
+	select {
+	case k.events <- event:
+	default:
+		k.logger.Warn("kafka publisher buffer full, dropping event", "type", event.Type, "ticket_id", event.TicketID)
+	}
+}
+
+func (k *KafkaPublisher) Deliver(event TicketEvent) error {
+	message, err := kafkaMessage(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kafkaDeliverTimeout)
+	defer cancel()
+
+	return k.writer.WriteMessages(ctx, message)
+}
+
+// Close stops accepting events and waits for the buffered ones to be written
+// before closing the writer.
+func (k *KafkaPublisher) Close() error {
+	k.mu.Lock()
+	k.closed = true
+	close(k.events)
+	k.mu.Unlock()
+	<-k.done
+
+	return k.writer.Close()
+}
+
+func (k *KafkaPublisher) run() {
+	defer close(k.done)
+
+	for event := range k.events {
+		message, err := kafkaMessage(event)
+		if err != nil {
+			k.logger.Error("unable to encode ticket event", "error", err)
+			continue
+		}
+
+		err = k.writer.WriteMessages(context.Background(), message)
+		if err != nil {
+			k.logger.Error("unable to publish ticket event", "type", event.Type, "ticket_id", event.TicketID, "error", err)
+		}
+	}
+}
+
+func kafkaMessage(event TicketEvent) (kafka.Message, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	return kafka.Message{
+		Key:   []byte(strconv.Itoa(event.TicketID)),
+		Value: value,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKafkaMessage(t *testing.T) {
+	event := TicketEvent{
+		Type:       EVENT_CREATED,
+		TicketID:   42,
+		Status:     STATUS_PENDING,
+		OccurredAt: time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	message, err := kafkaMessage(event)
+	if err != nil {
+		t.Fatalf("unable to build kafka message, %v", err)
+	}
+
+	if string(message.Key) != "42" {
+		t.Errorf("got message key %q, want %q", message.Key, "42")
+	}
+
+	got := TicketEvent{}
+	json.Unmarshal(message.Value, &got)
+	if !reflect.DeepEqual(got, event) {
+		t.Errorf("got event %v, want %v", got, event)
+	}
+}
+
+func TestKafkaPublisherClose(t *testing.T) {
+	publisher := NewKafkaPublisher([]string{"127.0.0.1:0"}, "ticket-events", slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := publisher.Close(); err != nil {
+		t.Fatalf("unable to close publisher, %v", err)
+	}
+
+	publisher.Publish(TicketEvent{Type: EVENT_CREATED, TicketID: 1})
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+const defaultAcquireTimeout = 50 * time.Millisecond
+
+func (k *KitchenServer) limitConcurrency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isProbePath(r.URL.Path) || isStreamPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		acquire := time.NewTimer(k.acquireTimeout)
+		defer acquire.Stop()
+
+		select {
+		case k.inFlight <- struct{}{}:
+		case <-acquire.C:
+			k.logger.Warn("too many requests in flight, shedding request", "method", r.Method, "path", r.URL.Path)
+			k.setRetryAfter(w, time.Second)
+			k.writeError(w, http.StatusServiceUnavailable, CODE_OVERLOADED, "too many requests in flight, try again later")
+			return
+		case <-r.Context().Done():
+			return
+		}
+		defer func() { <-k.inFlight }()
+
+		next.ServeHTTP(w, r)
+	})
+}
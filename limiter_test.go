@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	t.Run("sheds requests beyond the limit while others proceed", func(t *testing.T) {
+		ticket := Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+		store := &BlockingKitchenStore{
+			KitchenStore: NewInMemoryKitchenStore(),
+			started:      make(chan struct{}),
+			release:      make(chan struct{}),
+		}
+		server := NewKitchenServer(store, WithConcurrencyLimit(2, 10*time.Millisecond))
+
+		var wg sync.WaitGroup
+		codes := make([]int, 2)
+		for i := range codes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newCreateTicketRequest(ticket))
+				codes[i] = response.Code
+			}()
+		}
+		<-store.started
+		<-store.started
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest(""))
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+		assertErrorCode(t, response, CODE_OVERLOADED)
+
+		response = httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, healthzPath, nil)
+		server.ServeHTTP(response, request)
+		assertStatus(t, response.Code, http.StatusOK)
+
+		close(store.release)
+		wg.Wait()
+		for _, code := range codes {
+			assertStatus(t, code, http.StatusAccepted)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest(""))
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+}
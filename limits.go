@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type Limits struct {
+	MaxItems          int
+	MaxItemNameLength int
+	MaxNoteLength     int
+	MaxStationLength  int
+	MaxOrderIDLength  int
+	MaxBatchSize      int
+	MaxPageLimit      int
+	MaxAttachmentSize int
+	StationMaxItems   map[string]int
+}
+
+var defaultLimits = Limits{
+	MaxItemNameLength: 100,
+	MaxNoteLength:     500,
+	MaxStationLength:  50,
+	MaxOrderIDLength:  100,
+	MaxBatchSize:      100,
+	MaxPageLimit:      maxPageLimit,
+	MaxAttachmentSize: 5 << 20,
+}
+
+func (l Limits) Validate() error {
+	if l.MaxItems < 0 || l.MaxItemNameLength < 0 || l.MaxNoteLength < 0 || l.MaxStationLength < 0 || l.MaxOrderIDLength < 0 {
+		return fmt.Errorf("limits can't be negative, got %+v", l)
+	}
+
+	for station, maxItems := range l.StationMaxItems {
+		if maxItems < 0 {
+			return fmt.Errorf("station %q max items can't be negative, got %d", station, maxItems)
+		}
+	}
+
+	if l.MaxBatchSize < 1 || l.MaxPageLimit < 1 || l.MaxAttachmentSize < 1 {
+		return fmt.Errorf("batch size, page limit and attachment size must be at least 1, got %d, %d and %d", l.MaxBatchSize, l.MaxPageLimit, l.MaxAttachmentSize)
+	}
+
+	return nil
+}
+
+func ParseStationMaxItems(pairs string) (map[string]int, error) {
+	maxItems := map[string]int{}
+	for _, pair := range strings.Split(pairs, ",") {
+		station, value, found := strings.Cut(pair, "=")
+		station = strings.TrimSpace(station)
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || station == "" || err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid station max items %q, want station=items with 0 or more items", pair)
+		}
+
+		maxItems[station] = limit
+	}
+
+	return maxItems, nil
+}
+
+func (l Limits) maxItems(station string) int {
+	if maxItems, ok := l.StationMaxItems[station]; ok {
+		return maxItems
+	}
+
+	return l.MaxItems
+}
+
+func (l Limits) check(ticket Ticket) error {
+	if maxItems := l.maxItems(ticket.Station); exceeds(len(ticket.Items), maxItems) {
+		return newValidationError(CODE_TOO_MANY_ITEMS, "ticket has %d items, at most %d are allowed", len(ticket.Items), maxItems)
+	}
+
+	if err := checkLength("Notes", ticket.Notes, l.MaxNoteLength); err != nil {
+		return err
+	}
+
+	if err := checkLength("Station", ticket.Station, l.MaxStationLength); err != nil {
+		return err
+	}
+
+	if err := checkLength("OrderID", ticket.OrderID, l.MaxOrderIDLength); err != nil {
+		return err
+	}
+
+	for _, item := range ticket.Items {
+		if err := checkLength("item Name", item.Name, l.MaxItemNameLength); err != nil {
+			return err
+		}
+
+		if err := checkLength("item StationOverride", item.StationOverride, l.MaxStationLength); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkLength(field, value string, limit int) error {
+	if exceeds(utf8.RuneCountInString(value), limit) {
+		return newValidationError(CODE_FIELD_TOO_LONG, "%s is longer than %d characters", field, limit)
+	}
+
+	return nil
+}
+
+func exceeds(value, limit int) bool {
+	return limit > 0 && value > limit
+}
+
+func (k *KitchenServer) getLimits(w http.ResponseWriter, r *http.Request) {
+	k.writeJSON(w, http.StatusOK, k.limits)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	limits := Limits{
+		MaxItems:          2,
+		MaxItemNameLength: 10,
+		MaxNoteLength:     20,
+		MaxStationLength:  5,
+		MaxOrderIDLength:  8,
+		MaxBatchSize:      3,
+		MaxPageLimit:      10,
+	}
+
+	tooLong := map[string]Ticket{
+		"items":       {Items: Items{{Name: "burger"}, {Name: "fries"}, {Name: "shake"}}},
+		"item name":   {Items: Items{{Name: strings.Repeat("a", 11)}}},
+		"notes":       {Items: Items{{Name: "burger"}}, Notes: strings.Repeat("a", 21)},
+		"station":     {Items: Items{{Name: "burger"}}, Station: "grills"},
+		"order ID":    {Items: Items{{Name: "burger"}}, OrderID: "order-123"},
+		"UTF-8 notes": {Items: Items{{Name: "burger"}}, Notes: strings.Repeat("ü", 21)},
+	}
+
+	for name, ticket := range tooLong {
+		t.Run("rejects too long "+name, func(t *testing.T) {
+			server := NewKitchenServer(&StubKitchenStore{}, WithLimits(limits))
+
+			for i := range ticket.Items {
+				ticket.Items[i].setDefaults()
+			}
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+			assertStatus(t, response.Code, http.StatusBadRequest)
+		})
+	}
+
+	t.Run("counts characters not bytes", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithLimits(limits))
+
+		ticket := Ticket{Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}, Notes: strings.Repeat("ü", 20)}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+	})
+
+	t.Run("rejects patches over the limits", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 0, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}}}
+		server := NewKitchenServer(store, WithLimits(limits))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(0, fmt.Sprintf(`{"Notes": %q}`, strings.Repeat("a", 21))))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("rejects batches over the limit", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithLimits(limits))
+
+		items := strings.Repeat(`{"Items": ["burger"]},`, limits.MaxBatchSize+1)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newBatchRequest("["+strings.TrimSuffix(items, ",")+"]"))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("rejects page limits over the limit", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithLimits(limits))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest(fmt.Sprintf("?limit=%d", limits.MaxPageLimit+1)))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("serves the configured limits", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithLimits(limits))
+
+		request, _ := http.NewRequest(http.MethodGet, "/limits", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var got Limits
+		err := json.NewDecoder(response.Body).Decode(&got)
+		if err != nil {
+			t.Fatalf("unable to parse response from server %q into Limits, %v", response.Body, err)
+		}
+
+		if !reflect.DeepEqual(got, limits) {
+			t.Errorf("got limits %+v, want %+v", got, limits)
+		}
+	})
+}
+
+func TestStationMaxItems(t *testing.T) {
+	limits := defaultLimits
+	limits.MaxItems = 3
+	limits.StationMaxItems = map[string]int{"grill": 2, "salad": 10}
+
+	newTicket := func(station string, count int) Ticket {
+		ticket := Ticket{Station: station}
+		for range count {
+			ticket.Items = append(ticket.Items, Item{Name: "burger", Quantity: 1, Unit: UNIT_EACH})
+		}
+		return ticket
+	}
+
+	cases := []struct {
+		name    string
+		station string
+		items   int
+		valid   bool
+	}{
+		{"grill rejects 3 items", "grill", 3, false},
+		{"salad accepts 3 items", "salad", 3, true},
+		{"salad accepts 10 items", "salad", 10, true},
+		{"salad rejects 11 items", "salad", 11, false},
+		{"other stations take the global limit", "bar", 4, false},
+		{"tickets without a station take the global limit", "", 3, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := isTicketValid(newTicket(c.station, c.items), limits)
+			if got != c.valid {
+				t.Errorf("got valid %v, want %v", got, c.valid)
+			}
+		})
+	}
+
+	t.Run("rejects creating a ticket over its station limit", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithLimits(limits))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(newTicket("grill", 3)))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_TOO_MANY_ITEMS)
+	})
+}
+
+func TestParseStationMaxItems(t *testing.T) {
+	t.Run("parses station limits", func(t *testing.T) {
+		got, err := ParseStationMaxItems("grill=2, salad=10")
+		if err != nil {
+			t.Fatalf("didn't expect an error but got one, %v", err)
+		}
+
+		want := map[string]int{"grill": 2, "salad": 10}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	for _, pairs := range []string{"grill", "=2", "grill=many", "grill=-1"} {
+		t.Run("rejects "+pairs, func(t *testing.T) {
+			if _, err := ParseStationMaxItems(pairs); err == nil {
+				t.Errorf("expected an error but didn't get one")
+			}
+		})
+	}
+}
+
+func TestLimitsValidate(t *testing.T) {
+	t.Run("accepts the defaults", func(t *testing.T) {
+		if err := defaultLimits.Validate(); err != nil {
+			t.Errorf("got error %v validating the default limits", err)
+		}
+	})
+
+	t.Run("rejects negative limits", func(t *testing.T) {
+		limits := defaultLimits
+		limits.MaxNoteLength = -1
+
+		if err := limits.Validate(); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("rejects a negative station limit", func(t *testing.T) {
+		limits := defaultLimits
+		limits.StationMaxItems = map[string]int{"grill": -1}
+
+		if err := limits.Validate(); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("rejects a zero page limit", func(t *testing.T) {
+		limits := defaultLimits
+		limits.MaxPageLimit = 0
+
+		if err := limits.Validate(); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package main
+
+import "time"
+
+func (k *KitchenServer) isLingering(ticket Ticket, now time.Time) bool {
+	if k.completedLinger <= 0 || ticket.Status != STATUS_COMPLETED {
+		return false
+	}
+
+	completedAt := ticket.UpdatedAt
+	if ticket.CompletedAt != nil {
+		completedAt = *ticket.CompletedAt
+	}
+
+	return now.Sub(completedAt) < k.completedLinger
+}
+
+func (k *KitchenServer) lingeringIDs(tickets []Ticket) []int {
+	now := k.clock.Now()
+
+	var ids []int
+	for _, ticket := range tickets {
+		if k.isLingering(ticket, now) {
+			ids = append(ids, ticket.ID)
+		}
+	}
+
+	return ids
+}
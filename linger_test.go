@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompletedLinger(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	linger := 10 * time.Second
+
+	newServer := func(clock *StubClock) *KitchenServer {
+		store := NewInMemoryKitchenStore()
+		for range 2 {
+			store.StoreTicket(Ticket{Status: STATUS_ACCEPTED, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		}
+
+		return NewKitchenServer(store, WithClock(clock), WithCompletedLinger(linger))
+	}
+
+	listActive := func(t testing.TB, server *KitchenServer) TicketPage {
+		t.Helper()
+
+		request, _ := http.NewRequest(http.MethodGet, "/ticket/active", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response.Code, http.StatusOK)
+
+		return getTicketPageFromResponse(t, response.Body)
+	}
+
+	ids := func(page TicketPage) []int {
+		ids := []int{}
+		for _, ticket := range page.Tickets {
+			ids = append(ids, ticket.ID)
+		}
+		return ids
+	}
+
+	t.Run("keeps a just completed ticket on the active view as lingering", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := newServer(clock)
+		server.ServeHTTP(httptest.NewRecorder(), newCompleteTicketRequest(1))
+
+		clock.Advance(linger - time.Nanosecond)
+		page := listActive(t, server)
+
+		if got, want := ids(page), []int{1, 2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got active IDs %v, want %v", got, want)
+		}
+		if want := []int{1}; !reflect.DeepEqual(page.Lingering, want) {
+			t.Errorf("got lingering IDs %v, want %v", page.Lingering, want)
+		}
+	})
+
+	t.Run("flags a lingering ticket on its own", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := newServer(clock)
+		server.ServeHTTP(httptest.NewRecorder(), newCompleteTicketRequest(1))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(1))
+
+		got := TicketResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+		if !got.Lingering {
+			t.Errorf("got ticket not lingering, want it lingering")
+		}
+	})
+
+	t.Run("drops a completed ticket once the window passes", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := newServer(clock)
+		server.ServeHTTP(httptest.NewRecorder(), newCompleteTicketRequest(1))
+
+		clock.Advance(linger)
+		page := listActive(t, server)
+
+		if got, want := ids(page), []int{2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got active IDs %v, want %v", got, want)
+		}
+		if len(page.Lingering) != 0 {
+			t.Errorf("got lingering IDs %v, want none", page.Lingering)
+		}
+	})
+
+	t.Run("drops completed tickets right away without a window", func(t *testing.T) {
+		clock := &StubClock{now}
+		store := NewInMemoryKitchenStore()
+		store.StoreTicket(Ticket{Status: STATUS_ACCEPTED, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		server := NewKitchenServer(store, WithClock(clock))
+		server.ServeHTTP(httptest.NewRecorder(), newCompleteTicketRequest(1))
+
+		if got := ids(listActive(t, server)); len(got) != 0 {
+			t.Errorf("got active IDs %v, want none", got)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type LogSink struct {
+	Target string
+	Format string
+	Level  slog.Level
+}
+
+func ParseLogSinks(specs string) ([]LogSink, error) {
+	sinks := []LogSink{}
+	for _, spec := range strings.Split(specs, ",") {
+		parts := strings.Split(strings.TrimSpace(spec), ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid log sink %q, want target:format:level", spec)
+		}
+
+		sink := LogSink{Target: parts[0], Format: parts[1]}
+		if sink.Format != "json" && sink.Format != "text" {
+			return nil, fmt.Errorf("unknown log format %q, want json or text", sink.Format)
+		}
+
+		if err := sink.Level.UnmarshalText([]byte(parts[2])); err != nil {
+			return nil, fmt.Errorf("unknown log level %q", parts[2])
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
+
+func OpenLogSinks(sinks []LogSink) (slog.Handler, error) {
+	handlers := []slog.Handler{}
+	for _, sink := range sinks {
+		w, err := openLogTarget(sink.Target)
+		if err != nil {
+			return nil, err
+		}
+
+		options := &slog.HandlerOptions{Level: sink.Level}
+		if sink.Format == "json" {
+			handlers = append(handlers, slog.NewJSONHandler(w, options))
+		} else {
+			handlers = append(handlers, slog.NewTextHandler(w, options))
+		}
+	}
+
+	return NewFanoutHandler(handlers...), nil
+}
+
+func openLogTarget(target string) (io.Writer, error) {
+	switch target {
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open log file %q, %v", target, err)
+	}
+
+	return file, nil
+}
+
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+func NewFanoutHandler(handlers ...slog.Handler) slog.Handler {
+	return &fanoutHandler{handlers: handlers}
+}
+
+func (f *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range f.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range f.handlers {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, handler := range f.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+
+	return &fanoutHandler{handlers: handlers}
+}
+
+func (f *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, handler := range f.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+
+	return &fanoutHandler{handlers: handlers}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFanoutHandler(t *testing.T) {
+	t.Run("sends each record to the sinks whose level it reaches", func(t *testing.T) {
+		everything := &bytes.Buffer{}
+		errorsOnly := &bytes.Buffer{}
+		logger := slog.New(NewFanoutHandler(
+			slog.NewJSONHandler(everything, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			slog.NewJSONHandler(errorsOnly, &slog.HandlerOptions{Level: slog.LevelError}),
+		))
+
+		logger.Debug("request", "path", "/ticket/")
+		logger.Error("unable to store ticket", "ticket_id", 1)
+
+		if !strings.Contains(errorsOnly.String(), "unable to store ticket") {
+			t.Errorf("error log didn't reach the error sink, got %q", errorsOnly.String())
+		}
+		if strings.Contains(errorsOnly.String(), "request") {
+			t.Errorf("debug log reached the error sink, got %q", errorsOnly.String())
+		}
+		if !strings.Contains(everything.String(), "request") || !strings.Contains(everything.String(), "unable to store ticket") {
+			t.Errorf("got %q, want both logs in the debug sink", everything.String())
+		}
+	})
+
+	t.Run("keeps attributes added to the logger", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		logger := slog.New(NewFanoutHandler(slog.NewTextHandler(buffer, nil))).With("kitchen", "london")
+
+		logger.Info("ticket created")
+
+		if !strings.Contains(buffer.String(), "kitchen=london") {
+			t.Errorf("got %q, want the kitchen attribute", buffer.String())
+		}
+	})
+}
+
+func TestParseLogSinks(t *testing.T) {
+	t.Run("parses sinks", func(t *testing.T) {
+		got, err := ParseLogSinks("stdout:json:info, errors.log:text:error")
+		if err != nil {
+			t.Fatalf("didn't expect an error but got one, %v", err)
+		}
+
+		want := []LogSink{
+			{Target: "stdout", Format: "json", Level: slog.LevelInfo},
+			{Target: "errors.log", Format: "text", Level: slog.LevelError},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	for _, specs := range []string{"stdout", "stdout:xml:info", "stdout:json:loud", ":json:info"} {
+		t.Run("rejects "+specs, func(t *testing.T) {
+			if _, err := ParseLogSinks(specs); err == nil {
+				t.Errorf("expected an error but didn't get one")
+			}
+		})
+	}
+}
@@ -1,23 +1,380 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
-type InMemoryKitchenStore struct{}
+func main() {
+	addr := flag.String("addr", ":5000", "address of the public ticket API")
+	admin := flag.Bool("admin", false, "enable the /admin endpoints")
+	adminAddr := flag.String("admin-addr", "127.0.0.1:5001", "internal address of the /admin endpoints")
+	kafkaBrokers := flag.String("kafka-brokers", "", "comma separated Kafka brokers to publish ticket events to")
+	kafkaTopic := flag.String("kafka-topic", "ticket-events", "Kafka topic for ticket events")
+	snapshotFile := flag.String("snapshot-file", "", "file to load the store from on startup and snapshot it to")
+	snapshotInterval := flag.Duration("snapshot-interval", time.Minute, "how often to snapshot the store")
+	kitchens := flag.String("kitchens", "", "comma separated kitchen IDs served under /{kitchenID}/ticket/")
+	slowThreshold := flag.Duration("slow-threshold", time.Second, "log a warning for requests slower than this")
+	requestTimeout := flag.Duration("request-timeout", 5*time.Second, "time budget for handling a request before responding 503")
+	longPollTimeout := flag.Duration("long-poll-timeout", 3*time.Second, "how long GET /ticket/next waits for a pending ticket")
+	duplicateItems := flag.String("duplicate-items", "allow", "how to handle repeated item names on a ticket: allow, reject or merge")
+	maxInFlight := flag.Int("max-in-flight", 0, "most requests handled at once before shedding with 503, 0 is unlimited")
+	acquireTimeout := flag.Duration("in-flight-acquire-timeout", defaultAcquireTimeout, "how long a request waits for a -max-in-flight slot")
+	writeQueueSize := flag.Int("write-queue-size", 0, "bound on ticket writes waiting for a worker, 0 writes directly")
+	writeWorkers := flag.Int("write-workers", 4, "number of workers draining the write queue")
+	redactNotes := flag.Bool("redact-notes", false, "mask phone numbers, emails and -redact-words in ticket notes")
+	redactWords := flag.String("redact-words", "", "comma separated words to mask in ticket notes")
+	auditRedactions := flag.Bool("audit-redactions", false, "keep the original notes of redacted tickets in their history")
+	eventSourced := flag.Bool("event-sourced", false, "keep an append-only change log the store can be rebuilt from")
+	stationRules := flag.String("station-rules", "", "comma separated HH:MM-HH:MM=station windows assigning a default station")
+	defaultStatus := flag.String("default-status", "pending", "status new tickets start in")
+	stationCapacities := flag.String("station-capacities", "", "comma separated station=tickets pairs capping how many accepted tickets a station works at once")
+	stations := flag.String("stations", "", "comma separated stations items may name as their StationOverride, empty allows any")
+	autoAcceptStations := flag.String("auto-accept-stations", "", "comma separated stations whose new tickets skip pending and start accepted")
+	requireIfMatch := flag.Bool("require-if-match-on-delete", false, "reject DELETE /ticket/{id} without an If-Match header")
+	clockSkew := flag.Duration("clock-skew", 5*time.Second, "how far in the past client supplied ScheduledFor and ExpiresAt, or in the future CompletedAt, may be")
+	itemAliases := flag.String("item-aliases", "", "comma separated alias=name pairs canonicalizing item names on new tickets")
+	minPrepMinutes := flag.String("min-prep-minutes", "", "comma separated item=minutes pairs no estimate for a ticket with that item goes below")
+	defaultMinPrepMinutes := flag.Int("default-min-prep-minutes", 0, "minimum prep minutes for items missing from -min-prep-minutes")
+	dedupWindow := flag.Duration("dedup-window", 0, "return the original ticket for identical creates from the same API key or X-Client-ID within this window, 0 disables")
+	sweepInterval := flag.Duration("sweep-interval", time.Minute, "how often to cancel expired tickets")
+	startSoonLead := flag.Duration("start-soon-lead", 0, "send a starting_soon event this long before a pre-order's ScheduledFor, 0 disables")
+	acceptSLA := flag.Duration("accept-sla", 0, "send an accept_sla_breached event when a ticket stays pending longer than this, 0 disables")
+	acceptSLAInterval := flag.Duration("accept-sla-interval", 10*time.Second, "how often to check for tickets breaching -accept-sla")
+	startSoonInterval := flag.Duration("start-soon-interval", 10*time.Second, "how often to check for pre-orders starting soon")
+	retryAttempts := flag.Int("retry-attempts", defaultRetryAttempts, "attempts for idempotent store writes that fail with a transient error, 1 disables retries")
+	retryDelay := flag.Duration("retry-delay", defaultRetryBaseDelay, "base delay of the jittered backoff between store write retries")
+	retryAfterHTTPDate := flag.Bool("retry-after-http-date", false, "send Retry-After as an HTTP-date instead of delta-seconds")
+	stringIDs := flag.Bool("string-ids", false, "serialize ticket IDs as JSON strings")
+	envelope := flag.Bool("envelope", false, "wrap every response body as {\"data\": ..., \"error\": ...}")
+	apiKeys := flag.String("api-keys", os.Getenv("KITCHEN_API_KEYS"), "comma separated key:role API keys required on every request, role is cook or manager and defaults to cook, empty disables auth (default $KITCHEN_API_KEYS)")
+	streamQueryToken := flag.Bool("stream-query-token", false, "accept the API key as ?token= on /ticket/stream")
+	streamOrigins := flag.String("stream-origins", "", "comma separated origins allowed to open /ticket/stream from a browser")
+	tlsCert := flag.String("tls-cert", "", "certificate file, serves HTTPS and HTTP/2 together with -tls-key")
+	tlsKey := flag.String("tls-key", "", "private key file for -tls-cert")
+	cleartextHTTP2 := flag.Bool("h2c", false, "accept cleartext HTTP/2 (h2c) for use behind a TLS terminating proxy")
+	cacheSize := flag.Int("cache-size", 0, "number of tickets to cache in front of the store, 0 disables the cache")
+	cacheTTL := flag.Duration("cache-ttl", 5*time.Second, "how long a cached ticket is served before it is read again")
+	logSinks := flag.String("log-sinks", "", "comma separated target:format:level log sinks, target is stdout, stderr or a file, format json or text, e.g. stdout:json:info,errors.log:json:error")
+	features := flag.String("features", "", "comma separated feature=true|false pairs gating stream, batch, estimate and templates, all enabled by default")
+	taxRate := flag.String("tax-rate", "0", "tax percentage added to ticket subtotals, e.g. 8.875")
+	taxCategories := flag.String("tax-categories", "", "comma separated category=percentage tax rates for items with a TaxCategory, e.g. food=8.875,drink=5")
+	warmUpTimeout := flag.Duration("warm-up-timeout", defaultWarmUpTimeout, "how long to wait for the store to become healthy before giving up at startup")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second, "how long to wait for in-flight requests on SIGINT or SIGTERM before exiting")
+	removeItemsAtZero := flag.Bool("remove-items-at-zero", false, "remove an item decremented from a quantity of one instead of keeping it")
+	logBodies := flag.Bool("log-bodies", false, "log ticket request and response bodies at debug level, may include customer details so only enable while debugging")
+	bodyLogMaxBytes := flag.Int("log-body-max-bytes", defaultBodyLogMaxBytes, "most bytes of each body -log-bodies logs")
+	bodyLogRedactFields := flag.String("log-body-redact-fields", "Notes", "comma separated JSON fields -log-bodies masks")
+	completedLingerSeconds := flag.Int("completed-linger-seconds", 0, "seconds a completed ticket stays on the active view, flagged as Lingering")
+	staleAfter := flag.Duration("stale-after", 0, "flag active tickets created longer ago than this as Stale, 0 disables")
+	outbox := flag.Bool("outbox", false, "queue published events in the store and relay them with retries, so they survive a publisher outage or, with -snapshot-file, a restart")
+	outboxInterval := flag.Duration("outbox-interval", time.Second, "how often the outbox is relayed")
+	timezone := flag.String("timezone", "", "IANA timezone business days and station rules are counted in, e.g. Europe/London, defaults to the server's")
+	defaultVersion := flag.String("default-version", "", "response shape, v1 or v2, for clients without an Accept-Version header, empty keeps the unversioned shape")
+	idFormat := IDFormat{}
+	flag.StringVar(&idFormat.Prefix, "display-id-prefix", "", "prefix of the DisplayID printed tickets show, e.g. LON-")
+	flag.IntVar(&idFormat.Width, "display-id-width", 0, "zero padded width of the number in DisplayID")
+	limits := defaultLimits
+	flag.IntVar(&limits.MaxItems, "max-items", limits.MaxItems, "most items allowed on a ticket, 0 is unlimited")
+	flag.IntVar(&limits.MaxItemNameLength, "max-item-name-length", limits.MaxItemNameLength, "most characters allowed in an item name, 0 is unlimited")
+	flag.IntVar(&limits.MaxNoteLength, "max-note-length", limits.MaxNoteLength, "most characters allowed in ticket notes, 0 is unlimited")
+	flag.IntVar(&limits.MaxStationLength, "max-station-length", limits.MaxStationLength, "most characters allowed in a station name, 0 is unlimited")
+	flag.IntVar(&limits.MaxOrderIDLength, "max-order-id-length", limits.MaxOrderIDLength, "most characters allowed in an order ID, 0 is unlimited")
+	flag.IntVar(&limits.MaxBatchSize, "max-batch-size", limits.MaxBatchSize, "most tickets accepted by POST /ticket/batch")
+	flag.IntVar(&limits.MaxPageLimit, "max-page-limit", limits.MaxPageLimit, "largest ?limit= accepted when listing tickets")
+	flag.IntVar(&limits.MaxAttachmentSize, "max-attachment-size", limits.MaxAttachmentSize, "most bytes accepted for a ticket attachment")
+	stationMaxItems := flag.String("station-max-items", "", "comma separated station=items pairs overriding -max-items for tickets at that station, 0 is unlimited")
+	attachmentsDir := flag.String("attachments-dir", "attachments", "directory ticket attachments are stored in")
+	chaosOptions := chaosFlags()
+	flag.Parse()
 
-func (i *InMemoryKitchenStore) GetTicketByID(ticketId int) (Ticket, error) {
-	return Ticket{}, nil
-}
+	if *logSinks != "" {
+		sinks, err := ParseLogSinks(*logSinks)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-func (i *InMemoryKitchenStore) StoreTicket(Ticket) (int, error) {
-	return 123, nil
-}
+		handler, err := OpenLogSinks(sinks)
+		if err != nil {
+			log.Fatal(err)
+		}
+		slog.SetDefault(slog.New(handler))
+	}
 
-func main() {
-	store := &InMemoryKitchenStore{}
-	server := &KitchenServer{store}
+	if *stationMaxItems != "" {
+		stationLimits, err := ParseStationMaxItems(*stationMaxItems)
+		if err != nil {
+			log.Fatal(err)
+		}
+		limits.StationMaxItems = stationLimits
+	}
+
+	if err := limits.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ValidateLongPollTimeout(*longPollTimeout, *requestTimeout); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := idFormat.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ValidateVersion(*defaultVersion); err != nil {
+		log.Fatal(err)
+	}
+
+	duplicates, err := ParseDuplicateItems(*duplicateItems)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	options := []Option{
+		WithAdmin(*admin),
+		WithEnvelope(*envelope),
+		WithRetry(*retryAttempts, *retryDelay),
+		WithRetryAfterHTTPDate(*retryAfterHTTPDate),
+		WithStringIDs(*stringIDs),
+		WithRequestTimeout(*requestTimeout),
+		WithSlowThreshold(*slowThreshold),
+		WithLongPollTimeout(*longPollTimeout),
+		WithDuplicateItems(duplicates),
+		WithLimits(limits),
+		WithClockSkew(*clockSkew),
+		WithBlobStore(NewFileBlobStore(*attachmentsDir)),
+		WithRequireIfMatchOnDelete(*requireIfMatch),
+		WithDedupWindow(*dedupWindow),
+		WithIDFormat(idFormat),
+		WithDefaultVersion(*defaultVersion),
+		WithRemoveItemsAtZero(*removeItemsAtZero),
+		WithStartSoonLeadTime(*startSoonLead),
+		WithAcceptSLA(*acceptSLA),
+		WithStaleAfter(*staleAfter),
+		WithCompletedLinger(time.Duration(*completedLingerSeconds) * time.Second),
+		WithOutbox(*outbox),
+	}
+
+	options = append(options, chaosOptions()...)
+
+	if *timezone != "" {
+		location, err := time.LoadLocation(*timezone)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, WithTimezone(location))
+	}
+
+	if *logBodies {
+		bodyLogging := BodyLogging{MaxBytes: *bodyLogMaxBytes}
+		if *bodyLogRedactFields != "" {
+			bodyLogging.RedactFields = strings.Split(*bodyLogRedactFields, ",")
+		}
+		if err := bodyLogging.Validate(); err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, WithBodyLogging(bodyLogging))
+	}
+
+	if *maxInFlight > 0 {
+		options = append(options, WithConcurrencyLimit(*maxInFlight, *acquireTimeout))
+	}
+
+	if err := ValidateWriteQueue(*writeQueueSize, *writeWorkers); err != nil {
+		log.Fatal(err)
+	}
+	if *writeQueueSize > 0 {
+		options = append(options, WithWriteQueue(*writeQueueSize, *writeWorkers))
+	}
+
+	if *redactNotes {
+		var words []string
+		if *redactWords != "" {
+			words = strings.Split(*redactWords, ",")
+		}
+		options = append(options, WithNoteRedaction(words...), WithRedactionAudit(*auditRedactions))
+	}
+
+	if *stationRules != "" {
+		rules, err := ParseStationRules(*stationRules)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, WithStationRules(rules...))
+	}
+
+	status, err := ParseStatus(*defaultStatus)
+	if err != nil {
+		log.Fatal(err)
+	}
+	options = append(options, WithDefaultStatus(status))
+
+	if *autoAcceptStations != "" {
+		stations := strings.Split(*autoAcceptStations, ",")
+		if err := ValidateAutoAcceptStations(status, stations); err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, WithAutoAcceptStations(stations...))
+	}
+
+	if *stations != "" {
+		options = append(options, WithStations(strings.Split(*stations, ",")...))
+	}
+
+	if *itemAliases != "" {
+		aliases, err := ParseItemAliases(*itemAliases)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, WithItemAliases(aliases))
+	}
+
+	rate, err := ParseTaxRate(*taxRate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	options = append(options, WithTaxRate(rate))
+
+	if *taxCategories != "" {
+		categories, err := ParseTaxCategories(*taxCategories)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, WithTaxCategories(categories))
+	}
+
+	if *stationCapacities != "" {
+		capacities, err := ParseStationCapacities(*stationCapacities)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, WithStationCapacities(capacities))
+	}
+
+	if *features != "" {
+		flags, err := ParseFeatureFlags(*features)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, WithFeatureFlags(flags))
+	}
+
+	minPrepTimes := map[string]time.Duration{}
+	if *minPrepMinutes != "" {
+		minPrepTimes, err = ParseMinPrepTimes(*minPrepMinutes)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	options = append(options, WithMinPrepTimes(minPrepTimes, time.Duration(*defaultMinPrepMinutes)*time.Minute))
+
+	keys, err := ParseAPIKeys(*apiKeys)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(keys) > 0 {
+		options = append(options, WithAPIKeys(keys...), WithStreamQueryToken(*streamQueryToken))
+	}
+
+	if *streamOrigins != "" {
+		options = append(options, WithStreamOrigins(strings.Split(*streamOrigins, ",")...))
+	}
+
+	if *kitchens != "" {
+		options = append(options, WithKitchens(strings.Split(*kitchens, ",")...))
+	}
+
+	var publisher *KafkaPublisher
+	if *kafkaBrokers != "" {
+		publisher = NewKafkaPublisher(strings.Split(*kafkaBrokers, ","), *kafkaTopic, slog.Default())
+		options = append(options, WithPublisher(publisher))
+	}
+
+	store := NewInMemoryKitchenStore()
+	if *eventSourced {
+		store = NewEventSourcedKitchenStore()
+	}
+	if *snapshotFile != "" {
+		if err := store.RestoreFromFile(*snapshotFile); err != nil {
+			log.Fatal(err)
+		}
+		store.SnapshotEvery(*snapshotFile, *snapshotInterval, slog.Default())
+	}
+	var kitchenStore KitchenStore = store
+	if *cacheSize > 0 {
+		kitchenStore = NewCachingKitchenStore(store, *cacheSize, *cacheTTL)
+	}
+	server := NewKitchenServer(kitchenStore, options...)
+	stops := []func(){server.SweepExpiredEvery(*sweepInterval)}
+	if *outbox {
+		stops = append(stops, server.RelayOutboxEvery(*outboxInterval))
+	}
+	if *startSoonLead > 0 {
+		stops = append(stops, server.NotifyStartingSoonEvery(*startSoonInterval))
+	}
+	if *acceptSLA > 0 {
+		stops = append(stops, server.CheckAcceptSLAEvery(*acceptSLAInterval))
+	}
+	go func() {
+		if err := server.WarmUp(context.Background(), *warmUpTimeout); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	public := newPublicServer(*addr, server, *cleartextHTTP2)
+	httpServers := []*http.Server{public}
+	go func() {
+		var err error
+		if *tlsCert != "" {
+			err = public.ListenAndServeTLS(*tlsCert, *tlsKey)
+		} else {
+			err = public.ListenAndServe()
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	if *admin {
+		internal := &http.Server{Addr: *adminAddr, Handler: server.AdminHandler()}
+		httpServers = append(httpServers, internal)
+		go func() {
+			if err := internal.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	slog.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	for _, httpServer := range httpServers {
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("unable to drain in-flight requests", "addr", httpServer.Addr, "error", err)
+		}
+	}
+
+	for _, stop := range stops {
+		stop()
+	}
 
-	log.Fatal(http.ListenAndServe(":5000", server))
+	if publisher != nil {
+		if err := publisher.Close(); err != nil {
+			slog.Error("unable to flush kafka publisher", "error", err)
+		}
+	}
 }
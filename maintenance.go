@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+const defaultMaintenanceMessage = "the kitchen is paused for maintenance, try again later"
+
+type Maintenance struct {
+	Enabled bool
+	Message string
+}
+
+func (k *KitchenServer) rejectDuringMaintenance(w http.ResponseWriter, r *http.Request) bool {
+	if isSafeMethod(r.Method) && !isNextPath(r.URL.Path) {
+		return false
+	}
+
+	maintenance, err := k.store.GetMaintenance()
+	if err != nil {
+		k.logger.Error("unable to read maintenance mode", "error", err)
+		return false
+	}
+
+	if !maintenance.Enabled {
+		return false
+	}
+
+	message := maintenance.Message
+	if message == "" {
+		message = defaultMaintenanceMessage
+	}
+	k.writeError(w, http.StatusServiceUnavailable, CODE_MAINTENANCE, message)
+	return true
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// isNextPath reports whether path is GET /ticket/next, which claims the
+// ticket it returns and so is a write despite the method.
+func isNextPath(path string) bool {
+	return strings.HasSuffix(path, "/ticket/next")
+}
+
+func (k *KitchenServer) serveMaintenance(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		maintenance, err := k.store.GetMaintenance()
+		if err != nil {
+			k.logger.Error("unable to read maintenance mode", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		k.writeJSON(w, http.StatusOK, maintenance)
+	case http.MethodPost:
+		if !k.claimNonce(w, r) {
+			return
+		}
+
+		maintenance := Maintenance{}
+		err := decodeRequestBody(r.Body, &maintenance)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = k.store.SetMaintenance(maintenance)
+		if err != nil {
+			k.logger.Error("unable to set maintenance mode", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		k.logger.Info("maintenance mode changed", "enabled", maintenance.Enabled, "message", maintenance.Message)
+		k.writeJSON(w, http.StatusOK, maintenance)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
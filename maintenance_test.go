@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	ticket := Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+
+	t.Run("rejects writes but serves reads while enabled", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		store.StoreTicket(Ticket{Station: "grill", Items: ticket.Items})
+		server := NewKitchenServer(store, WithAdmin(true))
+
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, newSetMaintenanceRequest(Maintenance{Enabled: true, Message: "fire alarm"}))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+		assertErrorResponse(t, response, "fire alarm")
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(1))
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newNextTicketRequest("grill"))
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+		if got, _ := store.GetTicketByID(1); got.Status != STATUS_PENDING {
+			t.Errorf("got ticket status %v, want %v", got.Status, STATUS_PENDING)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(1))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest(""))
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("accepts writes again once disabled", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store, WithAdmin(true))
+
+		server.AdminHandler().ServeHTTP(httptest.NewRecorder(), newSetMaintenanceRequest(Maintenance{Enabled: true}))
+		server.AdminHandler().ServeHTTP(httptest.NewRecorder(), newSetMaintenanceRequest(Maintenance{Enabled: false}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+		assertStatus(t, response.Code, http.StatusAccepted)
+	})
+
+	t.Run("reports the current state", func(t *testing.T) {
+		server := NewKitchenServer(NewInMemoryKitchenStore(), WithAdmin(true))
+		want := Maintenance{Enabled: true, Message: "fire alarm"}
+		server.AdminHandler().ServeHTTP(httptest.NewRecorder(), newSetMaintenanceRequest(want))
+
+		request, _ := http.NewRequest(http.MethodGet, "/admin/maintenance", nil)
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		got := Maintenance{}
+		json.NewDecoder(response.Body).Decode(&got)
+		if got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("falls back to a default message", func(t *testing.T) {
+		server := NewKitchenServer(NewInMemoryKitchenStore(), WithAdmin(true))
+		server.AdminHandler().ServeHTTP(httptest.NewRecorder(), newSetMaintenanceRequest(Maintenance{Enabled: true}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+		assertErrorResponse(t, response, defaultMaintenanceMessage)
+	})
+
+	t.Run("survives a restart from a snapshot", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		NewKitchenServer(store, WithAdmin(true)).AdminHandler().ServeHTTP(httptest.NewRecorder(), newSetMaintenanceRequest(Maintenance{Enabled: true}))
+
+		snapshot := &bytes.Buffer{}
+		if err := store.Snapshot(snapshot); err != nil {
+			t.Fatalf("unable to snapshot store, %v", err)
+		}
+		restored := NewInMemoryKitchenStore()
+		if err := restored.Restore(snapshot); err != nil {
+			t.Fatalf("unable to restore store, %v", err)
+		}
+
+		response := httptest.NewRecorder()
+		NewKitchenServer(restored).ServeHTTP(response, newCreateTicketRequest(ticket))
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+	})
+}
+
+func newSetMaintenanceRequest(maintenance Maintenance) *http.Request {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(maintenance)
+
+	req, _ := http.NewRequest(http.MethodPost, "/admin/maintenance", buffer)
+	req.Header.Set(nonceHeader, newNonce())
+	return req
+}
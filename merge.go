@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type MergeRequest struct {
+	TicketID int
+}
+
+func (k *KitchenServer) mergeTicket(w http.ResponseWriter, r *http.Request, ticketID int) {
+	request := MergeRequest{}
+	err := decodeRequestBody(r.Body, &request)
+	if err != nil || request.TicketID == ticketID {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	store := k.storeFor(r)
+	target, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	source, err := store.GetTicketByID(request.TicketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !containsStatus(activeStatuses, target.Status) || !containsStatus(activeStatuses, source.Status) {
+		k.writeError(w, http.StatusConflict, CODE_INVALID_TRANSITION, fmt.Sprintf("can't merge %v ticket into %v ticket", source.Status, target.Status))
+		return
+	}
+
+	merged := target
+	merged.Items, err = k.handleDuplicateItems(append(append(Items{}, target.Items...), source.Items...))
+	if err == nil {
+		err = k.checkTicket(merged)
+	}
+	if err != nil {
+		k.writeValidationError(w, err)
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, merged) || !k.authorize(w, r, ACTION_UPDATE, source) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	merged.UpdatedAt = now
+	err = k.updateTicket(store, merged, TicketEvent{
+		Type:       EVENT_MERGED,
+		TicketID:   ticketID,
+		Status:     merged.Status,
+		Reason:     "merged ticket " + strconv.Itoa(source.ID),
+		OccurredAt: now,
+	})
+	if err != nil {
+		k.logger.Error("unable to merge ticket", "ticket_id", ticketID, "source_id", source.ID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	source.Status = STATUS_MERGED
+	source.UpdatedAt = now
+	err = k.updateTicket(store, source, TicketEvent{
+		Type:       EVENT_MERGED,
+		TicketID:   source.ID,
+		Status:     source.Status,
+		Reason:     "merged into ticket " + strconv.Itoa(ticketID),
+		OccurredAt: now,
+	})
+	if err != nil {
+		k.logger.Error("unable to mark ticket merged", "ticket_id", source.ID, "target_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(merged))
+}
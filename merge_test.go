@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMergeTicket(t *testing.T) {
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_ACCEPTED, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}},
+				{ID: 2, Status: STATUS_PENDING, Items: []Item{{Name: "burger", Quantity: 2, Unit: UNIT_EACH}, {Name: "fries", Quantity: 1, Unit: UNIT_EACH}}},
+				{ID: 3, Status: STATUS_COMPLETED, Items: []Item{{Name: "shake", Quantity: 1, Unit: UNIT_EACH}}},
+				{ID: 4, Status: STATUS_CANCELLED, Items: []Item{{Name: "pizza", Quantity: 1, Unit: UNIT_EACH}}},
+			},
+		}
+	}
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+
+	t.Run("combines items and marks the source merged", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock), WithDuplicateItems(DUPLICATES_MERGE))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergeTicketRequest(1, MergeRequest{TicketID: 2}))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		target, _ := store.GetTicketByID(1)
+		assertItems(t, target.Items, Items{
+			{Name: "burger", Quantity: 3, Unit: UNIT_EACH},
+			{Name: "fries", Quantity: 1, Unit: UNIT_EACH},
+		})
+		assertTime(t, target.UpdatedAt, clock.now)
+
+		source, _ := store.GetTicketByID(2)
+		if source.Status != STATUS_MERGED {
+			t.Errorf("got source status %v, want %v", source.Status, STATUS_MERGED)
+		}
+
+		assertEvents(t, store.events, []TicketEvent{
+			{Type: EVENT_MERGED, TicketID: 1, Status: STATUS_ACCEPTED, Reason: "merged ticket 2", OccurredAt: clock.now},
+			{Type: EVENT_MERGED, TicketID: 2, Status: STATUS_MERGED, Reason: "merged into ticket 1", OccurredAt: clock.now},
+		})
+	})
+
+	t.Run("returns Unprocessable Entity when duplicates are rejected", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock), WithDuplicateItems(DUPLICATES_REJECT))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergeTicketRequest(1, MergeRequest{TicketID: 2}))
+
+		assertStatus(t, response.Code, http.StatusUnprocessableEntity)
+	})
+
+	for _, ids := range [][2]int{{1, 3}, {3, 1}, {1, 4}, {4, 1}} {
+		t.Run(fmt.Sprintf("returns Conflict merging ticket %d into %d", ids[1], ids[0]), func(t *testing.T) {
+			store := newStore()
+			server := NewKitchenServer(store, WithClock(clock))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newMergeTicketRequest(ids[0], MergeRequest{TicketID: ids[1]}))
+
+			assertStatus(t, response.Code, http.StatusConflict)
+			if len(store.events) != 0 {
+				t.Errorf("got events %v, want none recorded", store.events)
+			}
+		})
+	}
+
+	t.Run("returns Bad Request merging a ticket into itself", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergeTicketRequest(1, MergeRequest{TicketID: 1}))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("returns Not Found on nonexistant source ticket", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergeTicketRequest(1, MergeRequest{TicketID: 5}))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func newMergeTicketRequest(ticketID int, merge MergeRequest) *http.Request {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(merge)
+
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/%d/merge", ticketID), buffer)
+	return req
+}
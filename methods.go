@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+func routeMethods(path string) []string {
+	switch path {
+	case "/limits":
+		return []string{http.MethodGet, http.MethodHead}
+	case "/template":
+		return []string{http.MethodPost}
+	case "/ticket/":
+		return []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	case "/ticket/active", "/ticket/completed", "/ticket/stale", "/ticket/stream":
+		return []string{http.MethodGet, http.MethodHead}
+	case "/ticket/next":
+		return []string{http.MethodGet}
+	case "/ticket/estimate", "/ticket/batch":
+		return []string{http.MethodPost}
+	}
+
+	if strings.HasPrefix(path, "/template/") {
+		return []string{http.MethodGet, http.MethodHead, http.MethodDelete}
+	}
+
+	rest, ok := strings.CutPrefix(path, "/ticket/")
+	if !ok {
+		return nil
+	}
+
+	_, action, found := strings.Cut(rest, "/")
+	switch {
+	case !found:
+		return []string{http.MethodGet, http.MethodHead, http.MethodPatch, http.MethodDelete}
+	case action == "history":
+		return []string{http.MethodGet, http.MethodHead}
+	case strings.HasPrefix(action, "attachments/"):
+		return []string{http.MethodGet, http.MethodHead}
+	}
+
+	return []string{http.MethodPost}
+}
+
+func (k *KitchenServer) rejectMethod(w http.ResponseWriter, r *http.Request) bool {
+	allowed := routeMethods(r.URL.Path)
+	if allowed == nil || slices.Contains(allowed, r.Method) {
+		return false
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	return true
+}
+
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (h headWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (h headWriter) Unwrap() http.ResponseWriter {
+	return h.ResponseWriter
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeadRequests(t *testing.T) {
+	newServer := func() *KitchenServer {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 1, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}}}
+		store.StoreTemplate(Template{Name: "combo", Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		return NewKitchenServer(store)
+	}
+
+	serve := func(t testing.TB, server *KitchenServer, method, path string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		request, _ := http.NewRequest(method, path, nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response
+	}
+
+	for _, path := range []string{"/ticket/1", "/ticket/active", "/ticket/completed", "/ticket/1/history", "/limits", "/template/combo"} {
+		t.Run("answers HEAD like GET on "+path, func(t *testing.T) {
+			server := newServer()
+			get := serve(t, server, http.MethodGet, path)
+			head := serve(t, server, http.MethodHead, path)
+
+			assertStatus(t, head.Code, get.Code)
+			if head.Body.Len() != 0 {
+				t.Errorf("got body %q, want none", head.Body.String())
+			}
+			for _, header := range []string{"Content-Type", "ETag"} {
+				assertHeader(t, head, header, get.Header().Get(header))
+			}
+		})
+	}
+
+	t.Run("answers HEAD on the event stream without streaming", func(t *testing.T) {
+		response := serve(t, newServer(), http.MethodHead, "/ticket/stream")
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertHeader(t, response, "Content-Type", "text/event-stream")
+	})
+
+	t.Run("keeps counting tickets on HEAD of the list", func(t *testing.T) {
+		response := serve(t, newServer(), http.MethodHead, "/ticket/")
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertHeader(t, response, "X-Total-Count", "1")
+	})
+
+	cases := []struct {
+		method string
+		path   string
+		allow  string
+	}{
+		{http.MethodHead, "/ticket/batch", "POST"},
+		{http.MethodHead, "/ticket/1/accept", "POST"},
+		{http.MethodHead, "/ticket/next", "GET"},
+		{http.MethodGet, "/ticket/estimate", "POST"},
+		{http.MethodHead, "/template", "POST"},
+		{http.MethodPut, "/ticket/1", "GET, HEAD, PATCH, DELETE"},
+		{http.MethodPost, "/ticket/active", "GET, HEAD"},
+		{http.MethodPatch, "/ticket/stale", "GET, HEAD"},
+	}
+
+	for _, c := range cases {
+		t.Run("returns Method Not Allowed for "+c.method+" "+c.path, func(t *testing.T) {
+			response := serve(t, newServer(), c.method, c.path)
+
+			assertStatus(t, response.Code, http.StatusMethodNotAllowed)
+			assertHeader(t, response, "Allow", c.allow)
+		})
+	}
+}
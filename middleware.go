@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSlowThreshold = time.Second
+	requestIDHeader      = "X-Request-ID"
+)
+
+type requestIDKey struct{}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status   int
+	writeErr error
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(data []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(data)
+	if err != nil && s.writeErr == nil {
+		s.writeErr = err
+	}
+
+	return n, err
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+func (k *KitchenServer) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, requestID)))
+
+		duration := time.Since(start)
+		attrs := []any{"request_id", requestID, "method", r.Method, "path", r.URL.Path, "status", recorder.status, "duration", duration}
+		if ticketID, ok := ticketIDFromPath(r.URL.Path); ok {
+			attrs = append(attrs, "ticket_id", ticketID)
+		}
+
+		if recorder.writeErr != nil {
+			k.logger.Error("unable to write response", append(attrs, "error", recorder.writeErr)...)
+			return
+		}
+
+		if k.slowThreshold > 0 && duration >= k.slowThreshold {
+			k.logger.Warn("slow request", attrs...)
+			return
+		}
+		k.logger.Debug("request", attrs...)
+	})
+}
+
+func (k *KitchenServer) timeoutRequests(next http.Handler) http.Handler {
+	timed := http.TimeoutHandler(next, k.requestTimeout, requestTimeoutMessage)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isStreamingRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		timed.ServeHTTP(w, r)
+	})
+}
+
+// isStreamingRequest reports whether r is answered incrementally, which rules
+// out middleware that buffers the whole response before writing it.
+func isStreamingRequest(r *http.Request) bool {
+	query := r.URL.Query()
+	return isStreamPath(r.URL.Path) || query.Get("stream") == "true" || query.Get("format") == "ndjson"
+}
+
+func ticketIDFromPath(path string) (int, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments[:len(segments)-1] {
+		if segment == "ticket" {
+			ticketID, err := strconv.Atoi(segments[i+1])
+			return ticketID, err == nil
+		}
+	}
+
+	return 0, false
+}
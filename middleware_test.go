@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowRequestWarning(t *testing.T) {
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{{ID: 7, Status: STATUS_PENDING}},
+		}
+	}
+
+	t.Run("warns with method, path, duration and ticket ID on slow request", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		logger := slog.New(slog.NewTextHandler(buffer, nil))
+		store := &SlowKitchenStore{newStore(), 20 * time.Millisecond}
+		server := NewKitchenServer(store, WithLogger(logger), WithSlowThreshold(10*time.Millisecond))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(7))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := buffer.String()
+		for _, want := range []string{"level=WARN", "slow request", "method=GET", "path=/ticket/7", "duration=", "ticket_id=7"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected log %q to contain %q", got, want)
+			}
+		}
+	})
+
+	t.Run("doesn't warn on fast request", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		logger := slog.New(slog.NewTextHandler(buffer, nil))
+		server := NewKitchenServer(newStore(), WithLogger(logger), WithSlowThreshold(time.Second))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(7))
+
+		if strings.Contains(buffer.String(), "slow request") {
+			t.Errorf("got slow request warning %q for fast request", buffer.String())
+		}
+	})
+}
+
+type FailingResponseWriter struct {
+	*httptest.ResponseRecorder
+	remaining int
+}
+
+func (f *FailingResponseWriter) Write(data []byte) (int, error) {
+	if len(data) > f.remaining {
+		n, _ := f.ResponseRecorder.Write(data[:f.remaining])
+		f.remaining = 0
+		return n, errors.New("connection reset by peer")
+	}
+
+	f.remaining -= len(data)
+	return f.ResponseRecorder.Write(data)
+}
+
+func TestResponseWriteErrors(t *testing.T) {
+	store := &StubKitchenStore{tickets: []Ticket{{ID: 7, Status: STATUS_PENDING, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}}}
+
+	t.Run("logs a response cut off partway with the request ID", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		server := NewKitchenServer(store, WithLogger(slog.New(slog.NewTextHandler(buffer, nil))))
+
+		request := newGetTicketRequest(7)
+		request.Header.Set(requestIDHeader, "req-42")
+		response := &FailingResponseWriter{ResponseRecorder: httptest.NewRecorder(), remaining: 10}
+		server.ServeHTTP(response, request)
+
+		got := buffer.String()
+		for _, want := range []string{"level=ERROR", "unable to write response", "request_id=req-42", "path=/ticket/7", "connection reset by peer"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected log %q to contain %q", got, want)
+			}
+		}
+	})
+
+	t.Run("doesn't log a complete response as an error", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		server := NewKitchenServer(store, WithLogger(slog.New(slog.NewTextHandler(buffer, nil))))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(7))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if strings.Contains(buffer.String(), "level=ERROR") {
+			t.Errorf("got error log %q for a complete response", buffer.String())
+		}
+	})
+
+	t.Run("generates a request ID when none is sent", func(t *testing.T) {
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(7))
+
+		if response.Header().Get(requestIDHeader) == "" {
+			t.Errorf("expected a %s header but didn't get one", requestIDHeader)
+		}
+	})
+}
+
+func TestStreamTicketsErrors(t *testing.T) {
+	t.Run("responds with a clean error when nothing was written yet", func(t *testing.T) {
+		server := NewKitchenServer(&FailingKitchenStore{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?stream=true"))
+
+		assertStatus(t, response.Code, http.StatusInternalServerError)
+		if response.Body.Len() != 0 {
+			t.Errorf("got body %q, want none", response.Body.String())
+		}
+	})
+}
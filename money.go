@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var errMixedCurrencies = errors.New("mixed currencies")
+
+var currencyCodes = map[string]bool{}
+
+func init() {
+	codes := `AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL
+		BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP
+		ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR
+		IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL
+		LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR
+		NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD
+		SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX
+		USD UYU UZS VES VND VUV WST XAF XCD XOF XPF YER ZAR ZMW ZWL`
+	for _, code := range strings.Fields(codes) {
+		currencyCodes[code] = true
+	}
+}
+
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func (m Money) Valid() bool {
+	return m.Amount >= 0 && currencyCodes[m.Currency]
+}
+
+func SumMoney(amounts ...Money) (Money, error) {
+	if len(amounts) == 0 {
+		return Money{}, errors.New("nothing to sum")
+	}
+
+	total := Money{Currency: amounts[0].Currency}
+	for _, amount := range amounts {
+		if amount.Currency != total.Currency {
+			return Money{}, fmt.Errorf("%w, %s and %s", errMixedCurrencies, total.Currency, amount.Currency)
+		}
+
+		if amount.Amount > 0 && total.Amount > math.MaxInt64-amount.Amount {
+			return Money{}, fmt.Errorf("total overflows %s amount", total.Currency)
+		}
+		total.Amount += amount.Amount
+	}
+
+	return total, nil
+}
+
+func ticketTotal(ticket Ticket) (*Money, error) {
+	prices := []Money{}
+	for _, item := range ticket.Items {
+		if item.Price != nil {
+			prices = append(prices, *item.Price)
+		}
+	}
+
+	if len(prices) == 0 {
+		return nil, nil
+	}
+
+	total, err := SumMoney(prices...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &total, nil
+}
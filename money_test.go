@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSumMoney(t *testing.T) {
+	t.Run("sums a single currency", func(t *testing.T) {
+		got, err := SumMoney(Money{Amount: 1250, Currency: "EUR"}, Money{Amount: 399, Currency: "EUR"})
+		if err != nil {
+			t.Fatalf("unable to sum, %v", err)
+		}
+
+		want := Money{Amount: 1649, Currency: "EUR"}
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("rejects mixed currencies", func(t *testing.T) {
+		_, err := SumMoney(Money{Amount: 1250, Currency: "EUR"}, Money{Amount: 399, Currency: "USD"})
+		if !errors.Is(err, errMixedCurrencies) {
+			t.Errorf("got error %v, want %v", err, errMixedCurrencies)
+		}
+	})
+}
+
+func TestTicketTotal(t *testing.T) {
+	t.Run("returns the total of a single currency ticket", func(t *testing.T) {
+		store := &StubKitchenStore{
+			tickets: []Ticket{{ID: 0, Items: []Item{
+				{Name: "burger", Quantity: 1, Unit: UNIT_EACH, Price: &Money{Amount: 950, Currency: "GBP"}},
+				{Name: "fries", Quantity: 1, Unit: UNIT_EACH, Price: &Money{Amount: 300, Currency: "GBP"}},
+				{Name: "water", Quantity: 1, Unit: UNIT_EACH},
+			}}},
+		}
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(0))
+
+		got := TicketResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+
+		want := &Money{Amount: 1250, Currency: "GBP"}
+		if !reflect.DeepEqual(got.Total, want) {
+			t.Errorf("got total %v, want %v", got.Total, want)
+		}
+	})
+
+	t.Run("rejects a mixed currency ticket", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		body := bytes.NewBufferString(`{"Items": [
+			{"Name": "burger", "Price": {"Amount": 950, "Currency": "GBP"}},
+			{"Name": "fries", "Price": {"Amount": 300, "Currency": "EUR"}}
+		]}`)
+		request, _ := http.NewRequest(http.MethodPost, "/ticket/", body)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("rejects an unknown currency", func(t *testing.T) {
+		ticket := Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH, Price: &Money{Amount: 950, Currency: "XYZ"}}}}
+		if isTicketValid(ticket, defaultLimits) {
+			t.Errorf("got ticket with currency XYZ valid, want invalid")
+		}
+	})
+}
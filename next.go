@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultLongPollTimeout = 3 * time.Second
+	longPollInterval       = 100 * time.Millisecond
+)
+
+var ErrStationFull = errors.New("station is full")
+
+// Claim describes who claims the next ticket and when. A TicketID above 0
+// claims that ticket only, if it still matches the filter, and leaves the
+// filter's station to scope the capacity check alone. A Capacity above 0
+// makes the claim fail with ErrStationFull once the station already works
+// that many accepted tickets, checked atomically with the claim.
+type Claim struct {
+	At       time.Time
+	By       string
+	TicketID int
+	Capacity int
+}
+
+func ValidateLongPollTimeout(longPollTimeout, requestTimeout time.Duration) error {
+	if requestTimeout > 0 && longPollTimeout >= requestTimeout {
+		return fmt.Errorf("long poll timeout %v must be shorter than the request timeout %v", longPollTimeout, requestTimeout)
+	}
+
+	return nil
+}
+
+func (k *KitchenServer) nextTicket(w http.ResponseWriter, r *http.Request) {
+	station := r.URL.Query().Get("station")
+	if station == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// The claimed ticket isn't known until the store picks it atomically,
+	// so the policy decides on the station the cook is claiming from.
+	if !k.authorize(w, r, ACTION_UPDATE, Ticket{Station: station, Status: STATUS_PENDING}) {
+		return
+	}
+
+	if k.rejectFullStation(w, k.storeFor(r), station) {
+		return
+	}
+
+	filter := TicketFilter{Station: station, Statuses: []Status{STATUS_PENDING}}
+
+	timeout := time.NewTimer(k.longPollTimeout)
+	defer timeout.Stop()
+	poll := time.NewTicker(longPollInterval)
+	defer poll.Stop()
+
+	for {
+		now := k.clock.Now()
+		filter.ActiveAt = now
+		filter.ExcludeIDs = nil
+		blocked, err := blockedTicketIDs(k.storeFor(r), filter)
+		if err != nil {
+			k.logger.Error("unable to check ticket dependencies", "station", station, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		filter.ExcludeIDs = blocked
+
+		if r.Context().Err() != nil {
+			return
+		}
+
+		claim := Claim{At: now, By: r.URL.Query().Get("cook"), Capacity: k.stationCapacities[station]}
+		ticket, found, err := k.claimTicket(k.storeFor(r), filter, claim, TicketEvent{Type: EVENT_ACCEPTED, OccurredAt: now})
+		if errors.Is(err, ErrStationFull) {
+			k.writeStationFull(w, station, claim.Capacity)
+			return
+		}
+		if err != nil {
+			k.logger.Error("unable to claim next ticket", "station", station, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if found {
+			k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+			return
+		}
+
+		select {
+		case <-poll.C:
+		case <-timeout.C:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNextTicket(t *testing.T) {
+	t.Run("claims the oldest pending ticket for the station", func(t *testing.T) {
+		store := &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 0, Station: "grill", Status: STATUS_ACCEPTED},
+				{ID: 1, Station: "fryer", Status: STATUS_PENDING},
+				{ID: 2, Station: "grill", Status: STATUS_PENDING},
+				{ID: 3, Station: "grill", Status: STATUS_PENDING},
+			},
+		}
+		server := NewKitchenServer(store, WithClock(&StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newNextTicketRequest("grill"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := getTicketFromResponse(t, response.Body)
+		if got.ID != 2 || got.Status != STATUS_ACCEPTED {
+			t.Errorf("got ticket %d with status %v, want ticket 2 accepted", got.ID, got.Status)
+		}
+
+		assertEvents(t, store.events, []TicketEvent{
+			{Type: EVENT_ACCEPTED, TicketID: 2, Status: STATUS_ACCEPTED, OccurredAt: got.UpdatedAt},
+		})
+	})
+
+	t.Run("returns 204 when no ticket arrives before the timeout", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 0, Station: "fryer", Status: STATUS_PENDING}}}
+		server := NewKitchenServer(store, WithLongPollTimeout(10*time.Millisecond))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newNextTicketRequest("grill"))
+
+		assertStatus(t, response.Code, http.StatusNoContent)
+	})
+
+	t.Run("waits for a ticket created during the poll", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store, WithLongPollTimeout(time.Second))
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			store.StoreTicket(Ticket{Station: "grill", Status: STATUS_PENDING})
+		}()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newNextTicketRequest("grill"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("returns 400 without a station", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newNextTicketRequest(""))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("never hands the same ticket to concurrent callers", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		for range 5 {
+			store.StoreTicket(Ticket{Station: "grill", Status: STATUS_PENDING})
+		}
+		server := NewKitchenServer(store, WithLongPollTimeout(10*time.Millisecond))
+
+		var mu sync.Mutex
+		claimed := map[int]int{}
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newNextTicketRequest("grill"))
+				if response.Code != http.StatusOK {
+					return
+				}
+
+				ticket := getTicketFromResponse(t, response.Body)
+				mu.Lock()
+				claimed[ticket.ID]++
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		if len(claimed) != 5 {
+			t.Errorf("got %d tickets claimed, want 5", len(claimed))
+		}
+		for id, count := range claimed {
+			if count != 1 {
+				t.Errorf("ticket %d was claimed %d times, want once", id, count)
+			}
+		}
+	})
+}
+
+func TestNextTicketClaims(t *testing.T) {
+	t.Run("records the claiming cook", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		store.StoreTicket(Ticket{Station: "grill", Status: STATUS_PENDING})
+		server := NewKitchenServer(store)
+
+		request := newNextTicketRequest("grill")
+		request.URL.RawQuery += "&cook=alice"
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if got, _ := store.GetTicketByID(1); got.ClaimedBy != "alice" {
+			t.Errorf("got ticket claimed by %q, want alice", got.ClaimedBy)
+		}
+	})
+
+	t.Run("doesn't claim for a cancelled request", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		store.StoreTicket(Ticket{Station: "grill", Status: STATUS_PENDING})
+		server := NewKitchenServer(store)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newNextTicketRequest("grill").WithContext(ctx))
+
+		if got, _ := store.GetTicketByID(1); got.Status != STATUS_PENDING {
+			t.Errorf("got ticket status %v, want %v", got.Status, STATUS_PENDING)
+		}
+	})
+
+	t.Run("keeps concurrent claims within the station capacity", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		for range 5 {
+			store.StoreTicket(Ticket{Station: "grill", Status: STATUS_PENDING})
+		}
+		server := NewKitchenServer(store, WithStationCapacities(map[string]int{"grill": 2}), WithLongPollTimeout(10*time.Millisecond))
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				server.ServeHTTP(httptest.NewRecorder(), newNextTicketRequest("grill"))
+			}()
+		}
+		wg.Wait()
+
+		if accepted, _ := store.CountTickets(TicketFilter{Statuses: []Status{STATUS_ACCEPTED}}); accepted != 2 {
+			t.Errorf("got %d tickets accepted, want 2", accepted)
+		}
+	})
+}
+
+func TestValidateLongPollTimeout(t *testing.T) {
+	if err := ValidateLongPollTimeout(3*time.Second, 5*time.Second); err != nil {
+		t.Errorf("didn't expect an error but got one, %v", err)
+	}
+
+	if err := ValidateLongPollTimeout(5*time.Second, 5*time.Second); err == nil {
+		t.Error("expected an error for a long poll as long as the request timeout but didn't get one")
+	}
+
+	if err := ValidateLongPollTimeout(time.Minute, 0); err != nil {
+		t.Errorf("didn't expect an error without a request timeout but got one, %v", err)
+	}
+}
+
+func newNextTicketRequest(station string) *http.Request {
+	request, _ := http.NewRequest(http.MethodGet, "/ticket/next?station="+station, nil)
+	return request
+}
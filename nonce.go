@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	nonceHeader     = "X-Admin-Nonce"
+	defaultNonceTTL = 5 * time.Minute
+)
+
+type nonceStore struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newNonceStore() *nonceStore {
+	return &nonceStore{seen: map[string]time.Time{}}
+}
+
+func (n *nonceStore) claim(nonce string, now time.Time, ttl time.Duration) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for seen, seenAt := range n.seen {
+		if now.Sub(seenAt) >= ttl {
+			delete(n.seen, seen)
+		}
+	}
+
+	if _, ok := n.seen[nonce]; ok {
+		return false
+	}
+
+	n.seen[nonce] = now
+	return true
+}
+
+func (k *KitchenServer) claimNonce(w http.ResponseWriter, r *http.Request) bool {
+	nonce := r.Header.Get(nonceHeader)
+	if nonce == "" {
+		k.writeError(w, http.StatusBadRequest, CODE_NONCE_REQUIRED, nonceHeader+" header is required")
+		return false
+	}
+
+	if !k.nonces.claim(nonce, k.clock.Now(), k.nonceTTL) {
+		k.writeError(w, http.StatusConflict, CODE_NONCE_REUSED, "nonce was already used")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var nonceCounter atomic.Int64
+
+func newNonce() string {
+	return "nonce-" + strconv.FormatInt(nonceCounter.Add(1), 10)
+}
+
+func TestAdminNonce(t *testing.T) {
+	cutoff := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+
+	t.Run("accepts a fresh nonce", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithAdmin(true))
+
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, newPurgeCompletedRequest(cutoff))
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("rejects a reused nonce", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithAdmin(true))
+		request := newSetMaintenanceRequest(Maintenance{Enabled: true})
+		nonce := request.Header.Get(nonceHeader)
+
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+		assertStatus(t, response.Code, http.StatusOK)
+
+		request = newSetMaintenanceRequest(Maintenance{Enabled: false})
+		request.Header.Set(nonceHeader, nonce)
+		response = httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusConflict)
+		assertErrorCode(t, response, CODE_NONCE_REUSED)
+	})
+
+	t.Run("rejects a request without a nonce", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithAdmin(true))
+		request := newPurgeCompletedRequest(cutoff)
+		request.Header.Del(nonceHeader)
+
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_NONCE_REQUIRED)
+	})
+
+	t.Run("accepts a nonce again once it expires", func(t *testing.T) {
+		clock := &StubClock{now: time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+		server := NewKitchenServer(&StubKitchenStore{}, WithAdmin(true), WithClock(clock), WithNonceTTL(time.Minute))
+		request := newPurgeCompletedRequest(cutoff)
+		nonce := request.Header.Get(nonceHeader)
+
+		server.AdminHandler().ServeHTTP(httptest.NewRecorder(), request)
+		clock.now = clock.now.Add(time.Minute)
+
+		request = newPurgeCompletedRequest(cutoff)
+		request.Header.Set(nonceHeader, nonce)
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("doesn't require a nonce to read maintenance mode", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithAdmin(true))
+		request, _ := http.NewRequest(http.MethodGet, "/admin/maintenance", nil)
+
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+}
@@ -0,0 +1,475 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+const requestTimeoutMessage = "request took too long to process, try again later"
+
+type Option func(*KitchenServer)
+
+func NewKitchenServer(store KitchenStore, options ...Option) *KitchenServer {
+	k := &KitchenServer{
+		store:           store,
+		clock:           realClock{},
+		logger:          slog.Default(),
+		publisher:       NoopPublisher{},
+		authorizer:      AllowAllAuthorizer{},
+		defaultStatus:   STATUS_PENDING,
+		limits:          defaultLimits,
+		avgPrepTime:     defaultAveragePrepTime,
+		slowThreshold:   defaultSlowThreshold,
+		retryAttempts:   defaultRetryAttempts,
+		longPollTimeout: defaultLongPollTimeout,
+		retryDelay:      defaultRetryBaseDelay,
+		events:          newEventHub(),
+		dedup:           newDedupCache(),
+		nonces:          newNonceStore(),
+		startSoon:       newStartSoonNotices(),
+		acceptSLAAlerts: newAcceptSLAAlerts(),
+		idempotency:     newIdempotencyCache(),
+		idempotencyTTL:  defaultIdempotencyTTL,
+		nonceTTL:        defaultNonceTTL,
+		blobs:           NewFileBlobStore(filepath.Join(os.TempDir(), "kitchen-attachments")),
+	}
+
+	for _, option := range options {
+		option(k)
+	}
+
+	base := unwrapStore(store)
+	if pinger, ok := base.(Pinger); ok {
+		k.pinger = pinger
+	}
+	if reindexer, ok := base.(Reindexer); ok {
+		k.reindexer = reindexer
+	}
+	if importer, ok := base.(Importer); ok {
+		k.importer = importer
+	}
+	if outbox, ok := base.(OutboxStore); ok && k.outboxEnabled {
+		k.outbox = outbox
+	} else if k.outboxEnabled {
+		k.logger.Warn("store has no outbox, publishing events directly")
+	}
+
+	if k.retryAttempts > 1 {
+		k.store = &retryingStore{KitchenStore: k.store, attempts: k.retryAttempts, baseDelay: k.retryDelay}
+	}
+
+	k.Handler = k.envelopeResponses(k.APIKeyAuth(http.HandlerFunc(k.route)))
+	if k.requestTimeout > 0 {
+		k.Handler = k.timeoutRequests(k.Handler)
+	}
+	if k.inFlight != nil {
+		k.Handler = k.limitConcurrency(k.Handler)
+	}
+	if k.chaos != nil {
+		k.Handler = k.injectChaos(k.Handler)
+	}
+	k.Handler = k.logRequests(k.logBodies(k.prettyResponses(k.versionResponses(k.Handler))))
+	k.adminHandler = k.logRequests(k.prettyResponses(k.APIKeyAuth(http.HandlerFunc(k.serveAdmin))))
+
+	return k
+}
+
+// unwrapStore peels wrappers such as CachingKitchenStore off store so the
+// optional store interfaces can be found on the store underneath.
+func unwrapStore(store KitchenStore) KitchenStore {
+	for {
+		wrapper, ok := store.(interface{ Unwrap() KitchenStore })
+		if !ok {
+			return store
+		}
+		store = wrapper.Unwrap()
+	}
+}
+
+func WithClock(clock Clock) Option {
+	return func(k *KitchenServer) {
+		k.clock = clock
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(k *KitchenServer) {
+		k.logger = logger
+	}
+}
+
+func WithPublisher(publisher Publisher) Option {
+	return func(k *KitchenServer) {
+		k.publisher = publisher
+	}
+}
+
+func WithAuthorizer(authorizer Authorizer) Option {
+	return func(k *KitchenServer) {
+		k.authorizer = authorizer
+	}
+}
+
+func WithLimits(limits Limits) Option {
+	return func(k *KitchenServer) {
+		k.limits = limits
+	}
+}
+
+func WithMaxItems(maxItems int) Option {
+	return func(k *KitchenServer) {
+		k.limits.MaxItems = maxItems
+	}
+}
+
+// WithDuplicateItems controls tickets that list the same item more than once.
+// The default, DUPLICATES_ALLOW, keeps the items as sent.
+func WithDuplicateItems(mode DuplicateItems) Option {
+	return func(k *KitchenServer) {
+		k.duplicateItems = mode
+	}
+}
+
+func WithBlobStore(blobs BlobStore) Option {
+	return func(k *KitchenServer) {
+		k.blobs = blobs
+	}
+}
+
+func WithItemAliases(aliases map[string]string) Option {
+	return func(k *KitchenServer) {
+		k.itemAliases = map[string]string{}
+		for alias, name := range aliases {
+			k.itemAliases[aliasKey(alias)] = name
+		}
+	}
+}
+
+func WithDefaultStatus(status Status) Option {
+	return func(k *KitchenServer) {
+		k.defaultStatus = status
+	}
+}
+
+// WithClockSkew tolerates client clocks running behind by up to skew when
+// checking that ScheduledFor and ExpiresAt haven't already passed.
+func WithClockSkew(skew time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.clockSkew = skew
+	}
+}
+
+func WithAveragePrepTime(avgPrepTime time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.avgPrepTime = avgPrepTime
+	}
+}
+
+// WithMinPrepTimes keeps estimates from promising a ticket sooner than its
+// slowest item can be made. Items missing from times take defaultMin.
+func WithMinPrepTimes(times map[string]time.Duration, defaultMin time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.minPrepTimes = map[string]time.Duration{}
+		for name, minPrepTime := range times {
+			k.minPrepTimes[aliasKey(name)] = minPrepTime
+		}
+		k.defaultMinPrepTime = defaultMin
+	}
+}
+
+// WithDedupWindow returns the original ticket for a create whose body and
+// client match one seen less than window ago, guarding against double
+// submits. Deduplication is off by default and only applies to clients that
+// send an API key or an X-Client-ID header.
+func WithDedupWindow(window time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.dedupWindow = window
+	}
+}
+
+// WithConcurrencyLimit caps the requests handled at once at maxInFlight,
+// shedding a request with 503 when no slot frees up within acquireTimeout.
+func WithConcurrencyLimit(maxInFlight int, acquireTimeout time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.inFlight = make(chan struct{}, maxInFlight)
+		k.acquireTimeout = acquireTimeout
+	}
+}
+
+// WithIdempotencyTTL sets how long an Idempotency-Key replays the ticket it
+// created.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.idempotencyTTL = ttl
+	}
+}
+
+// WithStartSoonLeadTime sends a starting_soon event for a pending pre-order
+// lead before its ScheduledFor. A lead of 0 turns the notices off.
+func WithStartSoonLeadTime(lead time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.startSoonLead = lead
+	}
+}
+
+// WithAcceptSLA sends an accept_sla_breached event, once per ticket, when a
+// ticket stays pending longer than sla. An sla of 0 turns the alerts off.
+func WithAcceptSLA(sla time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.acceptSLA = sla
+	}
+}
+
+// WithTimezone sets the restaurant's timezone, which decides where a business
+// day starts and which station rule applies. Without it the clock's own
+// timezone is used.
+func WithTimezone(timezone *time.Location) Option {
+	return func(k *KitchenServer) {
+		k.timezone = timezone
+	}
+}
+
+// WithStaleAfter flags active tickets created more than threshold ago as
+// Stale. A threshold of 0 never flags a ticket.
+func WithStaleAfter(threshold time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.staleAfter = threshold
+	}
+}
+
+// WithOutbox writes published events to the store's outbox alongside the
+// ticket event, leaving RelayOutbox to deliver them so they survive a
+// publisher outage or a restart.
+func WithOutbox(enabled bool) Option {
+	return func(k *KitchenServer) {
+		k.outboxEnabled = enabled
+	}
+}
+
+// WithCompletedLinger keeps a ticket on the active view for linger after it is
+// completed, flagged as Lingering, so a cook can catch a mistake.
+func WithCompletedLinger(linger time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.completedLinger = linger
+	}
+}
+
+// WithNonceTTL sets how long a used admin nonce is remembered and rejected.
+func WithNonceTTL(ttl time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.nonceTTL = ttl
+	}
+}
+
+// WithFeatureFlags turns gated endpoints on or off. Features missing from
+// flags stay enabled.
+func WithFeatureFlags(flags map[string]bool) Option {
+	return func(k *KitchenServer) {
+		k.features = flags
+	}
+}
+
+func WithTaxRate(rate TaxRate) Option {
+	return func(k *KitchenServer) {
+		k.taxRate = rate
+	}
+}
+
+// WithTaxCategories taxes items with a TaxCategory at that category's rate
+// rather than the ticket wide rate. Items naming any other category are
+// rejected.
+func WithTaxCategories(categories map[string]TaxRate) Option {
+	return func(k *KitchenServer) {
+		k.taxCategories = categories
+	}
+}
+
+// WithDefaultVersion shapes responses as version for clients that don't send
+// an Accept-Version header.
+func WithDefaultVersion(version string) Option {
+	return func(k *KitchenServer) {
+		k.defaultVersion = version
+	}
+}
+
+func WithIDFormat(format IDFormat) Option {
+	return func(k *KitchenServer) {
+		k.idFormat = format
+	}
+}
+
+// WithBodyLogging logs ticket request and response bodies at debug level, cut
+// to logging.MaxBytes with logging.RedactFields masked. Bodies can carry
+// customer details, so this is only meant for debugging an integration.
+func WithBodyLogging(logging BodyLogging) Option {
+	return func(k *KitchenServer) {
+		k.bodyLogging = &logging
+		k.bodyRedactor = newBodyRedactor(logging.RedactFields)
+	}
+}
+
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.slowThreshold = threshold
+	}
+}
+
+func WithRetry(attempts int, baseDelay time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.retryAttempts = attempts
+		k.retryDelay = baseDelay
+	}
+}
+
+func WithLongPollTimeout(timeout time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.longPollTimeout = timeout
+	}
+}
+
+func WithWriteQueue(size, workers int) Option {
+	return func(k *KitchenServer) {
+		k.writes = newWriteQueue(size, workers)
+	}
+}
+
+func WithNoteRedaction(words ...string) Option {
+	return func(k *KitchenServer) {
+		k.redactor = newNoteRedactor(words)
+	}
+}
+
+func WithRedactionAudit(enabled bool) Option {
+	return func(k *KitchenServer) {
+		k.auditRedactions = enabled
+	}
+}
+
+func WithStationRules(rules ...StationRule) Option {
+	return func(k *KitchenServer) {
+		k.stationRules = rules
+	}
+}
+
+func WithAutoAcceptStations(stations ...string) Option {
+	return func(k *KitchenServer) {
+		k.autoAcceptStations = map[string]bool{}
+		for _, station := range stations {
+			k.autoAcceptStations[station] = true
+		}
+	}
+}
+
+func WithKitchens(kitchenIDs ...string) Option {
+	return func(k *KitchenServer) {
+		k.kitchens = map[string]bool{}
+		for _, kitchenID := range kitchenIDs {
+			k.kitchens[kitchenID] = true
+		}
+	}
+}
+
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(k *KitchenServer) {
+		k.requestTimeout = timeout
+	}
+}
+
+func WithAdmin(enabled bool) Option {
+	return func(k *KitchenServer) {
+		k.adminEnabled = enabled
+	}
+}
+
+func WithStringIDs(enabled bool) Option {
+	return func(k *KitchenServer) {
+		k.stringIDs = enabled
+	}
+}
+
+func WithEnvelope(enabled bool) Option {
+	return func(k *KitchenServer) {
+		k.envelope = enabled
+	}
+}
+
+func WithAPIKeys(keys ...APIKey) Option {
+	return func(k *KitchenServer) {
+		k.apiKeys = keys
+	}
+}
+
+func WithStreamQueryToken(enabled bool) Option {
+	return func(k *KitchenServer) {
+		k.streamQueryToken = enabled
+	}
+}
+
+func WithStreamOrigins(origins ...string) Option {
+	return func(k *KitchenServer) {
+		k.streamOrigins = map[string]bool{}
+		for _, origin := range origins {
+			k.streamOrigins[origin] = true
+		}
+	}
+}
+
+// WithRemoveItemsAtZero removes an item decremented below a quantity of one
+// instead of keeping it at one.
+func WithRemoveItemsAtZero(enabled bool) Option {
+	return func(k *KitchenServer) {
+		k.removeItemsAtZero = enabled
+	}
+}
+
+// WithStations lists the stations items may name as their StationOverride.
+// Without it any override is accepted.
+func WithStations(stations ...string) Option {
+	return func(k *KitchenServer) {
+		k.stations = map[string]bool{}
+		for _, station := range stations {
+			k.stations[station] = true
+		}
+	}
+}
+
+// WithStationCapacities caps how many accepted tickets each station works at
+// once. Stations missing from capacities are unlimited.
+func WithStationCapacities(capacities map[string]int) Option {
+	return func(k *KitchenServer) {
+		k.stationCapacities = capacities
+	}
+}
+
+func WithRequireIfMatchOnDelete(enabled bool) Option {
+	return func(k *KitchenServer) {
+		k.requireIfMatch = enabled
+	}
+}
+
+// WithChaos injects latency and 503s into a share of requests. main only
+// offers it in builds with the chaos tag.
+func WithChaos(config ChaosConfig) Option {
+	return func(k *KitchenServer) {
+		k.chaos = &config
+	}
+}
+
+func WithRetryAfterHTTPDate(enabled bool) Option {
+	return func(k *KitchenServer) {
+		k.retryAfterHTTPDate = enabled
+	}
+}
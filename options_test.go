@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewKitchenServer(t *testing.T) {
+	t.Run("applies clock and default status options", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+		server := NewKitchenServer(store, WithClock(clock), WithDefaultStatus(STATUS_ACCEPTED))
+
+		ticket := Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+		request := newCreateTicketRequest(ticket)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+
+		got, _ := store.GetTicketByID(0)
+		if got.Status != STATUS_ACCEPTED {
+			t.Errorf("got status %v, want %v", got.Status, STATUS_ACCEPTED)
+		}
+
+		if !got.CreatedAt.Equal(clock.now) {
+			t.Errorf("got CreatedAt %v, want %v", got.CreatedAt, clock.now)
+		}
+	})
+
+	t.Run("applies max items option", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithMaxItems(1))
+
+		ticket := Ticket{Items: []Item{
+			{Name: "burger", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "fries", Quantity: 1, Unit: UNIT_EACH},
+		}}
+		request := newCreateTicketRequest(ticket)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("applies logger option", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		logger := slog.New(slog.NewTextHandler(buffer, nil))
+		server := NewKitchenServer(&FailingKitchenStore{}, WithLogger(logger))
+
+		request := newListTicketsRequest("")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusInternalServerError)
+		if !strings.Contains(buffer.String(), "unable to list tickets") {
+			t.Errorf("expected store error to be logged, got %q", buffer.String())
+		}
+	})
+
+	t.Run("applies admin option", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithAdmin(true))
+
+		request := newPurgeCompletedRequest(time.Now().Format(time.RFC3339))
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+}
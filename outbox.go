@@ -0,0 +1,458 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const outboxBatchSize = 100
+
+type OutboxEntry struct {
+	ID    int
+	Event TicketEvent
+}
+
+type OutboxStore interface {
+	StoreTicketEventWithOutbox(event TicketEvent) error
+	AppendOutbox(event TicketEvent) error
+	GetOutbox(limit int) ([]OutboxEntry, error)
+	DeleteOutboxEntry(id int) error
+}
+
+// OutboxWriter is implemented by stores that can write a ticket change, its
+// event and the event's outbox entry in one transaction. The store fills in
+// the ID, and for claims, moves and expiries the status, of the ticket it
+// wrote.
+type OutboxWriter interface {
+	StoreTicketWithOutbox(ticket Ticket, events []TicketEvent) (int, error)
+	StoreTicketIfNotExistsWithOutbox(ticket Ticket, events []TicketEvent) (Ticket, bool, error)
+	UpdateTicketWithOutbox(ticket Ticket, event TicketEvent) error
+	DeleteTicketWithOutbox(ticketID int, deletedAt time.Time, event TicketEvent) error
+	ClaimNextTicketWithOutbox(filter TicketFilter, claim Claim, event TicketEvent) (Ticket, bool, error)
+	MoveTicketWithOutbox(ticketID int, delta int, movedAt time.Time, event TicketEvent) (Ticket, error)
+	ExpireTicketWithOutbox(ticketID int, now time.Time, event TicketEvent) (Ticket, bool, error)
+}
+
+var errNoOutboxWriter = errors.New("store can't write a ticket change with its outbox entry")
+
+type EventDeliverer interface {
+	Deliver(TicketEvent) error
+}
+
+func (i *InMemoryKitchenStore) StoreTicketEventWithOutbox(event TicketEvent) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.apply(StoreChange{Type: CHANGE_EVENT, Event: event})
+	i.appendOutbox(event)
+
+	return nil
+}
+
+func (i *InMemoryKitchenStore) StoreTicketWithOutbox(ticket Ticket, events []TicketEvent) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	id, err := i.storeTicket(ticket)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		event.TicketID = id
+		i.apply(StoreChange{Type: CHANGE_EVENT, Event: event})
+		i.appendOutbox(event)
+	}
+
+	return id, nil
+}
+
+func (i *InMemoryKitchenStore) StoreTicketIfNotExistsWithOutbox(ticket Ticket, events []TicketEvent) (Ticket, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	stored, created, err := i.storeTicketIfNotExists(ticket)
+	if err != nil || !created {
+		return stored, created, err
+	}
+
+	for _, event := range events {
+		event.TicketID = stored.ID
+		i.apply(StoreChange{Type: CHANGE_EVENT, Event: event})
+		i.appendOutbox(event)
+	}
+
+	return stored, true, nil
+}
+
+func (i *InMemoryKitchenStore) UpdateTicketWithOutbox(ticket Ticket, event TicketEvent) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if existing, ok := i.tickets[ticket.ID]; !ok || existing.Deleted {
+		return fmt.Errorf("no ticket with ID = %d", ticket.ID)
+	}
+
+	i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+	i.apply(StoreChange{Type: CHANGE_EVENT, Event: event})
+	i.appendOutbox(event)
+
+	return nil
+}
+
+// DeleteTicketWithOutbox leaves the deletion out of the ticket's event log,
+// like publishEvent does, and only queues it for the publisher.
+func (i *InMemoryKitchenStore) DeleteTicketWithOutbox(ticketID int, deletedAt time.Time, event TicketEvent) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if err := i.deleteTicket(ticketID, deletedAt); err != nil {
+		return err
+	}
+
+	i.appendOutbox(event)
+	return nil
+}
+
+func (i *InMemoryKitchenStore) ClaimNextTicketWithOutbox(filter TicketFilter, claim Claim, event TicketEvent) (Ticket, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ticket, claimed, err := i.claimNextTicket(filter, claim)
+	if err != nil || !claimed {
+		return ticket, claimed, err
+	}
+
+	i.recordWithOutbox(ticket, event)
+	return ticket, true, nil
+}
+
+func (i *InMemoryKitchenStore) MoveTicketWithOutbox(ticketID int, delta int, movedAt time.Time, event TicketEvent) (Ticket, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ticket, err := i.moveTicket(ticketID, delta, movedAt)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	i.recordWithOutbox(ticket, event)
+	return ticket, nil
+}
+
+func (i *InMemoryKitchenStore) ExpireTicketWithOutbox(ticketID int, now time.Time, event TicketEvent) (Ticket, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ticket, expired, err := i.expireTicket(ticketID, now)
+	if err != nil || !expired {
+		return ticket, expired, err
+	}
+
+	i.recordWithOutbox(ticket, event)
+	return ticket, true, nil
+}
+
+// recordWithOutbox logs event for the ticket a write left behind and queues it
+// for the publisher.
+func (i *InMemoryKitchenStore) recordWithOutbox(ticket Ticket, event TicketEvent) {
+	event = stampEvent(event, ticket)
+	i.apply(StoreChange{Type: CHANGE_EVENT, Event: event})
+	i.appendOutbox(event)
+}
+
+func (i *InMemoryKitchenStore) appendOutbox(event TicketEvent) {
+	i.apply(StoreChange{Type: CHANGE_OUTBOX, Outbox: OutboxEntry{ID: i.lastOutboxID + 1, Event: event}})
+}
+
+func (i *InMemoryKitchenStore) AppendOutbox(event TicketEvent) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.appendOutbox(event)
+	return nil
+}
+
+func (i *InMemoryKitchenStore) GetOutbox(limit int) ([]OutboxEntry, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	entries := make([]OutboxEntry, min(limit, len(i.outbox)))
+	copy(entries, i.outbox)
+
+	return entries, nil
+}
+
+func (i *InMemoryKitchenStore) DeleteOutboxEntry(id int) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.apply(StoreChange{Type: CHANGE_DELETE_OUTBOX, Outbox: OutboxEntry{ID: id}})
+
+	return nil
+}
+
+func (i *InMemoryKitchenStore) foldOutbox(change StoreChange) {
+	switch change.Type {
+	case CHANGE_OUTBOX:
+		i.outbox = append(i.outbox, change.Outbox)
+		i.lastOutboxID = max(i.lastOutboxID, change.Outbox.ID)
+	case CHANGE_DELETE_OUTBOX:
+		i.outbox = slices.DeleteFunc(i.outbox, func(entry OutboxEntry) bool {
+			return entry.ID == change.Outbox.ID
+		})
+	}
+}
+
+func (k *KitchenServer) publishEvent(event TicketEvent) {
+	if k.outbox == nil {
+		k.publisher.Publish(event)
+	} else if err := k.outbox.AppendOutbox(event); err != nil {
+		k.logger.Error("unable to add ticket event to outbox", "type", event.Type, "ticket_id", event.TicketID, "error", err)
+		k.publisher.Publish(event)
+	}
+
+	k.events.Publish(event)
+}
+
+// updateTicket stores ticket and records event for it. With the outbox on and
+// a store that supports it, the ticket, the event and its outbox entry are
+// written in a single transaction.
+func (k *KitchenServer) updateTicket(store KitchenStore, ticket Ticket, event TicketEvent) error {
+	if writer, ok := store.(OutboxWriter); ok && k.outbox != nil {
+		err := writer.UpdateTicketWithOutbox(ticket, event)
+		if err == nil {
+			k.events.Publish(event)
+			return nil
+		}
+		if !errors.Is(err, errNoOutboxWriter) {
+			return err
+		}
+	}
+
+	if err := store.UpdateTicket(ticket); err != nil {
+		return err
+	}
+
+	k.recordEvent(event)
+	return nil
+}
+
+// outboxWriter returns the store as an OutboxWriter when the outbox is on.
+func (k *KitchenServer) outboxWriter(store KitchenStore) (OutboxWriter, bool) {
+	writer, ok := store.(OutboxWriter)
+	return writer, ok && k.outbox != nil
+}
+
+// storeTicket stores a new ticket and records its creation, through the
+// outbox in the same transaction when it can.
+func (k *KitchenServer) storeTicket(store KitchenStore, ticket Ticket) (int, error) {
+	if writer, ok := k.outboxWriter(store); ok {
+		events := k.createdEvents(0, ticket)
+		id, err := writer.StoreTicketWithOutbox(ticket, events)
+		if err == nil {
+			for _, event := range events {
+				event.TicketID = id
+				k.events.Publish(event)
+			}
+			return id, nil
+		}
+		if !errors.Is(err, errNoOutboxWriter) {
+			return 0, err
+		}
+	}
+
+	id, err := store.StoreTicket(ticket)
+	if err != nil {
+		return 0, err
+	}
+
+	k.recordCreated(id, ticket)
+	return id, nil
+}
+
+// storeTicketIfNotExists is storeTicket for StoreTicketIfNotExists, and
+// records nothing when the ticket already existed.
+func (k *KitchenServer) storeTicketIfNotExists(store KitchenStore, ticket Ticket) (Ticket, bool, error) {
+	if writer, ok := k.outboxWriter(store); ok {
+		events := k.createdEvents(0, ticket)
+		stored, created, err := writer.StoreTicketIfNotExistsWithOutbox(ticket, events)
+		if err == nil {
+			for _, event := range events {
+				event.TicketID = stored.ID
+				if created {
+					k.events.Publish(event)
+				}
+			}
+			return stored, created, nil
+		}
+		if !errors.Is(err, errNoOutboxWriter) {
+			return Ticket{}, false, err
+		}
+	}
+
+	stored, created, err := store.StoreTicketIfNotExists(ticket)
+	if err != nil || !created {
+		return stored, created, err
+	}
+
+	k.recordCreated(stored.ID, stored)
+	return stored, true, nil
+}
+
+// tombstoneTicket deletes a ticket and publishes event for it, through the
+// outbox in the same transaction when it can.
+func (k *KitchenServer) tombstoneTicket(store KitchenStore, ticketID int, deletedAt time.Time, event TicketEvent) error {
+	if writer, ok := k.outboxWriter(store); ok {
+		err := writer.DeleteTicketWithOutbox(ticketID, deletedAt, event)
+		if err == nil {
+			k.events.Publish(event)
+			return nil
+		}
+		if !errors.Is(err, errNoOutboxWriter) {
+			return err
+		}
+	}
+
+	if err := store.DeleteTicket(ticketID, deletedAt); err != nil {
+		return err
+	}
+
+	k.publishEvent(event)
+	return nil
+}
+
+// claimTicket claims the next ticket and records event for the claimed
+// ticket, through the outbox in the same transaction when it can.
+func (k *KitchenServer) claimTicket(store KitchenStore, filter TicketFilter, claim Claim, event TicketEvent) (Ticket, bool, error) {
+	if writer, ok := k.outboxWriter(store); ok {
+		ticket, claimed, err := writer.ClaimNextTicketWithOutbox(filter, claim, event)
+		if err == nil {
+			if claimed {
+				k.events.Publish(stampEvent(event, ticket))
+			}
+			return ticket, claimed, nil
+		}
+		if !errors.Is(err, errNoOutboxWriter) {
+			return Ticket{}, false, err
+		}
+	}
+
+	ticket, claimed, err := store.ClaimNextTicket(filter, claim)
+	if err != nil || !claimed {
+		return ticket, claimed, err
+	}
+
+	k.recordEvent(stampEvent(event, ticket))
+	return ticket, true, nil
+}
+
+// shiftTicket moves a ticket in the queue and records event for it, through
+// the outbox in the same transaction when it can.
+func (k *KitchenServer) shiftTicket(store KitchenStore, ticketID int, delta int, movedAt time.Time, event TicketEvent) (Ticket, error) {
+	if writer, ok := k.outboxWriter(store); ok {
+		ticket, err := writer.MoveTicketWithOutbox(ticketID, delta, movedAt, event)
+		if err == nil {
+			k.events.Publish(stampEvent(event, ticket))
+			return ticket, nil
+		}
+		if !errors.Is(err, errNoOutboxWriter) {
+			return Ticket{}, err
+		}
+	}
+
+	ticket, err := store.MoveTicket(ticketID, delta, movedAt)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	k.recordEvent(stampEvent(event, ticket))
+	return ticket, nil
+}
+
+// expireTicket cancels a ticket past its ExpiresAt and records event for it,
+// through the outbox in the same transaction when it can.
+func (k *KitchenServer) expireTicket(store KitchenStore, ticketID int, now time.Time, event TicketEvent) (Ticket, bool, error) {
+	if writer, ok := k.outboxWriter(store); ok {
+		ticket, expired, err := writer.ExpireTicketWithOutbox(ticketID, now, event)
+		if err == nil {
+			if expired {
+				k.events.Publish(stampEvent(event, ticket))
+			}
+			return ticket, expired, nil
+		}
+		if !errors.Is(err, errNoOutboxWriter) {
+			return Ticket{}, false, err
+		}
+	}
+
+	ticket, expired, err := store.ExpireTicket(ticketID, now)
+	if err != nil || !expired {
+		return ticket, expired, err
+	}
+
+	k.recordEvent(stampEvent(event, ticket))
+	return ticket, true, nil
+}
+
+// stampEvent fills in the ID and status a store write left ticket with.
+func stampEvent(event TicketEvent, ticket Ticket) TicketEvent {
+	event.TicketID = ticket.ID
+	event.Status = ticket.Status
+	return event
+}
+
+func (k *KitchenServer) deliver(event TicketEvent) error {
+	if deliverer, ok := k.publisher.(EventDeliverer); ok {
+		return deliverer.Deliver(event)
+	}
+
+	k.publisher.Publish(event)
+	return nil
+}
+
+func (k *KitchenServer) RelayOutbox() (int, error) {
+	if k.outbox == nil {
+		return 0, nil
+	}
+
+	entries, err := k.outbox.GetOutbox(outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for delivered, entry := range entries {
+		if err := k.deliver(entry.Event); err != nil {
+			return delivered, err
+		}
+
+		if err := k.outbox.DeleteOutboxEntry(entry.ID); err != nil {
+			return delivered, err
+		}
+	}
+
+	return len(entries), nil
+}
+
+func (k *KitchenServer) RelayOutboxEvery(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := k.RelayOutbox(); err != nil {
+					k.logger.Error("unable to relay outbox", "error", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
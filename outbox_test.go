@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type FlakyDeliverer struct {
+	StubPublisher
+	failures int
+}
+
+func (f *FlakyDeliverer) Deliver(event TicketEvent) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("broker unavailable")
+	}
+
+	f.events = append(f.events, event)
+	return nil
+}
+
+type UpdateFailingKitchenStore struct {
+	*InMemoryKitchenStore
+}
+
+func (u *UpdateFailingKitchenStore) UpdateTicket(Ticket) error {
+	return errStoreUnavailable
+}
+
+// OutboxAppendFailingKitchenStore refuses outbox entries written apart from
+// their ticket change, so only the transactional writes reach its outbox.
+type OutboxAppendFailingKitchenStore struct {
+	*InMemoryKitchenStore
+}
+
+func (o *OutboxAppendFailingKitchenStore) StoreTicketEventWithOutbox(TicketEvent) error {
+	return errStoreUnavailable
+}
+
+func (o *OutboxAppendFailingKitchenStore) AppendOutbox(TicketEvent) error {
+	return errStoreUnavailable
+}
+
+func TestOutbox(t *testing.T) {
+	ticket := Ticket{Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+
+	t.Run("queues events until the relay delivers and removes them", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		publisher := &FlakyDeliverer{}
+		server := NewKitchenServer(store, WithPublisher(publisher), WithOutbox(true))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+		assertStatus(t, response.Code, http.StatusAccepted)
+
+		if len(publisher.events) != 0 {
+			t.Errorf("got %d events published before relaying, want 0", len(publisher.events))
+		}
+
+		delivered, err := server.RelayOutbox()
+		if err != nil {
+			t.Fatalf("didn't expect an error but got one, %v", err)
+		}
+		if delivered != 1 || len(publisher.events) != 1 || publisher.events[0].Type != EVENT_CREATED {
+			t.Errorf("got %d delivered and events %v, want the created event", delivered, publisher.events)
+		}
+
+		if entries, _ := store.GetOutbox(outboxBatchSize); len(entries) != 0 {
+			t.Errorf("got outbox %v, want it drained", entries)
+		}
+	})
+
+	t.Run("records the ticket event alongside the outbox entry", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store, WithOutbox(true))
+
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(ticket))
+
+		events, _ := store.GetTicketEvents(1)
+		entries, _ := store.GetOutbox(outboxBatchSize)
+		if len(events) != 1 || len(entries) != 1 || entries[0].Event != events[0] {
+			t.Errorf("got events %v and outbox %v, want the created event in both", events, entries)
+		}
+	})
+
+	t.Run("writes a ticket change in the same transaction as its outbox entry", func(t *testing.T) {
+		store := &UpdateFailingKitchenStore{NewInMemoryKitchenStore()}
+		server := NewKitchenServer(store, WithOutbox(true))
+		id, _ := store.StoreTicket(Ticket{Status: STATUS_ACCEPTED, Items: ticket.Items})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(id))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got, _ := store.GetTicketByID(id)
+		if got.Status != STATUS_COMPLETED {
+			t.Errorf("got status %v, want %v", got.Status, STATUS_COMPLETED)
+		}
+		entries, _ := store.GetOutbox(outboxBatchSize)
+		if len(entries) != 1 || entries[0].Event.Type != EVENT_COMPLETED {
+			t.Errorf("got outbox %v, want the completed event", entries)
+		}
+	})
+
+	t.Run("writes every ticket change with exactly one outbox entry", func(t *testing.T) {
+		cases := []struct {
+			name   string
+			mutate func(t *testing.T, server *KitchenServer, id int)
+			want   string
+		}{
+			{"create", func(t *testing.T, server *KitchenServer, _ int) {
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newCreateTicketRequest(ticket))
+				assertStatus(t, response.Code, http.StatusAccepted)
+			}, EVENT_CREATED},
+			{"create if not exists", func(t *testing.T, server *KitchenServer, _ int) {
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newPostTicketRequest("/ticket/?ifNotExists=true", Ticket{OrderID: "42", Items: ticket.Items}))
+				assertStatus(t, response.Code, http.StatusCreated)
+			}, EVENT_CREATED},
+			{"accept", func(t *testing.T, server *KitchenServer, id int) {
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newAcceptTicketRequest(id))
+				assertStatus(t, response.Code, http.StatusOK)
+			}, EVENT_ACCEPTED},
+			{"claim", func(t *testing.T, server *KitchenServer, _ int) {
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newNextTicketRequest("grill"))
+				assertStatus(t, response.Code, http.StatusOK)
+			}, EVENT_ACCEPTED},
+			{"bump", func(t *testing.T, server *KitchenServer, id int) {
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newMoveTicketRequest(id, "bump"))
+				assertStatus(t, response.Code, http.StatusOK)
+			}, EVENT_BUMPED},
+			{"delete", func(t *testing.T, server *KitchenServer, id int) {
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newDeleteTicketRequest(id, ""))
+				assertStatus(t, response.Code, http.StatusNoContent)
+			}, EVENT_DELETED},
+			{"expire", func(t *testing.T, server *KitchenServer, _ int) {
+				if swept, err := server.SweepExpired(); err != nil || swept != 1 {
+					t.Fatalf("got %d swept, %v, want 1 ticket expired", swept, err)
+				}
+			}, EVENT_CANCELLED},
+		}
+
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				store := &OutboxAppendFailingKitchenStore{NewInMemoryKitchenStore()}
+				expiresAt := time.Now().Add(-time.Minute)
+				if c.want != EVENT_CANCELLED {
+					expiresAt = time.Now().Add(time.Hour)
+				}
+				id, _ := store.StoreTicket(Ticket{Station: "grill", Status: STATUS_PENDING, ExpiresAt: &expiresAt, Items: ticket.Items})
+				server := NewKitchenServer(store, WithOutbox(true))
+
+				c.mutate(t, server, id)
+
+				entries, _ := store.GetOutbox(outboxBatchSize)
+				if len(entries) != 1 || entries[0].Event.Type != c.want {
+					t.Errorf("got outbox %v, want exactly one %s entry", entries, c.want)
+				}
+			})
+		}
+	})
+
+	t.Run("keeps events the publisher fails to deliver for the next relay", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		publisher := &FlakyDeliverer{failures: 1}
+		server := NewKitchenServer(store, WithPublisher(publisher), WithOutbox(true))
+
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(ticket))
+		server.ServeHTTP(httptest.NewRecorder(), newAcceptTicketRequest(1))
+
+		if _, err := server.RelayOutbox(); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+		if entries, _ := store.GetOutbox(outboxBatchSize); len(entries) != 2 {
+			t.Errorf("got %d outbox entries, want 2 kept", len(entries))
+		}
+
+		delivered, err := server.RelayOutbox()
+		if err != nil || delivered != 2 {
+			t.Errorf("got %d delivered and error %v, want 2 delivered", delivered, err)
+		}
+		if len(publisher.events) != 2 || publisher.events[0].Type != EVENT_CREATED || publisher.events[1].Type != EVENT_ACCEPTED {
+			t.Errorf("got events %v, want created then accepted", publisher.events)
+		}
+	})
+
+	t.Run("delivers queued events after a restart", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store, WithPublisher(&FlakyDeliverer{}), WithOutbox(true))
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(ticket))
+
+		buffer := &bytes.Buffer{}
+		if err := store.Snapshot(buffer); err != nil {
+			t.Fatalf("unable to snapshot store, %v", err)
+		}
+
+		restored := NewInMemoryKitchenStore()
+		if err := restored.Restore(buffer); err != nil {
+			t.Fatalf("unable to restore store, %v", err)
+		}
+		publisher := &FlakyDeliverer{}
+		restarted := NewKitchenServer(restored, WithPublisher(publisher), WithOutbox(true))
+		restarted.ServeHTTP(httptest.NewRecorder(), newAcceptTicketRequest(1))
+
+		delivered, err := restarted.RelayOutbox()
+		if err != nil || delivered != 2 {
+			t.Errorf("got %d delivered and error %v, want 2 delivered", delivered, err)
+		}
+		if len(publisher.events) != 2 || publisher.events[0].Type != EVENT_CREATED {
+			t.Errorf("got events %v, want the created event from before the restart first", publisher.events)
+		}
+	})
+
+	t.Run("replays the outbox from the event log", func(t *testing.T) {
+		store := NewEventSourcedKitchenStore()
+		server := NewKitchenServer(store, WithOutbox(true))
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(ticket))
+		server.ServeHTTP(httptest.NewRecorder(), newAcceptTicketRequest(1))
+		server.RelayOutbox()
+		server.ServeHTTP(httptest.NewRecorder(), newCompleteTicketRequest(1))
+
+		store.Rebuild()
+
+		entries, _ := store.GetOutbox(outboxBatchSize)
+		if len(entries) != 1 || entries[0].Event.Type != EVENT_COMPLETED {
+			t.Errorf("got outbox %v, want only the completed event", entries)
+		}
+	})
+
+	t.Run("publishes directly without the outbox", func(t *testing.T) {
+		publisher := &StubPublisher{}
+		server := NewKitchenServer(NewInMemoryKitchenStore(), WithPublisher(publisher))
+
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(ticket))
+
+		if len(publisher.events) != 1 {
+			t.Errorf("got %d events published, want 1", len(publisher.events))
+		}
+	})
+}
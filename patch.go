@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+var patchableFields = map[string]bool{
+	"OrderID":   true,
+	"Items":     true,
+	"Notes":     true,
+	"DependsOn": true,
+}
+
+func (k *KitchenServer) patchTicket(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != mergePatchContentType {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	ticketID, ok := k.pathTicketID(w, strings.TrimPrefix(r.URL.Path, "/ticket/"))
+	if !ok {
+		return
+	}
+
+	patch := map[string]any{}
+	err = json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for field := range patch {
+		if !patchableFields[field] {
+			k.writeError(w, http.StatusBadRequest, CODE_FIELD_NOT_PATCHABLE, fmt.Sprintf("field %q can't be patched", field))
+			return
+		}
+	}
+
+	store := k.storeFor(r)
+	ticket, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	patched, err := applyMergePatch(ticket, patch)
+	if err == nil {
+		err = k.checkTicket(patched)
+	}
+	if err == nil {
+		patched.Items, err = k.handleDuplicateItems(patched.Items)
+	}
+	if _, ok := patch["DependsOn"]; ok && err == nil {
+		err = k.checkDependencies(store, ticketID, patched.DependsOn)
+	}
+	if err != nil {
+		k.writeValidationError(w, err)
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, patched) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	patched.UpdatedAt = now
+	original := patched.Notes
+	patched.Notes = k.redactNotes(patched.Notes)
+
+	err = k.updateTicket(store, patched, TicketEvent{
+		Type:       EVENT_UPDATED,
+		TicketID:   ticketID,
+		Status:     patched.Status,
+		OccurredAt: now,
+	})
+	if err != nil {
+		k.logger.Error("unable to patch ticket", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	k.auditRedaction(patched, original, now)
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(patched))
+}
+
+func applyMergePatch(ticket Ticket, patch map[string]any) (Ticket, error) {
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	target := map[string]any{}
+	err = json.Unmarshal(data, &target)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	data, err = json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	patched := Ticket{}
+	err = json.Unmarshal(data, &patched)
+	if err != nil {
+		return Ticket{}, fmt.Errorf("unable to unmarshal patched ticket, %v", err)
+	}
+
+	return patched, nil
+}
+
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPatchTicket(t *testing.T) {
+	createdAt := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	clock := &StubClock{createdAt.Add(time.Minute)}
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{{
+				ID:        1,
+				OrderID:   "order-42",
+				Status:    STATUS_PENDING,
+				Items:     Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}},
+				Notes:     "no onions",
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			}},
+		}
+	}
+
+	t.Run("sets a field and leaves others unchanged", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(1, `{"Notes":"extra pickles"}`))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		want := store.tickets[0]
+		want.Notes = "extra pickles"
+		want.UpdatedAt = clock.now
+
+		got, _ := store.GetTicketByID(1)
+		assertTicket(t, got, want)
+	})
+
+	t.Run("clears a field set to null", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(1, `{"Notes":null}`))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got, _ := store.GetTicketByID(1)
+		if got.Notes != "" {
+			t.Errorf("got notes %q, want them cleared", got.Notes)
+		}
+		if got.OrderID != "order-42" {
+			t.Errorf("got order ID %q, want it unchanged", got.OrderID)
+		}
+	})
+
+	t.Run("replaces items", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(1, `{"Items":["pizza","water"]}`))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got, _ := store.GetTicketByID(1)
+		want := Items{{Name: "pizza", Quantity: 1, Unit: UNIT_EACH}, {Name: "water", Quantity: 1, Unit: UNIT_EACH}}
+		assertTicket(t, Ticket{Items: got.Items}, Ticket{Items: want})
+	})
+
+	t.Run("returns Bad Request when merged ticket is invalid", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(1, `{"Items":null}`))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+
+		got, _ := store.GetTicketByID(1)
+		assertTicket(t, got, newStore().tickets[0])
+	})
+
+	t.Run("returns Bad Request on fields that can't be patched", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(1, `{"Status":2}`))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("returns Unsupported Media Type without merge patch content type", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		request := newMergePatchRequest(1, `{"Notes":"extra pickles"}`)
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusUnsupportedMediaType)
+	})
+
+	t.Run("returns Not Found on nonexistant ticket ID", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newMergePatchRequest(2, `{"Notes":"extra pickles"}`))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func newMergePatchRequest(ticketID int, patch string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("/ticket/%d", ticketID), bytes.NewBufferString(patch))
+	req.Header.Set("Content-Type", mergePatchContentType)
+	return req
+}
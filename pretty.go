@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+type prettyWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (p *prettyWriter) WriteHeader(status int) {
+	if p.status == 0 {
+		p.status = status
+	}
+}
+
+func (p *prettyWriter) Write(data []byte) (int, error) {
+	if p.status == 0 {
+		p.status = http.StatusOK
+	}
+
+	return p.body.Write(data)
+}
+
+func (p *prettyWriter) Unwrap() http.ResponseWriter {
+	return p.ResponseWriter
+}
+
+func (p *prettyWriter) finish() {
+	if p.status == 0 {
+		return
+	}
+
+	body := p.body.Bytes()
+	indented := &bytes.Buffer{}
+	if json.Indent(indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+
+	p.ResponseWriter.WriteHeader(p.status)
+	p.ResponseWriter.Write(body)
+}
+
+func (k *KitchenServer) prettyResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !wantsPretty(r) || isStreamingRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		pretty := &prettyWriter{ResponseWriter: w}
+		next.ServeHTTP(pretty, r)
+		pretty.finish()
+	})
+}
+
+func wantsPretty(r *http.Request) bool {
+	if r.URL.Query().Get("pretty") == "true" {
+		return true
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && params["pretty"] == "true" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrettyResponses(t *testing.T) {
+	store := &StubKitchenStore{tickets: []Ticket{{ID: 1, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}}}
+
+	serve := func(t testing.TB, path, accept string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		request, _ := http.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		response := httptest.NewRecorder()
+		NewKitchenServer(store).ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("indents the response two spaces", func(t *testing.T) {
+		response := serve(t, "/ticket/1?pretty=true", "")
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if !strings.Contains(response.Body.String(), "{\n  \"ID\": 1,\n") {
+			t.Errorf("got body %q, want it indented", response.Body.String())
+		}
+	})
+
+	t.Run("indents errors", func(t *testing.T) {
+		response := serve(t, "/ticket/burger?pretty=true", "")
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		if !strings.Contains(response.Body.String(), "{\n  \"Code\": ") {
+			t.Errorf("got body %q, want it indented", response.Body.String())
+		}
+	})
+
+	t.Run("indents on an Accept hint, including envelopes", func(t *testing.T) {
+		response := serve(t, "/ticket/1", "application/json; pretty=true; envelope=true")
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if !strings.Contains(response.Body.String(), "{\n  \"data\": {\n    \"ID\": 1,\n") {
+			t.Errorf("got body %q, want it indented", response.Body.String())
+		}
+	})
+
+	t.Run("stays compact by default", func(t *testing.T) {
+		response := serve(t, "/ticket/1", "")
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if strings.Count(response.Body.String(), "\n") != 1 {
+			t.Errorf("got body %q, want it on one line", response.Body.String())
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package main
+
+import "time"
+
+const (
+	EVENT_CREATED       = "created"
+	EVENT_ACCEPTED      = "accepted"
+	EVENT_COMPLETED     = "completed"
+	EVENT_CANCELLED     = "cancelled"
+	EVENT_REOPENED      = "reopened"
+	EVENT_UPDATED       = "updated"
+	EVENT_SUBSTITUTED   = "substituted"
+	EVENT_BUMPED        = "bumped"
+	EVENT_DEMOTED       = "demoted"
+	EVENT_REDACTED      = "redacted"
+	EVENT_MERGED        = "merged"
+	EVENT_DELETED       = "deleted"
+	EVENT_RUSHED        = "rushed"
+	EVENT_UNRUSHED      = "unrushed"
+	EVENT_STARTING_SOON = "starting_soon"
+
+	EVENT_ACCEPT_SLA_BREACHED = "accept_sla_breached"
+)
+
+type TicketEvent struct {
+	Type       string
+	TicketID   int
+	Status     Status
+	Reason     string
+	OccurredAt time.Time
+	Rush       bool
+}
+
+type Publisher interface {
+	Publish(TicketEvent)
+}
+
+type NoopPublisher struct{}
+
+func (NoopPublisher) Publish(TicketEvent) {}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (k *KitchenServer) adjustItemQuantity(w http.ResponseWriter, r *http.Request, ticketID int, path string) {
+	stringIndex, direction, found := strings.Cut(path, "/")
+	index, err := strconv.Atoi(stringIndex)
+	if !found || err != nil || (direction != "increment" && direction != "decrement") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	store := k.storeFor(r)
+	ticket, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if index < 0 || index >= len(ticket.Items) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if ticket.Status != STATUS_PENDING {
+		k.writeError(w, http.StatusConflict, CODE_NOT_EDITABLE, fmt.Sprintf("can't change items on %v ticket", ticket.Status))
+		return
+	}
+
+	items, changed := k.stepQuantity(ticket.Items, index, direction == "increment")
+	if !changed {
+		k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+		return
+	}
+	ticket.Items = items
+
+	if err := k.checkTicket(ticket); err != nil {
+		k.writeValidationError(w, err)
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	ticket.UpdatedAt = now
+
+	err = k.updateTicket(store, ticket, TicketEvent{
+		Type:       EVENT_UPDATED,
+		TicketID:   ticketID,
+		Status:     ticket.Status,
+		OccurredAt: now,
+	})
+	if err != nil {
+		k.logger.Error("unable to change item quantity", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", ticketETag(ticket))
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+}
+
+func (k *KitchenServer) stepQuantity(items Items, index int, increment bool) (Items, bool) {
+	item := items[index]
+	switch {
+	case increment:
+		item.Quantity++
+	case item.Quantity > 1:
+		item.Quantity = max(item.Quantity-1, 1)
+	case k.removeItemsAtZero:
+		stepped := append(append(Items{}, items[:index]...), items[index+1:]...)
+		if len(stepped) == 0 {
+			stepped = nil
+		}
+		return stepped, true
+	default:
+		return items, false
+	}
+
+	item.Price = scalePrice(item.Price, items[index].Quantity, item.Quantity)
+
+	stepped := make(Items, len(items))
+	copy(stepped, items)
+	stepped[index] = item
+
+	return stepped, true
+}
+
+// scalePrice keeps an item's price a line total when its quantity moves from
+// one amount to another.
+func scalePrice(price *Money, from, to float64) *Money {
+	if price == nil || from == 0 {
+		return price
+	}
+
+	scaled := Money{Amount: int64(math.Round(float64(price.Amount) * to / from)), Currency: price.Currency}
+	return &scaled
+}
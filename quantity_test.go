@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestItemQuantity(t *testing.T) {
+	newStore := func(status Status) *StubKitchenStore {
+		return &StubKitchenStore{tickets: []Ticket{{ID: 1, Status: status, Items: Items{
+			{Name: "burger", Quantity: 1, Unit: UNIT_EACH},
+			{Name: "fries", Quantity: 2, Unit: UNIT_EACH},
+		}}}}
+	}
+
+	adjust := func(t testing.TB, server *KitchenServer, index int, direction string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/1/items/%d/%s", index, direction), nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("increments an item", func(t *testing.T) {
+		store := newStore(STATUS_PENDING)
+		server := NewKitchenServer(store)
+
+		response := adjust(t, server, 1, "increment")
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := getTicketFromResponse(t, response.Body)
+		assertItems(t, got.Items, Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}, {Name: "fries", Quantity: 3, Unit: UNIT_EACH}})
+		if len(store.events) != 1 || store.events[0].Type != EVENT_UPDATED {
+			t.Errorf("got events %v, want an update recorded", store.events)
+		}
+	})
+
+	t.Run("decrements an item", func(t *testing.T) {
+		store := newStore(STATUS_PENDING)
+		server := NewKitchenServer(store)
+
+		response := adjust(t, server, 1, "decrement")
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got, _ := store.GetTicketByID(1)
+		assertItems(t, got.Items, Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}, {Name: "fries", Quantity: 1, Unit: UNIT_EACH}})
+	})
+
+	t.Run("keeps a decremented item at one", func(t *testing.T) {
+		store := newStore(STATUS_PENDING)
+		server := NewKitchenServer(store)
+
+		response := adjust(t, server, 0, "decrement")
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got, _ := store.GetTicketByID(1)
+		assertItems(t, got.Items, Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}, {Name: "fries", Quantity: 2, Unit: UNIT_EACH}})
+		if len(store.events) != 0 {
+			t.Errorf("got events %v, want nothing recorded", store.events)
+		}
+	})
+
+	t.Run("removes an item decremented to zero when configured", func(t *testing.T) {
+		store := newStore(STATUS_PENDING)
+		server := NewKitchenServer(store, WithRemoveItemsAtZero(true))
+
+		response := adjust(t, server, 0, "decrement")
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got, _ := store.GetTicketByID(1)
+		assertItems(t, got.Items, Items{{Name: "fries", Quantity: 2, Unit: UNIT_EACH}})
+	})
+
+	t.Run("won't remove the last item", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 1, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}}}
+		server := NewKitchenServer(store, WithRemoveItemsAtZero(true))
+
+		response := adjust(t, server, 0, "decrement")
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_ITEMS_EMPTY)
+	})
+
+	t.Run("rejects changes once the ticket is accepted", func(t *testing.T) {
+		store := newStore(STATUS_ACCEPTED)
+		server := NewKitchenServer(store)
+
+		response := adjust(t, server, 1, "increment")
+
+		assertStatus(t, response.Code, http.StatusConflict)
+		assertErrorCode(t, response, CODE_NOT_EDITABLE)
+		got, _ := store.GetTicketByID(1)
+		if got.Items[1].Quantity != 2 {
+			t.Errorf("got quantity %v, want 2 kept", got.Items[1].Quantity)
+		}
+	})
+
+	t.Run("keeps the total in step with the quantity", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 1, Status: STATUS_PENDING, Items: Items{
+			{Name: "burger", Quantity: 1, Unit: UNIT_EACH, Price: &Money{Amount: 950, Currency: "GBP"}},
+			{Name: "fries", Quantity: 2, Unit: UNIT_EACH, Price: &Money{Amount: 600, Currency: "GBP"}},
+		}}}}
+		server := NewKitchenServer(store)
+
+		cases := []struct {
+			index     int
+			direction string
+			want      int64
+		}{
+			{1, "increment", 1850},
+			{0, "increment", 2800},
+			{1, "decrement", 2500},
+		}
+
+		for _, c := range cases {
+			response := adjust(t, server, c.index, c.direction)
+			assertStatus(t, response.Code, http.StatusOK)
+
+			got := TicketResponse{}
+			json.NewDecoder(response.Body).Decode(&got)
+			if got.Total == nil || got.Total.Amount != c.want {
+				t.Errorf("got total %v after %s of item %d, want %d", got.Total, c.direction, c.index, c.want)
+			}
+		}
+	})
+
+	t.Run("returns Not Found for an unknown item", func(t *testing.T) {
+		server := NewKitchenServer(newStore(STATUS_PENDING))
+
+		assertStatus(t, adjust(t, server, 5, "increment").Code, http.StatusNotFound)
+		assertStatus(t, adjust(t, server, 0, "double").Code, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const writeQueueRetryAfter = time.Second
+
+type writeQueue struct {
+	jobs chan func()
+}
+
+func ValidateWriteQueue(size, workers int) error {
+	if size > 0 && workers < 1 {
+		return fmt.Errorf("write queue needs at least one worker, got %d", workers)
+	}
+
+	return nil
+}
+
+func newWriteQueue(size, workers int) *writeQueue {
+	if workers < 1 {
+		panic(fmt.Sprintf("write queue needs at least one worker, got %d", workers))
+	}
+
+	q := &writeQueue{jobs: make(chan func(), size)}
+	for range workers {
+		go q.work()
+	}
+
+	return q
+}
+
+func (q *writeQueue) work() {
+	for job := range q.jobs {
+		job()
+	}
+}
+
+// do runs write on a worker and waits for it. It returns false when the queue
+// is full, or when ctx ends before a worker picked the write up, in which case
+// the write never runs.
+func (q *writeQueue) do(ctx context.Context, write func()) bool {
+	var started atomic.Bool
+	done := make(chan struct{})
+	job := func() {
+		defer close(done)
+		if started.CompareAndSwap(false, true) {
+			write()
+		}
+	}
+
+	select {
+	case q.jobs <- job:
+	default:
+		return false
+	}
+
+	select {
+	case <-done:
+		return started.Load()
+	case <-ctx.Done():
+		if started.CompareAndSwap(false, true) {
+			return false
+		}
+		<-done
+		return true
+	}
+}
+
+func (k *KitchenServer) doWrite(ctx context.Context, write func()) bool {
+	if k.writes == nil {
+		write()
+		return true
+	}
+
+	return k.writes.do(ctx, write)
+}
+
+func (k *KitchenServer) queueWrite(w http.ResponseWriter, r *http.Request, write func()) bool {
+	if !k.doWrite(r.Context(), write) {
+		k.logger.Warn("write queue full, rejecting request")
+		k.setRetryAfter(w, writeQueueRetryAfter)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return false
+	}
+
+	return true
+}
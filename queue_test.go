@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type BlockingKitchenStore struct {
+	KitchenStore
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *BlockingKitchenStore) StoreTicket(ticket Ticket) (int, error) {
+	b.started <- struct{}{}
+	<-b.release
+
+	return b.KitchenStore.StoreTicket(ticket)
+}
+
+func TestWriteQueue(t *testing.T) {
+	ticket := Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+
+	t.Run("stores tickets through the queue", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithWriteQueue(1, 1))
+
+		for range 3 {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newCreateTicketRequest(ticket))
+			assertStatus(t, response.Code, http.StatusAccepted)
+		}
+
+		if len(store.tickets) != 3 {
+			t.Errorf("got %d tickets stored, want 3", len(store.tickets))
+		}
+	})
+
+	t.Run("returns 503 when the queue is saturated", func(t *testing.T) {
+		store := &BlockingKitchenStore{
+			KitchenStore: NewInMemoryKitchenStore(),
+			started:      make(chan struct{}),
+			release:      make(chan struct{}),
+		}
+		server := NewKitchenServer(store, WithWriteQueue(1, 1))
+
+		responses := make([]*httptest.ResponseRecorder, 2)
+		var wg sync.WaitGroup
+		for i := range responses {
+			responses[i] = httptest.NewRecorder()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				server.ServeHTTP(responses[i], newCreateTicketRequest(ticket))
+			}()
+
+			if i == 0 {
+				<-store.started
+			}
+		}
+
+		for len(server.writes.jobs) < 1 {
+			time.Sleep(time.Millisecond)
+		}
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+		assertHeader(t, response, "Retry-After", "1")
+
+		go func() {
+			for range responses {
+				store.release <- struct{}{}
+			}
+		}()
+		go func() {
+			<-store.started
+		}()
+		wg.Wait()
+
+		for _, response := range responses {
+			assertStatus(t, response.Code, http.StatusAccepted)
+		}
+	})
+
+	t.Run("drops a queued write whose request was cancelled", func(t *testing.T) {
+		store := &BlockingKitchenStore{
+			KitchenStore: NewInMemoryKitchenStore(),
+			started:      make(chan struct{}),
+			release:      make(chan struct{}),
+		}
+		server := NewKitchenServer(store, WithWriteQueue(1, 1))
+
+		first := httptest.NewRecorder()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			server.ServeHTTP(first, newCreateTicketRequest(ticket))
+		}()
+		<-store.started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		second := httptest.NewRecorder()
+		cancelled := make(chan struct{})
+		go func() {
+			defer close(cancelled)
+			server.ServeHTTP(second, newCreateTicketRequest(ticket).WithContext(ctx))
+		}()
+		for len(server.writes.jobs) < 1 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		<-cancelled
+
+		store.release <- struct{}{}
+		wg.Wait()
+		server.writes.do(context.Background(), func() {})
+
+		assertStatus(t, first.Code, http.StatusAccepted)
+		assertStatus(t, second.Code, http.StatusServiceUnavailable)
+		if got, _ := store.CountTickets(TicketFilter{}); got != 1 {
+			t.Errorf("got %d tickets stored, want 1", got)
+		}
+	})
+}
+
+func TestValidateWriteQueue(t *testing.T) {
+	if err := ValidateWriteQueue(10, 0); err == nil {
+		t.Errorf("expected an error for a queue without workers but didn't get one")
+	}
+
+	if err := ValidateWriteQueue(10, 1); err != nil {
+		t.Errorf("got error %v, want none", err)
+	}
+
+	if err := ValidateWriteQueue(0, 0); err != nil {
+		t.Errorf("got error %v, want none for a disabled queue", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	readyzPath            = "/readyz"
+	defaultWarmUpTimeout  = 30 * time.Second
+	defaultWarmUpInterval = 100 * time.Millisecond
+)
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+func isProbePath(path string) bool {
+	return path == healthzPath || path == readyzPath
+}
+
+func (k *KitchenServer) WarmUp(ctx context.Context, timeout time.Duration) error {
+	if k.pinger == nil {
+		k.ready.Store(true)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(defaultWarmUpInterval)
+	defer ticker.Stop()
+
+	for {
+		err := k.pinger.Ping(ctx)
+		if err == nil {
+			k.ready.Store(true)
+			k.logger.Info("store warmed up")
+			return nil
+		}
+		k.logger.Info("waiting for store to warm up", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("store not ready after %v, %w", timeout, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (k *KitchenServer) serveReadiness(w http.ResponseWriter) {
+	if !k.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type WarmingKitchenStore struct {
+	KitchenStore
+	healthyAt time.Time
+	pings     atomic.Int32
+}
+
+func (w *WarmingKitchenStore) Ping(ctx context.Context) error {
+	w.pings.Add(1)
+	if time.Now().Before(w.healthyAt) {
+		return errors.New("pool still warming up")
+	}
+
+	return nil
+}
+
+func TestReadiness(t *testing.T) {
+	getReadiness := func(t testing.TB, server *KitchenServer) int {
+		t.Helper()
+
+		request, _ := http.NewRequest(http.MethodGet, readyzPath, nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response.Code
+	}
+
+	t.Run("reports ready once the store warms up", func(t *testing.T) {
+		store := &WarmingKitchenStore{KitchenStore: &StubKitchenStore{}, healthyAt: time.Now().Add(150 * time.Millisecond)}
+		server := NewKitchenServer(store)
+
+		assertStatus(t, getReadiness(t, server), http.StatusServiceUnavailable)
+
+		if err := server.WarmUp(context.Background(), time.Second); err != nil {
+			t.Fatalf("unable to warm up, %v", err)
+		}
+
+		assertStatus(t, getReadiness(t, server), http.StatusOK)
+		if store.pings.Load() < 2 {
+			t.Errorf("got %d pings, want the store pinged until healthy", store.pings.Load())
+		}
+	})
+
+	t.Run("stays unready when the store never warms up", func(t *testing.T) {
+		store := &WarmingKitchenStore{KitchenStore: &StubKitchenStore{}, healthyAt: time.Now().Add(time.Hour)}
+		server := NewKitchenServer(store)
+
+		if err := server.WarmUp(context.Background(), 50*time.Millisecond); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+
+		assertStatus(t, getReadiness(t, server), http.StatusServiceUnavailable)
+	})
+
+	t.Run("is ready straight away without a store to ping", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		if err := server.WarmUp(context.Background(), time.Second); err != nil {
+			t.Fatalf("unable to warm up, %v", err)
+		}
+
+		assertStatus(t, getReadiness(t, server), http.StatusOK)
+	})
+
+	t.Run("doesn't need an API key", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithAPIKeys(APIKey{Key: "secret", Role: ROLE_MANAGER}))
+		server.WarmUp(context.Background(), time.Second)
+
+		assertStatus(t, getReadiness(t, server), http.StatusOK)
+	})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const redactedText = "[redacted]"
+
+var (
+	emailPattern = regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s().-]{5,}\d`)
+)
+
+type noteRedactor struct {
+	words *regexp.Regexp
+}
+
+func newNoteRedactor(words []string) *noteRedactor {
+	n := &noteRedactor{}
+	if len(words) == 0 {
+		return n
+	}
+
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	n.words = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+
+	return n
+}
+
+func (n *noteRedactor) redact(note string) string {
+	note = emailPattern.ReplaceAllString(note, redactedText)
+	note = phonePattern.ReplaceAllString(note, redactedText)
+	if n.words != nil {
+		note = n.words.ReplaceAllString(note, redactedText)
+	}
+
+	return note
+}
+
+func (k *KitchenServer) redactNotes(notes string) string {
+	if k.redactor == nil {
+		return notes
+	}
+
+	return k.redactor.redact(notes)
+}
+
+func (k *KitchenServer) auditRedaction(ticket Ticket, original string, at time.Time) {
+	if !k.auditRedactions || ticket.Notes == original {
+		return
+	}
+
+	err := k.store.StoreTicketEvent(TicketEvent{
+		Type:       EVENT_REDACTED,
+		TicketID:   ticket.ID,
+		Status:     ticket.Status,
+		Reason:     original,
+		OccurredAt: at,
+	})
+	if err != nil {
+		k.logger.Error("unable to store original notes", "ticket_id", ticket.ID, "error", err)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNoteRedaction(t *testing.T) {
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	newTicket := func(notes string) Ticket {
+		return Ticket{Notes: notes, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+	}
+
+	t.Run("masks phone numbers", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithNoteRedaction())
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(newTicket("call me on +44 7700 900123 when ready")))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		assertNotes(t, store.tickets[0].Notes, "call me on [redacted] when ready")
+	})
+
+	t.Run("masks emails and configured words", func(t *testing.T) {
+		got := newNoteRedactor([]string{"darn"}).redact("Darn, email jo@example.com")
+		assertNotes(t, got, "[redacted], email [redacted]")
+	})
+
+	t.Run("leaves clean notes untouched", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithNoteRedaction("darn"), WithRedactionAudit(true))
+
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(newTicket("no onions, extra pickles")))
+
+		assertNotes(t, store.tickets[0].Notes, "no onions, extra pickles")
+		for _, event := range store.events {
+			if event.Type == EVENT_REDACTED {
+				t.Errorf("got redaction event %v for clean note", event)
+			}
+		}
+	})
+
+	t.Run("keeps the original note in history when audited", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithClock(clock), WithNoteRedaction(), WithRedactionAudit(true))
+
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(newTicket("ring 555-123-4567")))
+
+		events, _ := store.GetTicketEvents(0)
+		assertEvents(t, events, []TicketEvent{
+			{Type: EVENT_CREATED, TicketID: 0, Status: STATUS_PENDING, OccurredAt: clock.now},
+			{Type: EVENT_REDACTED, TicketID: 0, Status: STATUS_PENDING, Reason: "ring 555-123-4567", OccurredAt: clock.now},
+		})
+	})
+
+	t.Run("keeps notes when redaction is off", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(newTicket("ring 555-123-4567")))
+
+		assertNotes(t, store.tickets[0].Notes, "ring 555-123-4567")
+	})
+}
+
+func assertNotes(t testing.TB, got, want string) {
+	t.Helper()
+
+	if got != want {
+		t.Errorf("got notes %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import "net/http"
+
+type Reindexer interface {
+	Reindex() int
+}
+
+type ReindexResponse struct {
+	Tickets int
+}
+
+func (i *InMemoryKitchenStore) Reindex() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.reindex()
+}
+
+func (i *InMemoryKitchenStore) reindex() int {
+	i.byOrderID = map[string]int{}
+	i.byStatus = map[Status]map[int]bool{}
+	for _, ticket := range i.tickets {
+		i.indexStatus(ticket)
+		i.lastID = max(i.lastID, ticket.ID)
+		if ticket.OrderID == "" {
+			continue
+		}
+
+		if id, ok := i.byOrderID[orderKey(ticket)]; ok && !i.tickets[id].Deleted {
+			continue
+		}
+		i.byOrderID[orderKey(ticket)] = ticket.ID
+	}
+
+	return len(i.tickets)
+}
+
+func (s *ShardedKitchenStore) Reindex() int {
+	reindexed := 0
+	for _, shard := range s.shards {
+		if reindexer, ok := shard.(Reindexer); ok {
+			reindexed += reindexer.Reindex()
+		}
+	}
+
+	return reindexed
+}
+
+func (c *CachingKitchenStore) Reindex() int {
+	defer c.invalidateAll()
+
+	reindexer, ok := c.KitchenStore.(Reindexer)
+	if !ok {
+		return 0
+	}
+
+	return reindexer.Reindex()
+}
+
+func (k *KitchenServer) reindexStore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if k.reindexer == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	tickets := k.reindexer.Reindex()
+	k.logger.Info("store reindexed", "tickets", tickets)
+	k.writeJSON(w, http.StatusOK, ReindexResponse{Tickets: tickets})
+}
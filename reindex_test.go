@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReindex(t *testing.T) {
+	createdAt := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	newCorruptedStore := func() (*InMemoryKitchenStore, int) {
+		store := NewInMemoryKitchenStore()
+		id, _ := store.StoreTicket(Ticket{
+			OrderID:   "order-1",
+			Status:    STATUS_PENDING,
+			Items:     Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}},
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		})
+
+		store.byOrderID = map[string]int{}
+		store.byStatus = map[Status]map[int]bool{STATUS_COMPLETED: {id: true}}
+
+		return store, id
+	}
+
+	t.Run("rebuilds indexes from the primary map", func(t *testing.T) {
+		store, id := newCorruptedStore()
+
+		got := store.Reindex()
+		if got != 1 {
+			t.Errorf("got %d reindexed tickets, want 1", got)
+		}
+
+		tickets, _ := store.GetTickets(TicketFilter{Statuses: []Status{STATUS_PENDING}, Limit: 10})
+		if len(tickets) != 1 || tickets[0].ID != id {
+			t.Errorf("got pending tickets %v, want ticket %d", tickets, id)
+		}
+
+		tickets, _ = store.GetTickets(TicketFilter{Statuses: []Status{STATUS_COMPLETED}, Limit: 10})
+		if len(tickets) != 0 {
+			t.Errorf("got completed tickets %v, want none", tickets)
+		}
+
+		ticket, created, _ := store.StoreTicketIfNotExists(Ticket{OrderID: "order-1", Status: STATUS_PENDING})
+		if created || ticket.ID != id {
+			t.Errorf("got ticket %d created %v, want existing ticket %d", ticket.ID, created, id)
+		}
+	})
+
+	t.Run("is safe to call concurrently", func(t *testing.T) {
+		store, _ := newCorruptedStore()
+
+		wg := sync.WaitGroup{}
+		for range 10 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				store.Reindex()
+			}()
+			go func() {
+				defer wg.Done()
+				store.GetTickets(TicketFilter{Statuses: []Status{STATUS_PENDING}, Limit: 10})
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("reindexes through the admin endpoint", func(t *testing.T) {
+		store, id := newCorruptedStore()
+		server := NewKitchenServer(store, WithAdmin(true))
+
+		request, _ := http.NewRequest(http.MethodPost, "/admin/reindex", nil)
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := ReindexResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+		if got.Tickets != 1 {
+			t.Errorf("got %d reindexed tickets, want 1", got.Tickets)
+		}
+
+		tickets, _ := store.GetTickets(TicketFilter{Statuses: []Status{STATUS_PENDING}, Limit: 10})
+		if len(tickets) != 1 || tickets[0].ID != id {
+			t.Errorf("got pending tickets %v, want ticket %d", tickets, id)
+		}
+	})
+
+	t.Run("returns 501 when the store can't be reindexed", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithAdmin(true))
+
+		request, _ := http.NewRequest(http.MethodPost, "/admin/reindex", nil)
+		response := httptest.NewRecorder()
+		server.AdminHandler().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotImplemented)
+	})
+}
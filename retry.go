@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = 10 * time.Millisecond
+	maxRetryDelay         = time.Second
+)
+
+var ErrTransient = errors.New("transient store error")
+
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrTransient)
+}
+
+// retryingStore retries the idempotent store writes on transient errors.
+// Writes that would apply twice if the failed attempt had in fact landed,
+// such as StoreTicket or MoveTicket, go through once.
+type retryingStore struct {
+	KitchenStore
+	attempts  int
+	baseDelay time.Duration
+	ctx       context.Context
+}
+
+// withContext returns a copy of the store that stops backing off once ctx is
+// done.
+func (s *retryingStore) withContext(ctx context.Context) *retryingStore {
+	scoped := *s
+	scoped.ctx = ctx
+	return &scoped
+}
+
+func (s *retryingStore) StoreTicketIfNotExists(ticket Ticket) (Ticket, bool, error) {
+	var stored Ticket
+	var created bool
+	err := s.retry(func() (err error) {
+		stored, created, err = s.KitchenStore.StoreTicketIfNotExists(ticket)
+		return err
+	})
+
+	return stored, created, err
+}
+
+func (s *retryingStore) UpdateTicket(ticket Ticket) error {
+	return s.retry(func() error {
+		return s.KitchenStore.UpdateTicket(ticket)
+	})
+}
+
+func (s *retryingStore) UpdateTicketWithOutbox(ticket Ticket, event TicketEvent) error {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return errNoOutboxWriter
+	}
+
+	return writer.UpdateTicketWithOutbox(ticket, event)
+}
+
+func (s *retryingStore) StoreTicketWithOutbox(ticket Ticket, events []TicketEvent) (int, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return 0, errNoOutboxWriter
+	}
+
+	return writer.StoreTicketWithOutbox(ticket, events)
+}
+
+func (s *retryingStore) StoreTicketIfNotExistsWithOutbox(ticket Ticket, events []TicketEvent) (Ticket, bool, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, false, errNoOutboxWriter
+	}
+
+	return writer.StoreTicketIfNotExistsWithOutbox(ticket, events)
+}
+
+func (s *retryingStore) DeleteTicketWithOutbox(ticketID int, deletedAt time.Time, event TicketEvent) error {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return errNoOutboxWriter
+	}
+
+	return writer.DeleteTicketWithOutbox(ticketID, deletedAt, event)
+}
+
+func (s *retryingStore) ClaimNextTicketWithOutbox(filter TicketFilter, claim Claim, event TicketEvent) (Ticket, bool, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, false, errNoOutboxWriter
+	}
+
+	return writer.ClaimNextTicketWithOutbox(filter, claim, event)
+}
+
+func (s *retryingStore) MoveTicketWithOutbox(ticketID int, delta int, movedAt time.Time, event TicketEvent) (Ticket, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, errNoOutboxWriter
+	}
+
+	return writer.MoveTicketWithOutbox(ticketID, delta, movedAt, event)
+}
+
+func (s *retryingStore) ExpireTicketWithOutbox(ticketID int, now time.Time, event TicketEvent) (Ticket, bool, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, false, errNoOutboxWriter
+	}
+
+	return writer.ExpireTicketWithOutbox(ticketID, now, event)
+}
+
+func (s *retryingStore) DeleteTicket(ticketID int, deletedAt time.Time) error {
+	retrying := false
+	return s.retry(func() error {
+		// A transient failure may still have committed the delete, in which
+		// case the ticket is already gone and there's nothing left to retry.
+		if retrying {
+			if _, err := s.KitchenStore.GetTicketByID(ticketID); err != nil && !IsTransient(err) {
+				return nil
+			}
+		}
+		retrying = true
+
+		return s.KitchenStore.DeleteTicket(ticketID, deletedAt)
+	})
+}
+
+func (s *retryingStore) StoreTemplate(template Template) error {
+	return s.retry(func() error {
+		return s.KitchenStore.StoreTemplate(template)
+	})
+}
+
+func (s *retryingStore) DeleteTemplate(kitchenID, name string) error {
+	return s.retry(func() error {
+		return s.KitchenStore.DeleteTemplate(kitchenID, name)
+	})
+}
+
+func (s *retryingStore) SetMaintenance(maintenance Maintenance) error {
+	return s.retry(func() error {
+		return s.KitchenStore.SetMaintenance(maintenance)
+	})
+}
+
+func (s *retryingStore) retry(operation func() error) error {
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var err error
+	for attempt := 0; attempt < s.attempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff(s.baseDelay, attempt))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			}
+		}
+
+		err = operation()
+		if !IsTransient(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func backoff(baseDelay time.Duration, attempt int) time.Duration {
+	delay := min(baseDelay<<(attempt-1), maxRetryDelay)
+	return time.Duration(rand.Int64N(int64(delay) + 1))
+}
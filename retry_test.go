@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type FlakyKitchenStore struct {
+	*StubKitchenStore
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *FlakyKitchenStore) UpdateTicket(ticket Ticket) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+
+	return f.StubKitchenStore.UpdateTicket(ticket)
+}
+
+func (f *FlakyKitchenStore) StoreTicket(ticket Ticket) (int, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return 0, f.err
+	}
+
+	return f.StubKitchenStore.StoreTicket(ticket)
+}
+
+// LostAckKitchenStore deletes the ticket but reports a transient failure, as
+// when a commit succeeds and the connection drops before it's acknowledged.
+type LostAckKitchenStore struct {
+	*FlakyKitchenStore
+}
+
+func (l *LostAckKitchenStore) DeleteTicket(ticketID int, deletedAt time.Time) error {
+	l.calls++
+	err := l.StubKitchenStore.DeleteTicket(ticketID, deletedAt)
+	if l.calls <= l.failures {
+		return l.err
+	}
+
+	return err
+}
+
+func TestRetryTransientStoreErrors(t *testing.T) {
+	transient := fmt.Errorf("deadlock detected: %w", ErrTransient)
+	newStore := func(failures int, err error) *FlakyKitchenStore {
+		return &FlakyKitchenStore{
+			StubKitchenStore: &StubKitchenStore{tickets: []Ticket{{ID: 0, Status: STATUS_PENDING}}},
+			failures:         failures,
+			err:              err,
+		}
+	}
+
+	t.Run("retries transient errors until the write succeeds", func(t *testing.T) {
+		store := newStore(2, transient)
+		server := NewKitchenServer(store, WithRetry(3, time.Millisecond))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRushTicketRequest(0, true))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		if store.calls != 3 {
+			t.Errorf("got %d store calls, want 3", store.calls)
+		}
+		if !store.tickets[0].Rush {
+			t.Errorf("got ticket %v, want it rushed", store.tickets[0])
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		store := newStore(5, transient)
+		server := NewKitchenServer(store, WithRetry(3, time.Millisecond))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRushTicketRequest(0, true))
+
+		assertStatus(t, response.Code, http.StatusInternalServerError)
+		if store.calls != 3 {
+			t.Errorf("got %d store calls, want 3", store.calls)
+		}
+	})
+
+	t.Run("fails immediately on non-transient errors", func(t *testing.T) {
+		store := newStore(1, errors.New("constraint violation"))
+		server := NewKitchenServer(store, WithRetry(3, time.Millisecond))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRushTicketRequest(0, true))
+
+		assertStatus(t, response.Code, http.StatusInternalServerError)
+		if store.calls != 1 {
+			t.Errorf("got %d store calls, want 1", store.calls)
+		}
+	})
+
+	t.Run("doesn't retry creating a ticket", func(t *testing.T) {
+		store := newStore(1, transient)
+		server := NewKitchenServer(store, WithRetry(3, time.Millisecond))
+
+		request := newCreateTicketRequest(Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		if store.calls != 1 {
+			t.Errorf("got %d store calls, want 1", store.calls)
+		}
+	})
+
+	t.Run("treats a retried delete that finds the ticket gone as done", func(t *testing.T) {
+		store := &LostAckKitchenStore{newStore(1, transient)}
+		server := NewKitchenServer(store, WithRetry(3, time.Millisecond))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newDeleteTicketRequest(0, ""))
+
+		assertStatus(t, response.Code, http.StatusNoContent)
+		if store.calls != 1 {
+			t.Errorf("got %d delete calls, want 1", store.calls)
+		}
+	})
+
+	t.Run("stops backing off once the request is done", func(t *testing.T) {
+		store := newStore(5, transient)
+		server := NewKitchenServer(store, WithRetry(3, time.Hour))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		request := newRushTicketRequest(0, true).WithContext(ctx)
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			server.ServeHTTP(httptest.NewRecorder(), request)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("request still backing off after it was cancelled")
+		}
+		if store.calls != 1 {
+			t.Errorf("got %d store calls, want 1", store.calls)
+		}
+	})
+}
@@ -1,106 +1,632 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	STATUS_PENDING int = iota
-	STATUS_ACCEPTED
-	STATUS_COMPLETED
+	defaultPageLimit = 20
+	maxPageLimit     = 100
 )
 
-type Ticket struct {
-	ID     int
-	Status int
-	Items  []string
-}
-
 type CreateTicketResponse struct {
 	ID int
 }
 
+type TicketResponse struct {
+	Ticket
+	DisplayID string
+	Allergens []string
+	Total     *Money
+	TicketTotals
+	StepsDone  int
+	StepsTotal int
+	Stale      bool
+	Lingering  bool
+}
+
+type TicketPage struct {
+	Tickets      []Ticket
+	NextCursor   string
+	MaxUpdatedAt *time.Time
+	Lingering    []int
+}
+
 type KitchenStore interface {
 	GetTicketByID(int) (Ticket, error)
 	StoreTicket(Ticket) (int, error)
+	StoreTicketIfNotExists(Ticket) (Ticket, bool, error)
+	GetTickets(TicketFilter) ([]Ticket, error)
+	GetTicketsByIDs(ticketIDs []int) ([]Ticket, error)
+	StreamTickets(ctx context.Context, fn func(Ticket) error) error
+	CountTickets(TicketFilter) (int, error)
+	UpdateTicket(Ticket) error
+	DeleteTicket(ticketID int, deletedAt time.Time) error
+	RemoveTicket(ticketID int) error
+	StoreTicketEvent(TicketEvent) error
+	GetTicketEvents(ticketID int) ([]TicketEvent, error)
+	PurgeCompletedBefore(before, now time.Time) (int, error)
+	ClaimNextTicket(filter TicketFilter, claim Claim) (Ticket, bool, error)
+	MoveTicket(ticketID int, delta int, movedAt time.Time) (Ticket, error)
+	ExpireTicket(ticketID int, now time.Time) (Ticket, bool, error)
+	StoreTemplate(Template) error
+	GetTemplate(kitchenID, name string) (Template, error)
+	DeleteTemplate(kitchenID, name string) error
+	GetMaintenance() (Maintenance, error)
+	SetMaintenance(Maintenance) error
 }
 
 type KitchenServer struct {
-	store KitchenStore
+	store              KitchenStore
+	clock              Clock
+	logger             *slog.Logger
+	publisher          Publisher
+	limits             Limits
+	duplicateItems     DuplicateItems
+	defaultStatus      Status
+	avgPrepTime        time.Duration
+	minPrepTimes       map[string]time.Duration
+	defaultMinPrepTime time.Duration
+	requestTimeout     time.Duration
+	clockSkew          time.Duration
+	bodyLogging        *BodyLogging
+	bodyRedactor       *bodyRedactor
+	slowThreshold      time.Duration
+	retryAttempts      int
+	longPollTimeout    time.Duration
+	retryDelay         time.Duration
+	retryAfterHTTPDate bool
+	stringIDs          bool
+	adminEnabled       bool
+	envelope           bool
+	apiKeys            []APIKey
+	streamQueryToken   bool
+	streamOrigins      map[string]bool
+	events             *eventHub
+	kitchens           map[string]bool
+	writes             *writeQueue
+	redactor           *noteRedactor
+	itemAliases        map[string]string
+	blobs              BlobStore
+	stationRules       []StationRule
+	autoAcceptStations map[string]bool
+	stationCapacities  map[string]int
+	stations           map[string]bool
+	auditRedactions    bool
+	requireIfMatch     bool
+	removeItemsAtZero  bool
+	dedupWindow        time.Duration
+	dedup              *dedupCache
+	nonces             *nonceStore
+	idempotency        *idempotencyCache
+	idempotencyTTL     time.Duration
+	nonceTTL           time.Duration
+	startSoonLead      time.Duration
+	startSoon          *startSoonNotices
+	acceptSLA          time.Duration
+	acceptSLAAlerts    *acceptSLAAlerts
+	chaos              *ChaosConfig
+	idFormat           IDFormat
+	defaultVersion     string
+	timezone           *time.Location
+	staleAfter         time.Duration
+	completedLinger    time.Duration
+	features           map[string]bool
+	taxRate            TaxRate
+	taxCategories      map[string]TaxRate
+	pinger             Pinger
+	reindexer          Reindexer
+	importer           Importer
+	outboxEnabled      bool
+	outbox             OutboxStore
+	authorizer         Authorizer
+	ready              atomic.Bool
+	inFlight           chan struct{}
+	acquireTimeout     time.Duration
+	adminHandler       http.Handler
+	http.Handler
 }
 
-func (k *KitchenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+func (k *KitchenServer) route(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == healthzPath {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.URL.Path == readyzPath {
+		k.serveReadiness(w)
+		return
+	}
+
+	if k.rejectDuringMaintenance(w, r) {
+		return
+	}
+
+	r, ok := k.routeKitchen(w, r)
+	if !ok {
+		return
+	}
+
+	if k.rejectMethod(w, r) {
+		return
+	}
+
+	if r.Method == http.MethodHead && r.URL.Path == "/ticket/" {
+		k.countTickets(w, r)
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		w = headWriter{w}
+	}
+
+	if r.URL.Path == "/limits" {
+		k.getLimits(w, r)
+		return
+	}
+
+	if r.URL.Path == "/template" || strings.HasPrefix(r.URL.Path, "/template/") {
+		if !k.requireFeature(w, FEATURE_TEMPLATES) {
+			return
+		}
+		k.serveTemplates(w, r)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/ticket/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
 	switch r.Method {
-	case http.MethodGet:
-		k.getTicket(w, r)
+	case http.MethodGet, http.MethodHead:
+		switch r.URL.Path {
+		case "/ticket/":
+			k.listTickets(w, r)
+		case "/ticket/active":
+			k.listActiveTickets(w, r)
+		case "/ticket/completed":
+			k.listCompletedTickets(w, r)
+		case "/ticket/stale":
+			k.listStaleTickets(w, r)
+		case "/ticket/next":
+			k.nextTicket(w, r)
+		case "/ticket/stream":
+			if !k.requireFeature(w, FEATURE_STREAM) {
+				return
+			}
+			k.streamEvents(w, r)
+		default:
+			if strings.Contains(r.URL.Path, "/attachments/") {
+				k.getAttachment(w, r)
+				return
+			}
+			if strings.HasSuffix(r.URL.Path, "/history") {
+				k.getTicketHistory(w, r)
+				return
+			}
+			k.getTicket(w, r)
+		}
 	case http.MethodPost:
-		k.createTicket(w, r)
+		switch r.URL.Path {
+		case "/ticket/":
+			k.createTicket(w, r)
+		case "/ticket/estimate":
+			if !k.requireFeature(w, FEATURE_ESTIMATE) {
+				return
+			}
+			k.estimateTicket(w, r)
+		case "/ticket/batch":
+			if !k.requireFeature(w, FEATURE_BATCH) {
+				return
+			}
+			k.createTicketBatch(w, r)
+		default:
+			k.serveTicketAction(w, r)
+		}
+	case http.MethodPatch:
+		k.patchTicket(w, r)
+	case http.MethodDelete:
+		k.deleteTicket(w, r)
 	}
 }
 
 func (k *KitchenServer) getTicket(w http.ResponseWriter, r *http.Request) {
 	stringID := strings.TrimPrefix(r.URL.Path, "/ticket/")
-	ticketID, err := strconv.Atoi(stringID)
+	ticketID, ok := k.pathTicketID(w, stringID)
+	if !ok {
+		return
+	}
+
+	ticket, err := k.storeFor(r).GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("ETag", ticketETag(ticket))
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+}
+
+func (k *KitchenServer) newTicketResponse(ticket Ticket) TicketResponse {
+	total, _ := ticketTotal(ticket)
+	totals, _ := ticketTotals(ticket, k.taxRate, k.taxCategories)
+	done, steps := ticketStepProgress(ticket)
+
+	return TicketResponse{
+		Ticket:       ticket,
+		DisplayID:    k.idFormat.Format(ticket.ID),
+		Allergens:    ticketAllergens(ticket),
+		Total:        total,
+		TicketTotals: totals,
+		StepsDone:    done,
+		StepsTotal:   steps,
+		Stale:        k.isStale(ticket, k.clock.Now()),
+		Lingering:    k.isLingering(ticket, k.clock.Now()),
+	}
+}
+
+func (k *KitchenServer) listTickets(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("ids") {
+		k.getTicketsByIDs(w, r)
+		return
+	}
+
+	filter, err := getTicketFilter(r, k.limits.MaxPageLimit)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	ticket, err := k.store.GetTicketByID(ticketID)
+	k.serveTicketList(w, r, filter)
+}
+
+func (k *KitchenServer) serveTicketList(w http.ResponseWriter, r *http.Request, filter TicketFilter) {
+	k.resolveCursor(r, &filter)
+	if r.URL.Query().Get("scheduled") != "true" && !filter.IncludeDeleted {
+		filter.ActiveAt = k.clock.Now()
+	}
+
+	if r.URL.Query().Get("stream") == "true" {
+		k.streamTickets(w, r, filter)
+		return
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+	tickets, err := k.storeFor(r).GetTickets(filter)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
+		k.logger.Error("unable to list tickets", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	page := TicketPage{Tickets: tickets}
+	if len(tickets) > limit {
+		page.Tickets = tickets[:limit]
+		page.NextCursor = encodeCursor(page.Tickets[limit-1])
+	}
+
+	if filter.IncludeDeleted {
+		page.MaxUpdatedAt = maxUpdatedAt(page.Tickets, filter.UpdatedAfter)
+	}
+
+	page.Lingering = k.lingeringIDs(page.Tickets)
+
+	if r.URL.Query().Get("sortItems") == "true" {
+		sortTicketItems(page.Tickets, stringLess(r))
+	}
+
+	if acceptsCSV(r) {
+		k.writeTicketsCSV(w, page.Tickets)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, page)
+}
+
+func (k *KitchenServer) countTickets(w http.ResponseWriter, r *http.Request) {
+	filter, err := getTicketFilter(r, k.limits.MaxPageLimit)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
+	k.resolveCursor(r, &filter)
+
+	count, err := k.storeFor(r).CountTickets(filter)
+	if err != nil {
+		k.logger.Error("unable to count tickets", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(count))
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(ticket)
 }
 
 func (k *KitchenServer) createTicket(w http.ResponseWriter, r *http.Request) {
-	ticket, err := getTicketFromRequestBody(r.Body)
+	var reservation *idempotencyEntry
+	if idempotencyKey, payloadHash, idempotent := k.idempotencyKey(r); idempotent {
+		var served bool
+		reservation, served = k.serveIdempotentReplay(w, r, idempotencyKey, payloadHash)
+		if served {
+			return
+		}
+		defer func() {
+			if reservation != nil {
+				k.idempotency.release(reservation)
+			}
+		}()
+	}
+
+	dedupKey, dedup, err := k.dedupKey(w, r)
+	if err != nil {
+		k.writeError(w, http.StatusRequestEntityTooLarge, CODE_BODY_TOO_LARGE, err.Error())
+		return
+	}
+	if dedup && k.serveDuplicate(w, r, dedupKey) {
+		return
+	}
+
+	ticket, err := k.getTicketFromRequest(r)
+	if err != nil {
+		k.writeValidationError(w, err)
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_CREATE, *ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	original := k.stampNewTicket(k.storeFor(r), ticket)
+
+	if r.URL.Query().Get("ifNotExists") == "true" {
+		k.createTicketIfNotExists(w, r, *ticket, original)
+		return
+	}
+
+	var id int
+	if !k.queueWrite(w, r, func() { id, err = k.storeTicket(k.storeFor(r), *ticket) }) {
+		return
+	}
 	if err != nil {
+		k.logger.Error("unable to store ticket", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ticket.ID = id
+	k.auditRedaction(*ticket, original, ticket.CreatedAt)
+	if dedup {
+		k.dedup.record(dedupKey, id, ticket.CreatedAt, k.dedupWindow)
+	}
+	if reservation != nil {
+		k.idempotency.complete(reservation, id)
+		reservation = nil
+	}
+
+	k.writeJSON(w, http.StatusAccepted, CreateTicketResponse{ID: id})
+}
+
+func (k *KitchenServer) stampNewTicket(store KitchenStore, ticket *Ticket) string {
+	now := k.clock.Now()
+	ticket.Status = k.defaultStatus
+	ticket.CreatedAt = now
+	ticket.UpdatedAt = now
+	if ticket.Station == "" {
+		ticket.Station = k.defaultStation(now)
+	}
+	if ticket.Status == STATUS_PENDING && k.autoAccepts(*ticket) {
+		ticket.Status = STATUS_ACCEPTED
+	}
+	if ticket.Status == STATUS_ACCEPTED {
+		if incomplete, err := incompleteDependencies(store, *ticket); err != nil || len(incomplete) > 0 {
+			ticket.Status = STATUS_PENDING
+		}
+	}
+	if ticket.Status == STATUS_ACCEPTED {
+		if _, full, err := k.stationFull(store, ticket.Station); err != nil || full {
+			ticket.Status = STATUS_PENDING
+		}
+	}
+
+	original := ticket.Notes
+	ticket.Notes = k.redactNotes(ticket.Notes)
+
+	return original
+}
+
+func (k *KitchenServer) createTicketIfNotExists(w http.ResponseWriter, r *http.Request, ticket Ticket, original string) {
+	if ticket.OrderID == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	ticket.Status = STATUS_PENDING
-	id, err := k.store.StoreTicket(*ticket)
+	var stored Ticket
+	var created bool
+	var err error
+	if !k.queueWrite(w, r, func() { stored, created, err = k.storeTicketIfNotExists(k.storeFor(r), ticket) }) {
+		return
+	}
 	if err != nil {
+		k.logger.Error("unable to store ticket", "order_id", ticket.OrderID, "error", err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(CreateTicketResponse{ID: id})
+	if !created {
+		k.writeJSON(w, http.StatusOK, k.newTicketResponse(stored))
+		return
+	}
+
+	k.auditRedaction(stored, original, stored.CreatedAt)
+
+	k.writeJSON(w, http.StatusCreated, CreateTicketResponse{ID: stored.ID})
+}
+
+func (k *KitchenServer) recordCreated(id int, ticket Ticket) {
+	for _, event := range k.createdEvents(id, ticket) {
+		k.recordEvent(event)
+	}
+}
+
+func (k *KitchenServer) createdEvents(id int, ticket Ticket) []TicketEvent {
+	events := []TicketEvent{{
+		Type:       EVENT_CREATED,
+		TicketID:   id,
+		Status:     ticket.Status,
+		OccurredAt: ticket.CreatedAt,
+	}}
+
+	if ticket.Status == STATUS_ACCEPTED && k.autoAccepts(ticket) {
+		events = append(events, TicketEvent{
+			Type:       EVENT_ACCEPTED,
+			TicketID:   id,
+			Status:     ticket.Status,
+			Reason:     autoAcceptedReason,
+			OccurredAt: ticket.CreatedAt,
+		})
+	}
+
+	return events
 }
 
-func getTicketFromRequestBody(body io.Reader) (*Ticket, error) {
-	d := json.NewDecoder(body)
-	d.DisallowUnknownFields()
+func (k *KitchenServer) recordEvent(event TicketEvent) {
+	if k.outbox != nil {
+		err := k.outbox.StoreTicketEventWithOutbox(event)
+		if err == nil {
+			k.events.Publish(event)
+			return
+		}
+		k.logger.Error("unable to store ticket event in outbox", "type", event.Type, "ticket_id", event.TicketID, "error", err)
+	}
+
+	err := k.store.StoreTicketEvent(event)
+	if err != nil {
+		k.logger.Error("unable to store ticket event", "type", event.Type, "ticket_id", event.TicketID, "error", err)
+	}
+
+	k.publisher.Publish(event)
+	k.events.Publish(event)
+}
 
+func (k *KitchenServer) getTicketFromRequest(r *http.Request) (*Ticket, error) {
 	ticket := Ticket{}
-	err := d.Decode(&ticket)
+	if name := r.URL.Query().Get("fromTemplate"); name != "" {
+		template, err := k.storeFor(r).GetTemplate("", name)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q", errTemplateNotFound, name)
+		}
+		ticket.Items = template.Items
+	}
+
+	parsed, err := k.parseTicket(r.Body, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := k.checkDependencies(k.storeFor(r), newTicketID, parsed.DependsOn); err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
+
+func (k *KitchenServer) parseTicket(body io.Reader, prefill Ticket) (*Ticket, error) {
+	ticket, err := k.getTicketFromRequestBody(body, prefill)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket.Items, err = k.handleDuplicateItems(ticket.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := k.clock.Now().Add(-k.clockSkew)
+	if ticket.ExpiresAt != nil && !ticket.ExpiresAt.After(cutoff) {
+		return nil, newValidationError(CODE_INVALID_EXPIRY, "ticket expires at %v, which has already passed", ticket.ExpiresAt)
+	}
+
+	if ticket.ScheduledFor != nil && ticket.ScheduledFor.Before(cutoff) {
+		return nil, newValidationError(CODE_INVALID_SCHEDULE, "ticket is scheduled for %v, which has already passed", ticket.ScheduledFor)
+	}
+
+	if ticket.ExpiresAt != nil && ticket.ScheduledFor != nil && !ticket.ExpiresAt.After(*ticket.ScheduledFor) {
+		return nil, newValidationError(CODE_INVALID_EXPIRY, "ticket expires at %v, before it is scheduled for %v", ticket.ExpiresAt, ticket.ScheduledFor)
+	}
+
+	return ticket, nil
+}
+
+var errFutureTimestamp = errors.New("timestamp is in the future")
+
+func (k *KitchenServer) checkNotFuture(field string, t time.Time) error {
+	if limit := k.clock.Now().Add(k.clockSkew); t.After(limit) {
+		return fmt.Errorf("%w, %s %v is after %v", errFutureTimestamp, field, t, limit)
+	}
+
+	return nil
+}
+
+func (k *KitchenServer) getTicketFromRequestBody(body io.Reader, ticket Ticket) (*Ticket, error) {
+	err := decodeRequestBody(body, &ticket)
+	if errors.Is(err, errEmptyBody) {
+		return nil, newValidationError(CODE_BODY_REQUIRED, "%v", err)
+	}
 
 	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal ticket JSON, %v", err)
+		return nil, newValidationError(CODE_INVALID_JSON, "unable to unmarshal ticket JSON, %v", err)
 	}
 
-	if !isTicketValid(ticket) {
-		return nil, fmt.Errorf("some fields of ticket JSON are empty, cannot persist")
+	k.canonicalizeItemNames(ticket.Items)
+
+	if err := k.checkTicket(ticket); err != nil {
+		return nil, err
 	}
 
 	return &ticket, nil
 }
 
-func isTicketValid(ticket Ticket) bool {
-	if ticket.Items == nil {
-		return false
+func (k *KitchenServer) checkTicket(ticket Ticket) error {
+	if err := validateTicket(ticket, k.limits); err != nil {
+		return err
+	}
+
+	if err := k.checkTaxCategories(ticket); err != nil {
+		return err
+	}
+
+	return k.checkStationOverrides(ticket)
+}
+
+func (k *KitchenServer) setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Retry-After", retryAfterValue(d, k.retryAfterHTTPDate, k.clock.Now()))
+}
+
+func retryAfterValue(d time.Duration, httpDate bool, now time.Time) string {
+	if d < 0 {
+		d = 0
+	}
+
+	if httpDate {
+		return now.Add(d).UTC().Format(http.TimeFormat)
 	}
 
-	return true
+	seconds := (d + time.Second - 1) / time.Second
+	return strconv.Itoa(int(seconds))
 }
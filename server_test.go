@@ -2,17 +2,24 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type StubKitchenStore struct {
-	tickets []Ticket
+	tickets     []Ticket
+	events      []TicketEvent
+	templates   []Template
+	maintenance Maintenance
 }
 
 func (s *StubKitchenStore) GetTicketByID(ticketID int) (Ticket, error) {
@@ -32,22 +39,320 @@ func (s *StubKitchenStore) StoreTicket(ticket Ticket) (int, error) {
 	return ticket.ID, nil
 }
 
+func (s *StubKitchenStore) StoreTicketIfNotExists(ticket Ticket) (Ticket, bool, error) {
+	for _, existing := range s.tickets {
+		if existing.KitchenID == ticket.KitchenID && existing.OrderID == ticket.OrderID {
+			return existing, false, nil
+		}
+	}
+
+	ticket.ID, _ = s.StoreTicket(ticket)
+	return ticket, true, nil
+}
+
+func (s *StubKitchenStore) UpdateTicket(ticket Ticket) error {
+	for i := range s.tickets {
+		if s.tickets[i].ID == ticket.ID {
+			s.tickets[i] = ticket
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no ticket with ID = %d", ticket.ID)
+}
+
+func (s *StubKitchenStore) DeleteTicket(ticketID int, deletedAt time.Time) error {
+	for i := range s.tickets {
+		if s.tickets[i].ID == ticketID {
+			s.tickets = append(s.tickets[:i], s.tickets[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no ticket with ID = %d", ticketID)
+}
+
+func (s *StubKitchenStore) RemoveTicket(ticketID int) error {
+	return s.DeleteTicket(ticketID, time.Time{})
+}
+
+func (s *StubKitchenStore) StoreTicketEvent(event TicketEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *StubKitchenStore) GetTicketEvents(ticketID int) ([]TicketEvent, error) {
+	events := []TicketEvent{}
+	for _, event := range s.events {
+		if event.TicketID == ticketID {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (s *StubKitchenStore) PurgeCompletedBefore(before, now time.Time) (int, error) {
+	kept := []Ticket{}
+	for _, ticket := range s.tickets {
+		if ticket.Status != STATUS_COMPLETED || !ticket.UpdatedAt.Before(before) {
+			kept = append(kept, ticket)
+		}
+	}
+
+	removed := len(s.tickets) - len(kept)
+	s.tickets = kept
+
+	return removed, nil
+}
+
+func (s *StubKitchenStore) GetTickets(filter TicketFilter) ([]Ticket, error) {
+	tickets := []Ticket{}
+	for _, ticket := range s.tickets {
+		if len(tickets) == filter.Limit {
+			break
+		}
+
+		if filter.Matches(ticket) {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	return tickets, nil
+}
+
+func (s *StubKitchenStore) GetTicketsByIDs(ticketIDs []int) ([]Ticket, error) {
+	tickets := []Ticket{}
+	for _, id := range ticketIDs {
+		if ticket, err := s.GetTicketByID(id); err == nil {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	return tickets, nil
+}
+
+func (s *StubKitchenStore) StreamTickets(ctx context.Context, fn func(Ticket) error) error {
+	for _, ticket := range s.tickets {
+		if err := fn(ticket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *StubKitchenStore) CountTickets(filter TicketFilter) (int, error) {
+	count := 0
+	for _, ticket := range s.tickets {
+		if filter.Matches(ticket) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (s *StubKitchenStore) ClaimNextTicket(filter TicketFilter, claim Claim) (Ticket, bool, error) {
+	for i, ticket := range s.tickets {
+		if (claim.TicketID == 0 || ticket.ID == claim.TicketID) && filter.Matches(ticket) {
+			s.tickets[i].Status = STATUS_ACCEPTED
+			s.tickets[i].ClaimedBy = claim.By
+			s.tickets[i].UpdatedAt = claim.At
+			return s.tickets[i], true, nil
+		}
+	}
+
+	return Ticket{}, false, nil
+}
+
+func (s *StubKitchenStore) MoveTicket(ticketID int, delta int, movedAt time.Time) (Ticket, error) {
+	for i := range s.tickets {
+		if s.tickets[i].ID == ticketID {
+			s.tickets[i].QueueRank += delta
+			s.tickets[i].UpdatedAt = movedAt
+			return s.tickets[i], nil
+		}
+	}
+
+	return Ticket{}, fmt.Errorf("no ticket with ID = %d", ticketID)
+}
+
+func (s *StubKitchenStore) ExpireTicket(ticketID int, now time.Time) (Ticket, bool, error) {
+	for i := range s.tickets {
+		if s.tickets[i].ID == ticketID {
+			if !containsStatus(activeStatuses, s.tickets[i].Status) || !isExpired(s.tickets[i], now) {
+				return s.tickets[i], false, nil
+			}
+			s.tickets[i].Status = STATUS_CANCELLED
+			s.tickets[i].UpdatedAt = now
+			return s.tickets[i], true, nil
+		}
+	}
+
+	return Ticket{}, false, fmt.Errorf("no ticket with ID = %d", ticketID)
+}
+
+func (s *StubKitchenStore) StoreTemplate(template Template) error {
+	s.DeleteTemplate(template.KitchenID, template.Name)
+	s.templates = append(s.templates, template)
+	return nil
+}
+
+func (s *StubKitchenStore) GetTemplate(kitchenID, name string) (Template, error) {
+	for _, template := range s.templates {
+		if template.KitchenID == kitchenID && template.Name == name {
+			return template, nil
+		}
+	}
+
+	return Template{}, fmt.Errorf("no template named %q", name)
+}
+
+func (s *StubKitchenStore) DeleteTemplate(kitchenID, name string) error {
+	for i, template := range s.templates {
+		if template.KitchenID == kitchenID && template.Name == name {
+			s.templates = append(s.templates[:i], s.templates[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no template named %q", name)
+}
+
+func (s *StubKitchenStore) GetMaintenance() (Maintenance, error) {
+	return s.maintenance, nil
+}
+
+func (s *StubKitchenStore) SetMaintenance(maintenance Maintenance) error {
+	s.maintenance = maintenance
+	return nil
+}
+
+var errStoreUnavailable = errors.New("store unavailable")
+
+type FailingKitchenStore struct{}
+
+func (f *FailingKitchenStore) GetTicketByID(int) (Ticket, error) {
+	return Ticket{}, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) StoreTicket(Ticket) (int, error) {
+	return 0, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) GetTickets(TicketFilter) ([]Ticket, error) {
+	return nil, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) GetTicketsByIDs([]int) ([]Ticket, error) {
+	return nil, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) StreamTickets(context.Context, func(Ticket) error) error {
+	return errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) CountTickets(TicketFilter) (int, error) {
+	return 0, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) StoreTicketIfNotExists(Ticket) (Ticket, bool, error) {
+	return Ticket{}, false, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) UpdateTicket(Ticket) error {
+	return errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) DeleteTicket(int, time.Time) error {
+	return errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) RemoveTicket(int) error {
+	return errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) StoreTicketEvent(TicketEvent) error {
+	return errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) GetTicketEvents(int) ([]TicketEvent, error) {
+	return nil, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) PurgeCompletedBefore(time.Time, time.Time) (int, error) {
+	return 0, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) ClaimNextTicket(TicketFilter, Claim) (Ticket, bool, error) {
+	return Ticket{}, false, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) MoveTicket(int, int, time.Time) (Ticket, error) {
+	return Ticket{}, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) ExpireTicket(int, time.Time) (Ticket, bool, error) {
+	return Ticket{}, false, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) StoreTemplate(Template) error {
+	return errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) GetTemplate(string, string) (Template, error) {
+	return Template{}, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) DeleteTemplate(string, string) error {
+	return errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) GetMaintenance() (Maintenance, error) {
+	return Maintenance{}, errStoreUnavailable
+}
+
+func (f *FailingKitchenStore) SetMaintenance(Maintenance) error {
+	return errStoreUnavailable
+}
+
+type StubPublisher struct {
+	events []TicketEvent
+}
+
+func (s *StubPublisher) Publish(event TicketEvent) {
+	s.events = append(s.events, event)
+}
+
+type StubClock struct {
+	now time.Time
+}
+
+func (s *StubClock) Now() time.Time {
+	return s.now
+}
+
+func (s *StubClock) Advance(d time.Duration) {
+	s.now = s.now.Add(d)
+}
+
 func TestGETTicket(t *testing.T) {
 	store := &StubKitchenStore{
-		[]Ticket{
+		tickets: []Ticket{
 			{
 				ID:     1,
 				Status: STATUS_ACCEPTED,
-				Items:  []string{"burger", "fries"},
+				Items:  []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}, {Name: "fries", Quantity: 1, Unit: UNIT_EACH}},
 			},
 			{
 				ID:     2,
 				Status: STATUS_PENDING,
-				Items:  []string{"pizza", "water"},
+				Items:  []Item{{Name: "pizza", Quantity: 1, Unit: UNIT_EACH}, {Name: "water", Quantity: 1, Unit: UNIT_EACH}},
 			},
 		},
 	}
-	server := KitchenServer{store}
+	server := NewKitchenServer(store)
 	t.Run("returns OK on valid ticket ID", func(t *testing.T) {
 		request := newGetTicketRequest(1)
 		response := httptest.NewRecorder()
@@ -101,10 +406,11 @@ func TestGETTicket(t *testing.T) {
 
 func TestCreateTicket(t *testing.T) {
 	store := &StubKitchenStore{}
-	server := KitchenServer{store}
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	server := NewKitchenServer(store, WithClock(clock))
 	t.Run("returns Accepted on valid ticket JSON", func(t *testing.T) {
 		ticket := Ticket{
-			Items: []string{"burger", "fries"},
+			Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}, {Name: "fries", Quantity: 1, Unit: UNIT_EACH}},
 		}
 
 		request := newCreateTicketRequest(ticket)
@@ -127,9 +433,41 @@ func TestCreateTicket(t *testing.T) {
 		assertStatus(t, response.Code, http.StatusBadRequest)
 	})
 
+	t.Run("stores the same ticket for items as array or comma separated string", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithClock(clock))
+
+		for _, body := range []string{`{"Items":["burger","fries"]}`, `{"Items":"burger,fries"}`} {
+			request, _ := http.NewRequest(http.MethodPost, "/ticket/", bytes.NewBufferString(body))
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusAccepted)
+		}
+
+		fromArray, _ := store.GetTicketByID(0)
+		fromString, _ := store.GetTicketByID(1)
+		fromString.ID = fromArray.ID
+
+		assertTicket(t, fromString, fromArray)
+	})
+
+	t.Run("returns Bad Request on fractional quantity of each item", func(t *testing.T) {
+		ticket := Ticket{
+			Items: []Item{{Name: "burger", Quantity: 1.5, Unit: UNIT_EACH}},
+		}
+
+		request := newCreateTicketRequest(ticket)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
 	t.Run("returns ticket ID on valid ticket JSON", func(t *testing.T) {
 		ticket := Ticket{
-			Items: []string{"burger", "fries"},
+			Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}, {Name: "fries", Quantity: 1, Unit: UNIT_EACH}},
 		}
 
 		request := newCreateTicketRequest(ticket)
@@ -143,7 +481,7 @@ func TestCreateTicket(t *testing.T) {
 
 	t.Run("persists ticket and sets status to STATUS_PENDING", func(t *testing.T) {
 		ticket := Ticket{
-			Items: []string{"pizza", "water"},
+			Items: []Item{{Name: "pizza", Quantity: 1, Unit: UNIT_EACH}, {Name: "water", Quantity: 1, Unit: UNIT_EACH}},
 		}
 
 		request := newCreateTicketRequest(ticket)
@@ -155,14 +493,398 @@ func TestCreateTicket(t *testing.T) {
 		assertTicketResponse(t, response.Body, CreateTicketResponse{ID: 2})
 
 		want := Ticket{
-			ID:     2,
-			Status: STATUS_PENDING,
-			Items:  ticket.Items,
+			ID:        2,
+			Status:    STATUS_PENDING,
+			Items:     ticket.Items,
+			CreatedAt: clock.now,
+			UpdatedAt: clock.now,
 		}
 		assertTicketPersisted(t, store, want)
 	})
 }
 
+func TestCreateTicketIfNotExists(t *testing.T) {
+	ticket := Ticket{
+		OrderID: "order-42",
+		Items:   Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}},
+	}
+
+	t.Run("creates ticket on first submission", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		request := newPostTicketRequest("/ticket/?ifNotExists=true", ticket)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusCreated)
+		assertTicketResponse(t, response.Body, CreateTicketResponse{ID: 0})
+
+		if len(store.tickets) != 1 {
+			t.Errorf("got %d tickets, want 1", len(store.tickets))
+		}
+	})
+
+	t.Run("returns existing ticket on retried submission", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+		server := NewKitchenServer(store, WithClock(clock))
+
+		server.ServeHTTP(httptest.NewRecorder(), newPostTicketRequest("/ticket/?ifNotExists=true", ticket))
+
+		request := newPostTicketRequest("/ticket/?ifNotExists=true", ticket)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := getTicketFromResponse(t, response.Body)
+		assertTicket(t, got, store.tickets[0])
+
+		if len(store.tickets) != 1 {
+			t.Errorf("got %d tickets, want retry to be deduplicated", len(store.tickets))
+		}
+	})
+
+	t.Run("creates duplicate when not opted in", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(ticket))
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(ticket))
+
+		if len(store.tickets) != 2 {
+			t.Errorf("got %d tickets, want 2", len(store.tickets))
+		}
+	})
+
+	t.Run("returns Bad Request without order ID", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		request := newPostTicketRequest("/ticket/?ifNotExists=true", Ticket{Items: ticket.Items})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestPublishTicketEvents(t *testing.T) {
+	t.Run("publishes created events in order after storing tickets", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		publisher := &StubPublisher{}
+		clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+		server := NewKitchenServer(store, WithPublisher(publisher), WithClock(clock))
+
+		for _, name := range []string{"burger", "pizza"} {
+			ticket := Ticket{Items: []Item{{Name: name, Quantity: 1, Unit: UNIT_EACH}}}
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+			assertStatus(t, response.Code, http.StatusAccepted)
+		}
+
+		want := []TicketEvent{
+			{Type: EVENT_CREATED, TicketID: 0, Status: STATUS_PENDING, OccurredAt: clock.now},
+			{Type: EVENT_CREATED, TicketID: 1, Status: STATUS_PENDING, OccurredAt: clock.now},
+		}
+		assertEvents(t, publisher.events, want)
+	})
+
+	t.Run("doesn't publish when store write fails", func(t *testing.T) {
+		publisher := &StubPublisher{}
+		server := NewKitchenServer(&FailingKitchenStore{}, WithPublisher(publisher))
+
+		ticket := Ticket{Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertEvents(t, publisher.events, nil)
+	})
+}
+
+func assertEvents(t testing.TB, got, want []TicketEvent) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
+
+func TestListTickets(t *testing.T) {
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_PENDING, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}},
+				{ID: 2, Status: STATUS_ACCEPTED, Items: []Item{{Name: "fries", Quantity: 1, Unit: UNIT_EACH}}},
+				{ID: 3, Status: STATUS_PENDING, Items: []Item{{Name: "pizza", Quantity: 1, Unit: UNIT_EACH}}},
+			},
+		}
+	}
+
+	t.Run("returns first page and next cursor", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store)
+
+		request := newListTicketsRequest("?limit=2")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := getTicketPageFromResponse(t, response.Body)
+		assertTickets(t, got.Tickets, store.tickets[:2])
+		assertNextCursor(t, got.NextCursor, encodeCursor(store.tickets[1]))
+	})
+
+	t.Run("returns tickets after cursor and no next cursor on last page", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store)
+
+		request := newListTicketsRequest("?after=" + encodeCursor(store.tickets[1]) + "&limit=2")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := getTicketPageFromResponse(t, response.Body)
+		assertTickets(t, got.Tickets, store.tickets[2:])
+		assertNextCursor(t, got.NextCursor, "")
+	})
+
+	t.Run("doesn't duplicate or skip tickets inserted between pages", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store)
+
+		request := newListTicketsRequest("?limit=2")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		firstPage := getTicketPageFromResponse(t, response.Body)
+
+		store.tickets = append(store.tickets, Ticket{ID: 4, Status: STATUS_PENDING, Items: []Item{{Name: "water", Quantity: 1, Unit: UNIT_EACH}}})
+
+		request = newListTicketsRequest("?after=" + firstPage.NextCursor + "&limit=2")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		secondPage := getTicketPageFromResponse(t, response.Body)
+
+		got := append(firstPage.Tickets, secondPage.Tickets...)
+		assertTickets(t, got, store.tickets)
+		assertNextCursor(t, secondPage.NextCursor, "")
+	})
+
+	t.Run("accepts a ticket ID as the cursor", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store)
+
+		request := newListTicketsRequest("?after=2&limit=2")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertTickets(t, getTicketPageFromResponse(t, response.Body).Tickets, store.tickets[2:])
+	})
+
+	t.Run("returns Bad Request on invalid cursor", func(t *testing.T) {
+		server := NewKitchenServer(newStore())
+
+		request := newListTicketsRequest("?after=abc")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("returns Bad Request on malformed page cursor", func(t *testing.T) {
+		server := NewKitchenServer(newStore())
+
+		for _, cursor := range []string{encodeCursor(Ticket{}), base64.RawURLEncoding.EncodeToString([]byte("0:maybe:2"))} {
+			request := newListTicketsRequest("?after=" + cursor)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("keeps its place when the cursor ticket moves between pages", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		for _, ticket := range newStore().tickets {
+			store.StoreTicket(ticket)
+		}
+		server := NewKitchenServer(store)
+
+		request := newListTicketsRequest("?limit=2")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		firstPage := getTicketPageFromResponse(t, response.Body)
+
+		store.MoveTicket(2, -5, time.Now())
+
+		request = newListTicketsRequest("?after=" + firstPage.NextCursor + "&limit=2")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		secondPage := getTicketPageFromResponse(t, response.Body)
+
+		if len(secondPage.Tickets) != 1 || secondPage.Tickets[0].ID != 3 {
+			t.Errorf("got second page %v, want only ticket 3", secondPage.Tickets)
+		}
+	})
+
+	t.Run("returns Bad Request on out of range limit", func(t *testing.T) {
+		server := NewKitchenServer(newStore())
+
+		request := newListTicketsRequest(fmt.Sprintf("?limit=%d", maxPageLimit+1))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestAllergens(t *testing.T) {
+	store := &StubKitchenStore{
+		tickets: []Ticket{
+			{ID: 1, Status: STATUS_PENDING, Items: []Item{
+				{Name: "satay", Quantity: 1, Unit: UNIT_EACH, Allergens: []string{"peanut", "soy"}},
+				{Name: "noodles", Quantity: 1, Unit: UNIT_EACH, Allergens: []string{"gluten", "soy"}},
+			}},
+			{ID: 2, Status: STATUS_PENDING, Items: []Item{
+				{Name: "fries", Quantity: 1, Unit: UNIT_EACH},
+			}},
+			{ID: 3, Status: STATUS_ACCEPTED, Items: []Item{
+				{Name: "peanut butter cup", Quantity: 1, Unit: UNIT_EACH, Allergens: []string{"peanut", "milk"}},
+			}},
+		},
+	}
+	server := NewKitchenServer(store)
+
+	t.Run("returns ticket allergen summary", func(t *testing.T) {
+		request := newGetTicketRequest(1)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := TicketResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+
+		want := []string{"gluten", "peanut", "soy"}
+		if !reflect.DeepEqual(got.Allergens, want) {
+			t.Errorf("got allergens %v, want %v", got.Allergens, want)
+		}
+	})
+
+	t.Run("lists only tickets containing allergen", func(t *testing.T) {
+		request := newListTicketsRequest("?allergen=peanut")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := getTicketPageFromResponse(t, response.Body)
+		assertTickets(t, got.Tickets, []Ticket{store.tickets[0], store.tickets[2]})
+	})
+
+	t.Run("returns Bad Request on unknown allergen filter", func(t *testing.T) {
+		request := newListTicketsRequest("?allergen=kryptonite")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestCountTickets(t *testing.T) {
+	store := &StubKitchenStore{
+		tickets: []Ticket{
+			{ID: 1, Status: STATUS_PENDING},
+			{ID: 2, Status: STATUS_ACCEPTED},
+			{ID: 3, Status: STATUS_PENDING},
+			{ID: 4, Status: STATUS_COMPLETED},
+		},
+	}
+	server := NewKitchenServer(store)
+
+	t.Run("returns count of pending tickets without body", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodHead, "/ticket/?status=pending", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertHeader(t, response, "X-Total-Count", "2")
+
+		if response.Body.Len() != 0 {
+			t.Errorf("got body %q, want none", response.Body)
+		}
+	})
+
+	t.Run("counts several statuses", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodHead, "/ticket/?status=pending,accepted", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertHeader(t, response, "X-Total-Count", "3")
+	})
+
+	t.Run("returns Bad Request on unknown status", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodHead, "/ticket/?status=burnt", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Run("sets delta-seconds by default", func(t *testing.T) {
+		server := NewKitchenServer(nil)
+		response := httptest.NewRecorder()
+
+		server.setRetryAfter(response, 1500*time.Millisecond)
+
+		assertHeader(t, response, "Retry-After", "2")
+	})
+
+	t.Run("sets HTTP-date when configured", func(t *testing.T) {
+		server := NewKitchenServer(nil, WithRetryAfterHTTPDate(true))
+		response := httptest.NewRecorder()
+
+		before := time.Now()
+		server.setRetryAfter(response, 30*time.Second)
+
+		got, err := http.ParseTime(response.Header().Get("Retry-After"))
+		if err != nil {
+			t.Fatalf("Retry-After isn't a valid HTTP-date, %v", err)
+		}
+
+		want := before.Add(30 * time.Second).Truncate(time.Second)
+		if got.Before(want) || got.After(want.Add(2*time.Second)) {
+			t.Errorf("got Retry-After %v, want about %v", got, want)
+		}
+	})
+
+	t.Run("formats HTTP-date relative to now", func(t *testing.T) {
+		now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+		got := retryAfterValue(90*time.Second, true, now)
+		want := "Thu, 01 Jun 2023 12:01:30 GMT"
+
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func assertHeader(t testing.TB, response *httptest.ResponseRecorder, header, want string) {
+	t.Helper()
+
+	got := response.Header().Get(header)
+	if got != want {
+		t.Errorf("got header %s %q, want %q", header, got, want)
+	}
+}
+
 func assertTicketPersisted(t testing.TB, store *StubKitchenStore, want Ticket) {
 	t.Helper()
 
@@ -189,13 +911,50 @@ func assertTicketResponse(t testing.TB, body io.Reader, want CreateTicketRespons
 }
 
 func newCreateTicketRequest(ticket Ticket) *http.Request {
+	return newPostTicketRequest("/ticket/", ticket)
+}
+
+func newPostTicketRequest(path string, ticket Ticket) *http.Request {
 	buffer := &bytes.Buffer{}
 	json.NewEncoder(buffer).Encode(ticket)
 
-	req, _ := http.NewRequest(http.MethodPost, "/ticket/", buffer)
+	req, _ := http.NewRequest(http.MethodPost, path, buffer)
+	return req
+}
+
+func newListTicketsRequest(query string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "/ticket/"+query, nil)
 	return req
 }
 
+func getTicketPageFromResponse(t testing.TB, body io.Reader) TicketPage {
+	t.Helper()
+
+	page := TicketPage{}
+	err := json.NewDecoder(body).Decode(&page)
+	if err != nil {
+		t.Fatalf("Unable to parse response from server %q into TicketPage, %v", body, err)
+	}
+
+	return page
+}
+
+func assertTickets(t testing.TB, got, want []Ticket) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got tickets %v, want %v", got, want)
+	}
+}
+
+func assertNextCursor(t testing.TB, got, want string) {
+	t.Helper()
+
+	if got != want {
+		t.Errorf("got next cursor %q, want %q", got, want)
+	}
+}
+
 func newGetTicketRequest(ticketID int) *http.Request {
 	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/ticket/%d", ticketID), nil)
 	return req
@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShardStore is a store that can serve as a shard. Shards keep each ticket
+// under the ID the ShardedKitchenStore hands out, so IDs follow creation order
+// across all shards.
+type ShardStore interface {
+	KitchenStore
+	InsertTicket(ticket Ticket) error
+	InsertTicketIfNotExists(ticket Ticket) (Ticket, bool, error)
+}
+
+type Shard struct {
+	Store  ShardStore
+	Weight int
+}
+
+// ShardedKitchenStore spreads tickets over weighted shards. Ticket IDs come
+// from one counter and owners maps each ID to the shard holding it. Creates
+// take mu for writing and listings for reading, so a listing never sees a
+// ticket without also seeing every ticket created before it.
+type ShardedKitchenStore struct {
+	shards      []ShardStore
+	weights     []int
+	totalWeight uint64
+	next        atomic.Uint64
+	claimMu     sync.Mutex
+
+	mu     sync.RWMutex
+	owners map[int]int
+	lastID int
+}
+
+// NewShardedKitchenStore picks up the tickets, tombstones included, that the
+// shards already hold.
+func NewShardedKitchenStore(shards ...Shard) (*ShardedKitchenStore, error) {
+	s := &ShardedKitchenStore{owners: map[int]int{}}
+	for i, shard := range shards {
+		weight := max(shard.Weight, 1)
+		s.shards = append(s.shards, shard.Store)
+		s.weights = append(s.weights, weight)
+		s.totalWeight += uint64(weight)
+
+		tickets, err := shard.Store.GetTickets(TicketFilter{IncludeDeleted: true, Limit: math.MaxInt})
+		if err != nil {
+			return nil, fmt.Errorf("unable to index shard %d, %w", i, err)
+		}
+		for _, ticket := range tickets {
+			s.owners[ticket.ID] = i
+			s.lastID = max(s.lastID, ticket.ID)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *ShardedKitchenStore) pick(key uint64) int {
+	key %= s.totalWeight
+	for i, weight := range s.weights {
+		if key < uint64(weight) {
+			return i
+		}
+		key -= uint64(weight)
+	}
+
+	return len(s.weights) - 1
+}
+
+func (s *ShardedKitchenStore) shardForNewTicket(ticket Ticket) int {
+	if ticket.OrderID == "" {
+		return s.pick(s.next.Add(1) - 1)
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(orderKey(ticket)))
+
+	return s.pick(hash.Sum64())
+}
+
+func (s *ShardedKitchenStore) locate(ticketID int) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	shard, ok := s.owners[ticketID]
+	if !ok {
+		return 0, fmt.Errorf("no ticket with ID = %d", ticketID)
+	}
+
+	return shard, nil
+}
+
+func (s *ShardedKitchenStore) GetTicketByID(ticketID int) (Ticket, error) {
+	shard, err := s.locate(ticketID)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	return s.shards[shard].GetTicketByID(ticketID)
+}
+
+func (s *ShardedKitchenStore) StoreTicket(ticket Ticket) (int, error) {
+	shard := s.shardForNewTicket(ticket)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastID == math.MaxInt {
+		return 0, errTicketIDsExhausted
+	}
+
+	ticket.ID = s.lastID + 1
+	if err := s.shards[shard].InsertTicket(ticket); err != nil {
+		return 0, err
+	}
+	s.lastID = ticket.ID
+	s.owners[ticket.ID] = shard
+
+	return ticket.ID, nil
+}
+
+func (s *ShardedKitchenStore) StoreTicketIfNotExists(ticket Ticket) (Ticket, bool, error) {
+	shard := s.shardForNewTicket(ticket)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastID == math.MaxInt {
+		return Ticket{}, false, errTicketIDsExhausted
+	}
+
+	ticket.ID = s.lastID + 1
+	stored, created, err := s.shards[shard].InsertTicketIfNotExists(ticket)
+	if err != nil || !created {
+		return stored, false, err
+	}
+	s.lastID = stored.ID
+	s.owners[stored.ID] = shard
+
+	return stored, true, nil
+}
+
+func (s *ShardedKitchenStore) GetTickets(filter TicketFilter) ([]Ticket, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tickets := []Ticket{}
+	for _, store := range s.shards {
+		shardTickets, err := store.GetTickets(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		tickets = append(tickets, shardTickets...)
+	}
+
+	sort.Slice(tickets, func(a, b int) bool {
+		return queueBefore(tickets[a], tickets[b])
+	})
+
+	if len(tickets) > filter.Limit {
+		tickets = tickets[:filter.Limit]
+	}
+
+	return tickets, nil
+}
+
+func (s *ShardedKitchenStore) GetTicketsByIDs(ticketIDs []int) ([]Ticket, error) {
+	byShard := make([][]int, len(s.shards))
+	for _, id := range ticketIDs {
+		if shard, err := s.locate(id); err == nil {
+			byShard[shard] = append(byShard[shard], id)
+		}
+	}
+
+	found := map[int]Ticket{}
+	for shard, store := range s.shards {
+		if len(byShard[shard]) == 0 {
+			continue
+		}
+
+		shardTickets, err := store.GetTicketsByIDs(byShard[shard])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ticket := range shardTickets {
+			found[ticket.ID] = ticket
+		}
+	}
+
+	tickets := []Ticket{}
+	for _, id := range ticketIDs {
+		if ticket, ok := found[id]; ok {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	return tickets, nil
+}
+
+func (s *ShardedKitchenStore) StreamTickets(ctx context.Context, fn func(Ticket) error) error {
+	for _, store := range s.shards {
+		if err := store.StreamTickets(ctx, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ShardedKitchenStore) CountTickets(filter TicketFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, store := range s.shards {
+		shardCount, err := store.CountTickets(filter)
+		if err != nil {
+			return 0, err
+		}
+		count += shardCount
+	}
+
+	return count, nil
+}
+
+func (s *ShardedKitchenStore) UpdateTicket(ticket Ticket) error {
+	shard, err := s.locate(ticket.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.shards[shard].UpdateTicket(ticket)
+}
+
+func (s *ShardedKitchenStore) DeleteTicket(ticketID int, deletedAt time.Time) error {
+	shard, err := s.locate(ticketID)
+	if err != nil {
+		return err
+	}
+
+	return s.shards[shard].DeleteTicket(ticketID, deletedAt)
+}
+
+func (s *ShardedKitchenStore) RemoveTicket(ticketID int) error {
+	shard, err := s.locate(ticketID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.shards[shard].RemoveTicket(ticketID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.owners, ticketID)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *ShardedKitchenStore) StoreTicketEvent(event TicketEvent) error {
+	shard, err := s.locate(event.TicketID)
+	if err != nil {
+		return err
+	}
+
+	return s.shards[shard].StoreTicketEvent(event)
+}
+
+func (s *ShardedKitchenStore) GetTicketEvents(ticketID int) ([]TicketEvent, error) {
+	shard, err := s.locate(ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.shards[shard].GetTicketEvents(ticketID)
+}
+
+func (s *ShardedKitchenStore) PurgeCompletedBefore(before, now time.Time) (int, error) {
+	removed := 0
+	for _, store := range s.shards {
+		shardRemoved, err := store.PurgeCompletedBefore(before, now)
+		removed += shardRemoved
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+func (s *ShardedKitchenStore) ClaimNextTicket(filter TicketFilter, claim Claim) (Ticket, bool, error) {
+	s.claimMu.Lock()
+	defer s.claimMu.Unlock()
+
+	if claim.Capacity > 0 {
+		accepted, err := s.CountTickets(TicketFilter{KitchenID: filter.KitchenID, Station: filter.Station, Statuses: []Status{STATUS_ACCEPTED}})
+		if err != nil {
+			return Ticket{}, false, err
+		}
+		if accepted >= claim.Capacity {
+			return Ticket{}, false, ErrStationFull
+		}
+		claim.Capacity = 0
+	}
+
+	if claim.TicketID > 0 {
+		shard, err := s.locate(claim.TicketID)
+		if err != nil {
+			return Ticket{}, false, nil
+		}
+
+		return s.shards[shard].ClaimNextTicket(filter, claim)
+	}
+
+	filter.Limit = 1
+	for {
+		best, bestShard, found := Ticket{}, 0, false
+		for shard, store := range s.shards {
+			candidates, err := store.GetTickets(filter)
+			if err != nil {
+				return Ticket{}, false, err
+			}
+
+			if len(candidates) > 0 && (!found || queueBefore(candidates[0], best)) {
+				best, bestShard, found = candidates[0], shard, true
+			}
+		}
+
+		if !found {
+			return Ticket{}, false, nil
+		}
+
+		ticket, claimed, err := s.shards[bestShard].ClaimNextTicket(filter, claim)
+		if err != nil {
+			return Ticket{}, false, err
+		}
+
+		if claimed {
+			return ticket, true, nil
+		}
+	}
+}
+
+func (s *ShardedKitchenStore) MoveTicket(ticketID int, delta int, movedAt time.Time) (Ticket, error) {
+	shard, err := s.locate(ticketID)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	return s.shards[shard].MoveTicket(ticketID, delta, movedAt)
+}
+
+func (s *ShardedKitchenStore) ExpireTicket(ticketID int, now time.Time) (Ticket, bool, error) {
+	shard, err := s.locate(ticketID)
+	if err != nil {
+		return Ticket{}, false, err
+	}
+
+	return s.shards[shard].ExpireTicket(ticketID, now)
+}
+
+func (s *ShardedKitchenStore) StoreTemplate(template Template) error {
+	return s.shards[0].StoreTemplate(template)
+}
+
+func (s *ShardedKitchenStore) GetTemplate(kitchenID, name string) (Template, error) {
+	return s.shards[0].GetTemplate(kitchenID, name)
+}
+
+func (s *ShardedKitchenStore) DeleteTemplate(kitchenID, name string) error {
+	return s.shards[0].DeleteTemplate(kitchenID, name)
+}
+
+func (s *ShardedKitchenStore) GetMaintenance() (Maintenance, error) {
+	return s.shards[0].GetMaintenance()
+}
+
+func (s *ShardedKitchenStore) SetMaintenance(maintenance Maintenance) error {
+	return s.shards[0].SetMaintenance(maintenance)
+}
+
+func (i *InMemoryKitchenStore) InsertTicket(ticket Ticket) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.insertTicket(ticket)
+}
+
+func (i *InMemoryKitchenStore) InsertTicketIfNotExists(ticket Ticket) (Ticket, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if id, ok := i.byOrderID[orderKey(ticket)]; ok && !i.tickets[id].Deleted {
+		return i.tickets[id], false, nil
+	}
+
+	if err := i.insertTicket(ticket); err != nil {
+		return Ticket{}, false, err
+	}
+
+	return ticket, true, nil
+}
+
+func (i *InMemoryKitchenStore) insertTicket(ticket Ticket) error {
+	if ticket.ID < 1 {
+		return fmt.Errorf("invalid ticket ID = %d", ticket.ID)
+	}
+	if _, ok := i.tickets[ticket.ID]; ok {
+		return fmt.Errorf("ticket with ID = %d already exists", ticket.ID)
+	}
+
+	i.apply(StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+	return nil
+}
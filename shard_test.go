@@ -0,0 +1,401 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type PageRecordingKitchenStore struct {
+	*InMemoryKitchenStore
+	limits []int
+}
+
+func (p *PageRecordingKitchenStore) GetTickets(filter TicketFilter) ([]Ticket, error) {
+	p.limits = append(p.limits, filter.Limit)
+	return p.InMemoryKitchenStore.GetTickets(filter)
+}
+
+func newShardedStore(t testing.TB, shards ...Shard) *ShardedKitchenStore {
+	t.Helper()
+
+	store, err := NewShardedKitchenStore(shards...)
+	if err != nil {
+		t.Fatalf("unable to create sharded store, %v", err)
+	}
+
+	return store
+}
+
+func TestShardedKitchenStore(t *testing.T) {
+	newTicket := func(orderID string) Ticket {
+		return Ticket{OrderID: orderID, Status: STATUS_PENDING, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+	}
+	newStore := func(t testing.TB) (*ShardedKitchenStore, []*InMemoryKitchenStore) {
+		t.Helper()
+
+		shards := []*InMemoryKitchenStore{NewInMemoryKitchenStore(), NewInMemoryKitchenStore()}
+		return newShardedStore(t, Shard{Store: shards[0]}, Shard{Store: shards[1]}), shards
+	}
+
+	t.Run("round-robins new tickets across shards", func(t *testing.T) {
+		store, shards := newStore(t)
+
+		for range 4 {
+			store.StoreTicket(newTicket(""))
+		}
+
+		for i, shard := range shards {
+			if count, _ := shard.CountTickets(TicketFilter{}); count != 2 {
+				t.Errorf("got %d tickets on shard %d, want 2", count, i)
+			}
+		}
+	})
+
+	t.Run("weights the round-robin", func(t *testing.T) {
+		shards := []*InMemoryKitchenStore{NewInMemoryKitchenStore(), NewInMemoryKitchenStore()}
+		store := newShardedStore(t, Shard{Store: shards[0], Weight: 3}, Shard{Store: shards[1], Weight: 1})
+
+		for range 8 {
+			store.StoreTicket(newTicket(""))
+		}
+
+		first, _ := shards[0].CountTickets(TicketFilter{})
+		second, _ := shards[1].CountTickets(TicketFilter{})
+		if first != 6 || second != 2 {
+			t.Errorf("got %d and %d tickets on the shards, want 6 and 2", first, second)
+		}
+	})
+
+	t.Run("routes an order to the same shard every time", func(t *testing.T) {
+		store, shards := newStore(t)
+		other, others := newStore(t)
+
+		first, created, _ := store.StoreTicketIfNotExists(newTicket("order-42"))
+		if !created {
+			t.Fatalf("expected ticket to be created")
+		}
+		second, created, _ := store.StoreTicketIfNotExists(newTicket("order-42"))
+		if created || second.ID != first.ID {
+			t.Errorf("got ticket %d created %v, want existing ticket %d", second.ID, created, first.ID)
+		}
+
+		elsewhere, _, _ := other.StoreTicketIfNotExists(newTicket("order-42"))
+		for i := range shards {
+			_, here := shards[i].GetTicketByID(first.ID)
+			_, there := others[i].GetTicketByID(elsewhere.ID)
+			if (here == nil) != (there == nil) {
+				t.Errorf("got the order on different shards, want the same shard")
+			}
+		}
+	})
+
+	t.Run("reads a ticket from its owning shard", func(t *testing.T) {
+		store, shards := newStore(t)
+
+		ids := []int{}
+		for range 4 {
+			id, _ := store.StoreTicket(newTicket(""))
+			ids = append(ids, id)
+		}
+
+		for _, id := range ids {
+			got, err := store.GetTicketByID(id)
+			if err != nil || got.ID != id {
+				t.Errorf("got ticket %v and error %v reading ID %d", got, err, id)
+			}
+
+			owners := 0
+			for _, shard := range shards {
+				if _, err := shard.GetTicketByID(id); err == nil {
+					owners++
+				}
+			}
+			if owners != 1 {
+				t.Errorf("found ticket %d on %d shards, want it on one", id, owners)
+			}
+		}
+	})
+
+	t.Run("merges listings in queue order", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		ids := []int{}
+		for range 5 {
+			id, _ := store.StoreTicket(newTicket(""))
+			ids = append(ids, id)
+		}
+		store.MoveTicket(ids[3], -1, time.Now())
+
+		got := listedIDs(t, store, TicketFilter{Limit: 10})
+		want := []int{ids[3], ids[0], ids[1], ids[2], ids[4]}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+
+		cursor, _ := store.GetTicketByID(ids[0])
+		got = listedIDs(t, store, TicketFilter{AfterID: cursor.ID, AfterRank: cursor.QueueRank, Limit: 2})
+		want = []int{ids[1], ids[2]}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v after cursor, want %v", got, want)
+		}
+
+		if count, _ := store.CountTickets(TicketFilter{}); count != 5 {
+			t.Errorf("got count %d, want 5", count)
+		}
+	})
+
+	t.Run("pages through the shards a page at a time", func(t *testing.T) {
+		shards := []*PageRecordingKitchenStore{
+			{InMemoryKitchenStore: NewInMemoryKitchenStore()},
+			{InMemoryKitchenStore: NewInMemoryKitchenStore()},
+			{InMemoryKitchenStore: NewInMemoryKitchenStore()},
+		}
+		store := newShardedStore(t, Shard{Store: shards[0]}, Shard{Store: shards[1]}, Shard{Store: shards[2]})
+		for _, shard := range shards {
+			shard.limits = nil
+		}
+
+		ids := []int{}
+		for range 7 {
+			id, _ := store.StoreTicket(newTicket(""))
+			ids = append(ids, id)
+		}
+		store.MoveTicket(ids[4], -1, time.Now())
+		rushed, _ := store.GetTicketByID(ids[5])
+		rushed.Rush = true
+		store.UpdateTicket(rushed)
+
+		got := []int{}
+		filter := TicketFilter{Limit: 1}
+		for {
+			tickets, _ := store.GetTickets(filter)
+			for _, ticket := range tickets {
+				got = append(got, ticket.ID)
+			}
+			if len(tickets) < filter.Limit {
+				break
+			}
+			last := tickets[len(tickets)-1]
+			filter.AfterID, filter.AfterRank, filter.AfterRush = last.ID, last.QueueRank, last.Rush
+		}
+
+		want := []int{ids[4], ids[5], ids[0], ids[1], ids[2], ids[3], ids[6]}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+		for i, shard := range shards {
+			for _, limit := range shard.limits {
+				if limit != 1 {
+					t.Errorf("got limit %d on shard %d, want each shard asked for a page", limit, i)
+				}
+			}
+		}
+	})
+
+	t.Run("numbers tickets in creation order across shards", func(t *testing.T) {
+		store := newShardedStore(t, Shard{Store: NewInMemoryKitchenStore(), Weight: 3}, Shard{Store: NewInMemoryKitchenStore()})
+
+		ids := []int{}
+		for range 8 {
+			id, _ := store.StoreTicket(newTicket(""))
+			ids = append(ids, id)
+		}
+
+		if got := listedIDs(t, store, TicketFilter{Limit: 10}); !reflect.DeepEqual(got, ids) {
+			t.Errorf("got IDs %v in queue order, want creation order %v", got, ids)
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] <= ids[i-1] {
+				t.Errorf("got ID %d after %d, want IDs to grow", ids[i], ids[i-1])
+			}
+		}
+	})
+
+	t.Run("pages without skipping tickets created between pages", func(t *testing.T) {
+		store := newShardedStore(t, Shard{Store: NewInMemoryKitchenStore(), Weight: 3}, Shard{Store: NewInMemoryKitchenStore()})
+
+		want := []int{}
+		for range 3 {
+			id, _ := store.StoreTicket(newTicket(""))
+			want = append(want, id)
+		}
+
+		got := []int{}
+		filter := TicketFilter{Limit: 2}
+		for page := 0; ; page++ {
+			tickets, _ := store.GetTickets(filter)
+			for _, ticket := range tickets {
+				got = append(got, ticket.ID)
+			}
+			if len(tickets) < filter.Limit {
+				break
+			}
+			last := tickets[len(tickets)-1]
+			filter.AfterID, filter.AfterRank, filter.AfterRush = last.ID, last.QueueRank, last.Rush
+
+			if page < 3 {
+				for range 3 {
+					id, _ := store.StoreTicket(newTicket(""))
+					want = append(want, id)
+				}
+			}
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("picks up the tickets its shards already hold", func(t *testing.T) {
+		shards := []Shard{{Store: NewInMemoryKitchenStore()}, {Store: NewInMemoryKitchenStore()}}
+		first := newShardedStore(t, shards...)
+		ids := []int{}
+		for range 3 {
+			id, _ := first.StoreTicket(newTicket(""))
+			ids = append(ids, id)
+		}
+		first.DeleteTicket(ids[2], time.Now())
+
+		store := newShardedStore(t, shards...)
+		if got, err := store.GetTicketByID(ids[1]); err != nil || got.ID != ids[1] {
+			t.Errorf("got ticket %v and error %v reading ID %d", got, err, ids[1])
+		}
+		if id, _ := store.StoreTicket(newTicket("")); id <= ids[2] {
+			t.Errorf("got new ID %d, want it past the existing ID %d", id, ids[2])
+		}
+	})
+
+	t.Run("refuses a ticket once the IDs run out", func(t *testing.T) {
+		shards := []*InMemoryKitchenStore{NewInMemoryKitchenStore(), NewInMemoryKitchenStore()}
+		store := newShardedStore(t, Shard{Store: shards[0]}, Shard{Store: shards[1]})
+		store.lastID = math.MaxInt
+
+		_, err := store.StoreTicket(newTicket(""))
+		if !errors.Is(err, errTicketIDsExhausted) {
+			t.Errorf("got error %v, want %v", err, errTicketIDsExhausted)
+		}
+		for i, shard := range shards {
+			if count, _ := shard.CountTickets(TicketFilter{}); count != 0 {
+				t.Errorf("got %d tickets on shard %d, want none", count, i)
+			}
+		}
+	})
+
+	t.Run("gets tickets by ID across shards in request order", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		ids := []int{}
+		for range 3 {
+			id, _ := store.StoreTicket(newTicket(""))
+			ids = append(ids, id)
+		}
+
+		tickets, _ := store.GetTicketsByIDs([]int{ids[2], 999, ids[0], ids[1]})
+		got := []int{}
+		for _, ticket := range tickets {
+			got = append(got, ticket.ID)
+		}
+
+		want := []int{ids[2], ids[0], ids[1]}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("claims the next ticket across all shards", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		ids := []int{}
+		for range 3 {
+			id, _ := store.StoreTicket(newTicket(""))
+			ids = append(ids, id)
+		}
+		store.MoveTicket(ids[1], -1, time.Now())
+
+		filter := TicketFilter{Statuses: []Status{STATUS_PENDING}}
+		got := []int{}
+		for {
+			ticket, claimed, err := store.ClaimNextTicket(filter, Claim{At: time.Now()})
+			if err != nil || !claimed {
+				break
+			}
+			got = append(got, ticket.ID)
+		}
+
+		want := []int{ids[1], ids[0], ids[2]}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got claims %v, want %v", got, want)
+		}
+	})
+
+	t.Run("claims a given ticket on its shard", func(t *testing.T) {
+		store := newShardedStore(t, Shard{Store: NewInMemoryKitchenStore()}, Shard{Store: NewInMemoryKitchenStore()})
+
+		ids := []int{}
+		for range 3 {
+			id, _ := store.StoreTicket(newTicket(""))
+			ids = append(ids, id)
+		}
+
+		filter := TicketFilter{Statuses: []Status{STATUS_PENDING}}
+		ticket, claimed, err := store.ClaimNextTicket(filter, Claim{At: time.Now(), TicketID: ids[1]})
+		if err != nil || !claimed || ticket.ID != ids[1] || ticket.Status != STATUS_ACCEPTED {
+			t.Fatalf("got claim %v, %v, %v, want ticket %d accepted", ticket, claimed, err, ids[1])
+		}
+
+		if _, claimed, _ := store.ClaimNextTicket(filter, Claim{At: time.Now(), TicketID: ids[1]}); claimed {
+			t.Errorf("claimed ticket %d twice, want it claimed once", ids[1])
+		}
+	})
+
+	t.Run("excludes tickets by their global ID", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		ids := []int{}
+		for range 4 {
+			id, _ := store.StoreTicket(newTicket(""))
+			ids = append(ids, id)
+		}
+
+		filter := TicketFilter{Limit: 10, ExcludeIDs: []int{ids[0], ids[3]}}
+		if got, want := listedIDs(t, store, filter), []int{ids[1], ids[2]}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+
+		ticket, _, _ := store.ClaimNextTicket(filter, Claim{At: time.Now()})
+		if ticket.ID != ids[1] {
+			t.Errorf("got claim %d, want %d", ticket.ID, ids[1])
+		}
+	})
+
+	t.Run("keeps events with their ticket", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		store.StoreTicket(newTicket(""))
+		id, _ := store.StoreTicket(newTicket(""))
+		store.StoreTicketEvent(TicketEvent{Type: EVENT_CREATED, TicketID: id})
+
+		events, _ := store.GetTicketEvents(id)
+		if len(events) != 1 || events[0].TicketID != id {
+			t.Errorf("got events %v, want one event for ticket %d", events, id)
+		}
+	})
+}
+
+func listedIDs(t testing.TB, store KitchenStore, filter TicketFilter) []int {
+	t.Helper()
+
+	tickets, err := store.GetTickets(filter)
+	if err != nil {
+		t.Fatalf("unable to list tickets, %v", err)
+	}
+
+	ids := []int{}
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+	}
+	return ids
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+type storeSnapshot struct {
+	LastID    int
+	Tickets   []Ticket
+	Events    []TicketEvent
+	Templates []Template
+
+	Maintenance Maintenance
+	Outbox      []OutboxEntry
+}
+
+func (i *InMemoryKitchenStore) Snapshot(w io.Writer) error {
+	i.mu.RLock()
+	snapshot := storeSnapshot{LastID: i.lastID, Maintenance: i.maintenance, Outbox: slices.Clone(i.outbox)}
+	for _, ticket := range i.tickets {
+		snapshot.Tickets = append(snapshot.Tickets, ticket)
+	}
+	for _, events := range i.events {
+		snapshot.Events = append(snapshot.Events, events...)
+	}
+	for _, template := range i.templates {
+		snapshot.Templates = append(snapshot.Templates, template)
+	}
+	i.mu.RUnlock()
+
+	return json.NewEncoder(w).Encode(snapshot)
+}
+
+func (i *InMemoryKitchenStore) Restore(r io.Reader) error {
+	snapshot := storeSnapshot{}
+	err := json.NewDecoder(r).Decode(&snapshot)
+	if err != nil {
+		return fmt.Errorf("unable to decode store snapshot, %v", err)
+	}
+
+	tickets := map[int]Ticket{}
+	for _, ticket := range snapshot.Tickets {
+		tickets[ticket.ID] = ticket
+	}
+
+	events := map[int][]TicketEvent{}
+	for _, event := range snapshot.Events {
+		events[event.TicketID] = append(events[event.TicketID], event)
+	}
+
+	templates := map[string]Template{}
+	for _, template := range snapshot.Templates {
+		templates[templateKey(template.KitchenID, template.Name)] = template
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.lastID = snapshot.LastID
+	i.tickets = tickets
+	i.events = events
+	i.templates = templates
+	i.maintenance = snapshot.Maintenance
+	i.outbox = snapshot.Outbox
+	i.lastOutboxID = 0
+	for _, entry := range snapshot.Outbox {
+		i.lastOutboxID = max(i.lastOutboxID, entry.ID)
+	}
+	i.reindex()
+
+	if i.eventSourced {
+		i.log = nil
+		for _, ticket := range snapshot.Tickets {
+			i.log = append(i.log, StoreChange{Type: CHANGE_TICKET, Ticket: ticket})
+		}
+		for _, event := range snapshot.Events {
+			i.log = append(i.log, StoreChange{Type: CHANGE_EVENT, Event: event})
+		}
+		for _, template := range snapshot.Templates {
+			i.log = append(i.log, StoreChange{Type: CHANGE_TEMPLATE, Template: template})
+		}
+		if snapshot.Maintenance != (Maintenance{}) {
+			i.log = append(i.log, StoreChange{Type: CHANGE_MAINTENANCE, Maintenance: snapshot.Maintenance})
+		}
+		for _, entry := range snapshot.Outbox {
+			i.log = append(i.log, StoreChange{Type: CHANGE_OUTBOX, Outbox: entry})
+		}
+	}
+
+	return nil
+}
+
+func (i *InMemoryKitchenStore) SnapshotToFile(path string) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create snapshot file, %v", err)
+	}
+	defer os.Remove(file.Name())
+
+	err = i.Snapshot(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write snapshot file, %v", err)
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (i *InMemoryKitchenStore) RestoreFromFile(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to open snapshot file, %v", err)
+	}
+	defer file.Close()
+
+	return i.Restore(file)
+}
+
+func (i *InMemoryKitchenStore) SnapshotEvery(path string, interval time.Duration, logger *slog.Logger) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := i.SnapshotToFile(path); err != nil {
+					logger.Error("unable to snapshot store", "path", path, "error", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	createdAt := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	newPopulatedStore := func() *InMemoryKitchenStore {
+		store := NewInMemoryKitchenStore()
+		for _, name := range []string{"burger", "pizza"} {
+			id, _ := store.StoreTicket(Ticket{
+				Status:    STATUS_PENDING,
+				Items:     Items{{Name: name, Quantity: 1, Unit: UNIT_EACH}},
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			})
+			store.StoreTicketEvent(TicketEvent{Type: EVENT_CREATED, TicketID: id, OccurredAt: createdAt})
+		}
+
+		return store
+	}
+
+	t.Run("restores tickets and events from snapshot", func(t *testing.T) {
+		original := newPopulatedStore()
+
+		buffer := &bytes.Buffer{}
+		err := original.Snapshot(buffer)
+		if err != nil {
+			t.Fatalf("unable to snapshot store, %v", err)
+		}
+
+		restored := NewInMemoryKitchenStore()
+		err = restored.Restore(buffer)
+		if err != nil {
+			t.Fatalf("unable to restore store, %v", err)
+		}
+
+		assertStoresEqual(t, restored, original)
+	})
+
+	t.Run("continues IDs after restore", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		newPopulatedStore().Snapshot(buffer)
+
+		restored := NewInMemoryKitchenStore()
+		restored.Restore(buffer)
+
+		id, _ := restored.StoreTicket(Ticket{})
+		if id != 3 {
+			t.Errorf("got ID %d after restore, want 3", id)
+		}
+	})
+
+	t.Run("restores from snapshot file", func(t *testing.T) {
+		original := newPopulatedStore()
+		path := filepath.Join(t.TempDir(), "snapshot.json")
+
+		err := original.SnapshotToFile(path)
+		if err != nil {
+			t.Fatalf("unable to snapshot store to file, %v", err)
+		}
+
+		restored := NewInMemoryKitchenStore()
+		err = restored.RestoreFromFile(path)
+		if err != nil {
+			t.Fatalf("unable to restore store from file, %v", err)
+		}
+
+		assertStoresEqual(t, restored, original)
+	})
+
+	t.Run("starts empty when snapshot file doesn't exist", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+
+		err := store.RestoreFromFile(filepath.Join(t.TempDir(), "missing.json"))
+		if err != nil {
+			t.Errorf("got error %v, want none for a missing snapshot", err)
+		}
+	})
+}
+
+func assertStoresEqual(t testing.TB, got, want *InMemoryKitchenStore) {
+	t.Helper()
+
+	gotTickets, _ := got.GetTickets(TicketFilter{Limit: maxPageLimit})
+	wantTickets, _ := want.GetTickets(TicketFilter{Limit: maxPageLimit})
+	if !reflect.DeepEqual(gotTickets, wantTickets) {
+		t.Errorf("got tickets %v, want %v", gotTickets, wantTickets)
+	}
+
+	for _, ticket := range wantTickets {
+		gotEvents, _ := got.GetTicketEvents(ticket.ID)
+		wantEvents, _ := want.GetTicketEvents(ticket.ID)
+		if !reflect.DeepEqual(gotEvents, wantEvents) {
+			t.Errorf("got events %v for ticket %d, want %v", gotEvents, ticket.ID, wantEvents)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	subscriberBuffer = 16
+	eventHistorySize = 256
+)
+
+type sequencedEvent struct {
+	ID uint64
+	TicketEvent
+}
+
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan sequencedEvent]struct{}
+	lastID      uint64
+	history     []sequencedEvent
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: map[chan sequencedEvent]struct{}{}}
+}
+
+func (h *eventHub) subscribe() chan sequencedEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.addSubscriber()
+}
+
+func (h *eventHub) subscribeAfter(lastID uint64) (chan sequencedEvent, []sequencedEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	missed := []sequencedEvent{}
+	for _, event := range h.history {
+		if event.ID > lastID {
+			missed = append(missed, event)
+		}
+	}
+
+	return h.addSubscriber(), missed
+}
+
+func (h *eventHub) addSubscriber() chan sequencedEvent {
+	events := make(chan sequencedEvent, subscriberBuffer)
+	h.subscribers[events] = struct{}{}
+
+	return events
+}
+
+func (h *eventHub) unsubscribe(events chan sequencedEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscribers, events)
+}
+
+func (h *eventHub) subscriberCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscribers)
+}
+
+func (h *eventHub) Publish(event TicketEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	sequenced := sequencedEvent{ID: h.lastID, TicketEvent: event}
+	h.history = append(h.history, sequenced)
+	if len(h.history) > eventHistorySize {
+		h.history = h.history[len(h.history)-eventHistorySize:]
+	}
+
+	for events := range h.subscribers {
+		select {
+		case events <- sequenced:
+		default:
+			// Closing a subscriber that fell behind ends its stream, so the
+			// client reconnects with Last-Event-ID and replays from history.
+			delete(h.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+func isStreamPath(path string) bool {
+	return strings.HasSuffix(path, "/ticket/stream")
+}
+
+func (k *KitchenServer) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && k.streamOrigins[origin] {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
+
+	var events chan sequencedEvent
+	var missed []sequencedEvent
+	if lastID, err := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64); err == nil {
+		events, missed = k.events.subscribeAfter(lastID)
+	} else {
+		events = k.events.subscribe()
+	}
+	defer k.events.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	controller.Flush()
+
+	store := k.storeFor(r)
+	for _, event := range missed {
+		if !k.writeStreamEvent(w, store, event) {
+			return
+		}
+	}
+	controller.Flush()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !k.writeStreamEvent(w, store, event) {
+				return
+			}
+			controller.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (k *KitchenServer) writeStreamEvent(w http.ResponseWriter, store KitchenStore, event sequencedEvent) bool {
+	ticket, err := store.GetTicketByID(event.TicketID)
+	if err == nil {
+		event.Rush = ticket.Rush
+	} else if scoped, ok := store.(*kitchenStore); ok && !scoped.ownsTicket(event.TicketID) {
+		return true
+	}
+
+	data, err := k.marshalJSON(event.TicketEvent)
+	if err != nil {
+		k.logger.Error("unable to encode ticket event", "ticket_id", event.TicketID, "error", err)
+		return true
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\nid: %d\n\n", event.Type, data, event.ID)
+	return err == nil
+}
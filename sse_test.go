@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamEvents(t *testing.T) {
+	t.Run("streams ticket events", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := httptest.NewServer(NewKitchenServer(store))
+		defer server.Close()
+
+		response, err := http.Get(server.URL + "/ticket/stream")
+		if err != nil {
+			t.Fatalf("unable to open stream, %v", err)
+		}
+		defer response.Body.Close()
+
+		assertStatus(t, response.StatusCode, http.StatusOK)
+		if got := response.Header.Get("Content-Type"); got != "text/event-stream" {
+			t.Errorf("got content type %q, want text/event-stream", got)
+		}
+
+		http.Post(server.URL+"/ticket/", "application/json", strings.NewReader(`{"Items": ["burger"]}`))
+
+		reader := bufio.NewReader(response.Body)
+		line, _ := reader.ReadString('\n')
+		if line != "event: created\n" {
+			t.Errorf("got line %q, want a created event", line)
+		}
+	})
+
+	t.Run("flags rush tickets", func(t *testing.T) {
+		server := httptest.NewServer(NewKitchenServer(NewInMemoryKitchenStore()))
+		defer server.Close()
+
+		response, err := http.Get(server.URL + "/ticket/stream")
+		if err != nil {
+			t.Fatalf("unable to open stream, %v", err)
+		}
+		defer response.Body.Close()
+
+		http.Post(server.URL+"/ticket/", "application/json", strings.NewReader(`{"Items": ["burger"], "Rush": true}`))
+
+		reader := bufio.NewReader(response.Body)
+		reader.ReadString('\n')
+		line, _ := reader.ReadString('\n')
+		if !strings.Contains(line, `"Rush":true`) {
+			t.Errorf("got line %q, want the event flagged as rush", line)
+		}
+	})
+
+	t.Run("streams a ticket's deletion", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		store.StoreTicket(Ticket{Status: STATUS_PENDING, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		server := httptest.NewServer(NewKitchenServer(store))
+		defer server.Close()
+
+		response, err := http.Get(server.URL + "/ticket/stream")
+		if err != nil {
+			t.Fatalf("unable to open stream, %v", err)
+		}
+		defer response.Body.Close()
+
+		request, _ := http.NewRequest(http.MethodDelete, server.URL+"/ticket/1", nil)
+		http.DefaultClient.Do(request)
+
+		reader := bufio.NewReader(response.Body)
+		line, _ := reader.ReadString('\n')
+		if line != "event: deleted\n" {
+			t.Errorf("got line %q, want a deleted event", line)
+		}
+	})
+
+	t.Run("streams a deletion only to the ticket's kitchen", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		store.StoreTicket(Ticket{KitchenID: "london", Status: STATUS_PENDING, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		server := httptest.NewServer(NewKitchenServer(store, WithKitchens("london", "paris")))
+		defer server.Close()
+
+		response, err := http.Get(server.URL + "/paris/ticket/stream")
+		if err != nil {
+			t.Fatalf("unable to open stream, %v", err)
+		}
+		defer response.Body.Close()
+
+		request, _ := http.NewRequest(http.MethodDelete, server.URL+"/london/ticket/1", nil)
+		http.DefaultClient.Do(request)
+		http.Post(server.URL+"/paris/ticket/", "application/json", strings.NewReader(`{"Items": ["fries"]}`))
+
+		reader := bufio.NewReader(response.Body)
+		line, _ := reader.ReadString('\n')
+		if line != "event: created\n" {
+			t.Errorf("got line %q, want only the paris ticket's created event", line)
+		}
+	})
+}
+
+func TestStreamDisconnect(t *testing.T) {
+	waitForSubscribers := func(t *testing.T, hub *eventHub, want int) {
+		t.Helper()
+
+		deadline := time.Now().Add(time.Second)
+		for hub.subscriberCount() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("got %d subscribers, want %d", hub.subscriberCount(), want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	server := NewKitchenServer(NewInMemoryKitchenStore())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/ticket/stream", nil)
+
+	done := make(chan struct{})
+	go func() {
+		server.ServeHTTP(httptest.NewRecorder(), request)
+		close(done)
+	}()
+
+	waitForSubscribers(t, server.events, 1)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream didn't return after the client disconnected")
+	}
+	waitForSubscribers(t, server.events, 0)
+}
+
+func TestStreamReplay(t *testing.T) {
+	readEvent := func(t *testing.T, reader *bufio.Reader) (string, string) {
+		t.Helper()
+
+		eventType, id := "", ""
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("unable to read event, %v", err)
+			}
+			if line == "\n" {
+				return eventType, id
+			}
+			if value, ok := strings.CutPrefix(line, "event: "); ok {
+				eventType = strings.TrimSpace(value)
+			}
+			if value, ok := strings.CutPrefix(line, "id: "); ok {
+				id = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	openStream := func(t *testing.T, url, lastEventID string) *http.Response {
+		t.Helper()
+
+		request, _ := http.NewRequest(http.MethodGet, url+"/ticket/stream", nil)
+		if lastEventID != "" {
+			request.Header.Set("Last-Event-ID", lastEventID)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("unable to open stream, %v", err)
+		}
+
+		return response
+	}
+
+	t.Run("numbers events with increasing ids", func(t *testing.T) {
+		server := httptest.NewServer(NewKitchenServer(NewInMemoryKitchenStore()))
+		defer server.Close()
+
+		response := openStream(t, server.URL, "")
+		defer response.Body.Close()
+
+		http.Post(server.URL+"/ticket/", "application/json", strings.NewReader(`{"Items": ["burger"]}`))
+		http.Post(server.URL+"/ticket/", "application/json", strings.NewReader(`{"Items": ["pizza"]}`))
+
+		reader := bufio.NewReader(response.Body)
+		_, first := readEvent(t, reader)
+		_, second := readEvent(t, reader)
+		if first != "1" || second != "2" {
+			t.Errorf("got ids %q and %q, want 1 and 2", first, second)
+		}
+	})
+
+	t.Run("replays events missed since Last-Event-ID", func(t *testing.T) {
+		server := httptest.NewServer(NewKitchenServer(NewInMemoryKitchenStore()))
+		defer server.Close()
+
+		response := openStream(t, server.URL, "")
+		http.Post(server.URL+"/ticket/", "application/json", strings.NewReader(`{"Items": ["burger"]}`))
+		_, lastEventID := readEvent(t, bufio.NewReader(response.Body))
+		response.Body.Close()
+
+		http.Post(server.URL+"/ticket/", "application/json", strings.NewReader(`{"Items": ["pizza"]}`))
+		http.Post(server.URL+"/ticket/1/accept", "application/json", nil)
+
+		response = openStream(t, server.URL, lastEventID)
+		defer response.Body.Close()
+
+		reader := bufio.NewReader(response.Body)
+		eventType, id := readEvent(t, reader)
+		if eventType != EVENT_CREATED || id != "2" {
+			t.Errorf("got %q event %q, want created event 2", eventType, id)
+		}
+		eventType, id = readEvent(t, reader)
+		if eventType != EVENT_ACCEPTED || id != "3" {
+			t.Errorf("got %q event %q, want accepted event 3", eventType, id)
+		}
+	})
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	hub := newEventHub()
+	events := hub.subscribe()
+
+	for i := range subscriberBuffer + 1 {
+		hub.Publish(TicketEvent{Type: EVENT_CREATED, TicketID: i})
+	}
+
+	if got := len(drainEvents(events)); got != subscriberBuffer {
+		t.Errorf("got %d events, want the %d that fit the buffer", got, subscriberBuffer)
+	}
+	if _, ok := <-events; ok {
+		t.Error("expected the slow subscriber's channel to be closed but it wasn't")
+	}
+	if hub.subscriberCount() != 0 {
+		t.Errorf("got %d subscribers, want the slow one dropped", hub.subscriberCount())
+	}
+
+	_, missed := hub.subscribeAfter(subscriberBuffer)
+	if len(missed) != 1 || missed[0].TicketID != subscriberBuffer {
+		t.Errorf("got missed events %v, want the dropped event replayed", missed)
+	}
+}
+
+func TestStreamQueryToken(t *testing.T) {
+	server := NewKitchenServer(&StubKitchenStore{}, WithAPIKeys(APIKey{Key: "s3cret", Role: ROLE_COOK}), WithStreamQueryToken(true))
+
+	newRequest := func(path string) (*http.Request, context.CancelFunc) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		request, _ := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		return request, cancel
+	}
+
+	t.Run("accepts a valid query token on the stream", func(t *testing.T) {
+		request, cancel := newRequest("/ticket/stream?token=s3cret")
+		defer cancel()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("rejects an invalid query token", func(t *testing.T) {
+		request, cancel := newRequest("/ticket/stream?token=guess")
+		defer cancel()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("requires the header on other endpoints", func(t *testing.T) {
+		request, cancel := newRequest("/ticket/?token=s3cret")
+		defer cancel()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusUnauthorized)
+
+		request.Header.Set("Authorization", "Bearer s3cret")
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("ignores query tokens unless enabled", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithAPIKeys(APIKey{Key: "s3cret", Role: ROLE_COOK}))
+
+		request, cancel := newRequest("/ticket/stream?token=s3cret")
+		defer cancel()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusUnauthorized)
+	})
+}
+
+func drainEvents(events chan sequencedEvent) []TicketEvent {
+	received := []TicketEvent{}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return received
+			}
+			received = append(received, event.TicketEvent)
+		default:
+			return received
+		}
+	}
+}
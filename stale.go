@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+func (k *KitchenServer) isStale(ticket Ticket, now time.Time) bool {
+	return k.staleAfter > 0 && containsStatus(activeStatuses, ticket.Status) && now.Sub(ticket.CreatedAt) > k.staleAfter
+}
+
+func (k *KitchenServer) listStaleTickets(w http.ResponseWriter, r *http.Request) {
+	filter, err := getTicketFilter(r, k.limits.MaxPageLimit)
+	if err != nil || r.URL.Query().Has("status") {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	now := k.clock.Now()
+	matches := filter.Expression
+	filter.Statuses = activeStatuses
+	filter.Expression = func(ticket Ticket) bool {
+		return k.isStale(ticket, now) && (matches == nil || matches(ticket))
+	}
+	k.serveTicketList(w, r, filter)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStaleTickets(t *testing.T) {
+	createdAt := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	threshold := 30 * time.Minute
+
+	newServer := func(clock *StubClock) *KitchenServer {
+		store := NewInMemoryKitchenStore()
+		for _, status := range []Status{STATUS_ACCEPTED, STATUS_PENDING, STATUS_COMPLETED} {
+			store.StoreTicket(Ticket{
+				Status:    status,
+				Items:     Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}},
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			})
+		}
+		store.StoreTicket(Ticket{
+			Status:    STATUS_ACCEPTED,
+			Items:     Items{{Name: "pizza", Quantity: 1, Unit: UNIT_EACH}},
+			CreatedAt: createdAt.Add(time.Minute),
+			UpdatedAt: createdAt.Add(time.Minute),
+		})
+
+		return NewKitchenServer(store, WithClock(clock), WithStaleAfter(threshold))
+	}
+
+	getStale := func(t testing.TB, server *KitchenServer, ticketID int) bool {
+		t.Helper()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(ticketID))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := TicketResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+		return got.Stale
+	}
+
+	listStale := func(t testing.TB, server *KitchenServer) []int {
+		t.Helper()
+
+		request, _ := http.NewRequest(http.MethodGet, "/ticket/stale", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response.Code, http.StatusOK)
+
+		ids := []int{}
+		for _, ticket := range getTicketPageFromResponse(t, response.Body).Tickets {
+			ids = append(ids, ticket.ID)
+		}
+		return ids
+	}
+
+	t.Run("doesn't flag a ticket at the threshold", func(t *testing.T) {
+		server := newServer(&StubClock{createdAt.Add(threshold)})
+
+		if getStale(t, server, 1) {
+			t.Errorf("got ticket flagged stale, want it fresh")
+		}
+		if got := listStale(t, server); len(got) != 0 {
+			t.Errorf("got stale IDs %v, want none", got)
+		}
+	})
+
+	t.Run("flags active tickets past the threshold", func(t *testing.T) {
+		server := newServer(&StubClock{createdAt.Add(threshold + time.Nanosecond)})
+
+		if !getStale(t, server, 1) {
+			t.Errorf("got ticket fresh, want it flagged stale")
+		}
+		if getStale(t, server, 3) {
+			t.Errorf("got completed ticket flagged stale, want it fresh")
+		}
+		if got, want := listStale(t, server), []int{1, 2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got stale IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("flags tickets as they age", func(t *testing.T) {
+		clock := &StubClock{createdAt.Add(threshold + time.Nanosecond)}
+		server := newServer(clock)
+
+		clock.Advance(time.Minute)
+
+		if got, want := listStale(t, server), []int{1, 2, 4}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got stale IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("doesn't flag tickets without a threshold", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 1, Status: STATUS_ACCEPTED, CreatedAt: createdAt}}}
+		server := NewKitchenServer(store, WithClock(&StubClock{createdAt.Add(24 * time.Hour)}))
+
+		if getStale(t, server, 1) {
+			t.Errorf("got ticket flagged stale, want it fresh")
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type startSoonNotices struct {
+	mu   sync.Mutex
+	sent map[int]time.Time
+}
+
+func newStartSoonNotices() *startSoonNotices {
+	return &startSoonNotices{sent: map[int]time.Time{}}
+}
+
+func isStartingSoon(ticket Ticket, now time.Time, lead time.Duration) bool {
+	return ticket.Status == STATUS_PENDING && isScheduledAfter(ticket, now) && !now.Before(ticket.ScheduledFor.Add(-lead))
+}
+
+func (k *KitchenServer) NotifyStartingSoon() (int, error) {
+	if k.startSoonLead <= 0 {
+		return 0, nil
+	}
+
+	now := k.clock.Now()
+
+	upcoming := map[int]time.Time{}
+	due := []Ticket{}
+	err := k.store.StreamTickets(context.Background(), func(ticket Ticket) error {
+		if isScheduledAfter(ticket, now) {
+			upcoming[ticket.ID] = *ticket.ScheduledFor
+		}
+		if isStartingSoon(ticket, now, k.startSoonLead) {
+			due = append(due, ticket)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	k.startSoon.mu.Lock()
+	defer k.startSoon.mu.Unlock()
+
+	notified := 0
+	for _, ticket := range due {
+		if sent, ok := k.startSoon.sent[ticket.ID]; ok && sent.Equal(*ticket.ScheduledFor) {
+			continue
+		}
+
+		k.recordEvent(TicketEvent{
+			Type:       EVENT_STARTING_SOON,
+			TicketID:   ticket.ID,
+			Status:     ticket.Status,
+			OccurredAt: now,
+			Rush:       ticket.Rush,
+		})
+		k.startSoon.sent[ticket.ID] = *ticket.ScheduledFor
+		notified++
+	}
+
+	for id, scheduledFor := range k.startSoon.sent {
+		if !upcoming[id].Equal(scheduledFor) {
+			delete(k.startSoon.sent, id)
+		}
+	}
+
+	return notified, nil
+}
+
+func (k *KitchenServer) NotifyStartingSoonEvery(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := k.NotifyStartingSoon(); err != nil {
+					k.logger.Error("unable to notify starting soon tickets", "error", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotifyStartingSoon(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	scheduledFor := now.Add(time.Hour)
+	lead := 15 * time.Minute
+
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_PENDING, ScheduledFor: &scheduledFor},
+				{ID: 2, Status: STATUS_ACCEPTED, ScheduledFor: &scheduledFor},
+				{ID: 3, Status: STATUS_PENDING},
+			},
+		}
+	}
+
+	t.Run("waits until the lead time before ScheduledFor", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := NewKitchenServer(newStore(), WithClock(clock), WithStartSoonLeadTime(lead))
+		events := server.events.subscribe()
+
+		clock.Advance(time.Hour - lead - time.Nanosecond)
+		notified, _ := server.NotifyStartingSoon()
+
+		if notified != 0 {
+			t.Errorf("got %d tickets notified, want 0", notified)
+		}
+		if got := drainEvents(events); len(got) != 0 {
+			t.Errorf("got events %v, want none", got)
+		}
+	})
+
+	t.Run("fires once the lead time is reached", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := NewKitchenServer(newStore(), WithClock(clock), WithStartSoonLeadTime(lead))
+		events := server.events.subscribe()
+
+		clock.Advance(time.Hour - lead)
+		notified, _ := server.NotifyStartingSoon()
+
+		if notified != 1 {
+			t.Errorf("got %d tickets notified, want 1", notified)
+		}
+
+		got := drainEvents(events)
+		if len(got) != 1 {
+			t.Fatalf("got %d events, want 1", len(got))
+		}
+		if got[0].Type != EVENT_STARTING_SOON || got[0].TicketID != 1 || !got[0].OccurredAt.Equal(scheduledFor.Add(-lead)) {
+			t.Errorf("got event %+v, want %q for ticket 1 at %v", got[0], EVENT_STARTING_SOON, scheduledFor.Add(-lead))
+		}
+	})
+
+	t.Run("fires only once per ticket", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := NewKitchenServer(newStore(), WithClock(clock), WithStartSoonLeadTime(lead))
+		events := server.events.subscribe()
+
+		clock.Advance(time.Hour - lead)
+		server.NotifyStartingSoon()
+		clock.Advance(time.Minute)
+		notified, _ := server.NotifyStartingSoon()
+
+		if notified != 0 {
+			t.Errorf("got %d tickets notified, want 0", notified)
+		}
+		if got := drainEvents(events); len(got) != 1 {
+			t.Errorf("got %d events, want 1", len(got))
+		}
+	})
+
+	t.Run("fires again when the ticket is rescheduled", func(t *testing.T) {
+		store := newStore()
+		clock := &StubClock{now}
+		server := NewKitchenServer(store, WithClock(clock), WithStartSoonLeadTime(lead))
+
+		clock.Advance(time.Hour - lead)
+		server.NotifyStartingSoon()
+
+		rescheduledFor := scheduledFor.Add(-time.Minute)
+		store.tickets[0].ScheduledFor = &rescheduledFor
+		notified, _ := server.NotifyStartingSoon()
+
+		if notified != 1 {
+			t.Errorf("got %d tickets notified, want 1", notified)
+		}
+	})
+
+	t.Run("doesn't fire without a lead time", func(t *testing.T) {
+		clock := &StubClock{now}
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		clock.Advance(time.Hour - time.Minute)
+		notified, _ := server.NotifyStartingSoon()
+
+		if notified != 0 {
+			t.Errorf("got %d tickets notified, want 0", notified)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StationRule struct {
+	Start   time.Duration
+	End     time.Duration
+	Station string
+}
+
+func (s StationRule) Contains(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+
+	if s.Start <= s.End {
+		return offset >= s.Start && offset < s.End
+	}
+
+	return offset >= s.Start || offset < s.End
+}
+
+func ParseStationRules(rules string) ([]StationRule, error) {
+	parsed := []StationRule{}
+	for _, rule := range strings.Split(rules, ",") {
+		window, station, found := strings.Cut(rule, "=")
+		start, end, ok := strings.Cut(window, "-")
+		if !found || !ok || station == "" {
+			return nil, fmt.Errorf("invalid station rule %q, want HH:MM-HH:MM=station", rule)
+		}
+
+		startOffset, err := parseTimeOfDay(start)
+		if err != nil {
+			return nil, err
+		}
+
+		endOffset, err := parseTimeOfDay(end)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed = append(parsed, StationRule{Start: startOffset, End: endOffset, Station: station})
+	}
+
+	return parsed, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, %v", value, err)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (k *KitchenServer) defaultStation(now time.Time) string {
+	for _, rule := range k.stationRules {
+		if rule.Contains(k.localTime(now)) {
+			return rule.Station
+		}
+	}
+
+	return ""
+}
+
+// itemStation is the station that prepares item, its StationOverride when set
+// and otherwise the ticket's station.
+func itemStation(ticket Ticket, item Item) string {
+	if item.StationOverride != "" {
+		return item.StationOverride
+	}
+
+	return ticket.Station
+}
+
+func involvesStation(ticket Ticket, station string) bool {
+	if len(ticket.Items) == 0 {
+		return ticket.Station == station
+	}
+
+	for _, item := range ticket.Items {
+		if itemStation(ticket, item) == station {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k *KitchenServer) checkStationOverrides(ticket Ticket) error {
+	if len(k.stations) == 0 {
+		return nil
+	}
+
+	for _, item := range ticket.Items {
+		if item.StationOverride != "" && !k.stations[item.StationOverride] {
+			return newValidationError(CODE_UNKNOWN_STATION, "item %q overrides to unknown station %q", item.Name, item.StationOverride)
+		}
+	}
+
+	return nil
+}
+
+const autoAcceptedReason = "auto-accepted"
+
+func ValidateAutoAcceptStations(defaultStatus Status, stations []string) error {
+	if len(stations) > 0 && defaultStatus != STATUS_PENDING {
+		return fmt.Errorf("auto-accept stations need tickets to start %v, default status is %v", STATUS_PENDING, defaultStatus)
+	}
+
+	for _, station := range stations {
+		if station == "" {
+			return fmt.Errorf("auto-accept station can't be empty")
+		}
+	}
+
+	return nil
+}
+
+func (k *KitchenServer) autoAccepts(ticket Ticket) bool {
+	return ticket.Station != "" && k.autoAcceptStations[ticket.Station]
+}
+
+func ParseStationCapacities(pairs string) (map[string]int, error) {
+	capacities := map[string]int{}
+	for _, pair := range strings.Split(pairs, ",") {
+		station, value, found := strings.Cut(pair, "=")
+		station = strings.TrimSpace(station)
+		capacity, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || station == "" || err != nil || capacity < 1 {
+			return nil, fmt.Errorf("invalid station capacity %q, want station=tickets with at least 1 ticket", pair)
+		}
+
+		capacities[station] = capacity
+	}
+
+	return capacities, nil
+}
+
+func (k *KitchenServer) stationFull(store KitchenStore, station string) (int, bool, error) {
+	capacity, ok := k.stationCapacities[station]
+	if !ok {
+		return 0, false, nil
+	}
+
+	active, err := store.CountTickets(TicketFilter{Station: station, Statuses: []Status{STATUS_ACCEPTED}})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return capacity, active >= capacity, nil
+}
+
+func (k *KitchenServer) rejectFullStation(w http.ResponseWriter, store KitchenStore, station string) bool {
+	capacity, full, err := k.stationFull(store, station)
+	if err != nil {
+		k.logger.Error("unable to count station tickets", "station", station, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return true
+	}
+
+	if full {
+		k.writeStationFull(w, station, capacity)
+		return true
+	}
+
+	return false
+}
+
+func (k *KitchenServer) writeStationFull(w http.ResponseWriter, station string, capacity int) {
+	k.writeError(w, http.StatusConflict, CODE_STATION_FULL, fmt.Sprintf("station %q is working its limit of %d tickets, try again once one completes", station, capacity))
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDefaultStation(t *testing.T) {
+	rules := []StationRule{
+		{Start: 6 * time.Hour, End: 11 * time.Hour, Station: "breakfast"},
+		{Start: 17 * time.Hour, End: 2 * time.Hour, Station: "dinner"},
+	}
+	newTicket := func(station string) Ticket {
+		return Ticket{Station: station, Items: []Item{{Name: "eggs", Quantity: 2, Unit: UNIT_EACH}}}
+	}
+
+	cases := []struct {
+		name    string
+		at      time.Time
+		station string
+		want    string
+	}{
+		{"morning tickets go to breakfast", time.Date(2023, time.June, 1, 8, 30, 0, 0, time.UTC), "", "breakfast"},
+		{"evening tickets go to dinner", time.Date(2023, time.June, 1, 19, 0, 0, 0, time.UTC), "", "dinner"},
+		{"windows wrap past midnight", time.Date(2023, time.June, 2, 1, 0, 0, 0, time.UTC), "", "dinner"},
+		{"no window leaves station empty", time.Date(2023, time.June, 1, 14, 0, 0, 0, time.UTC), "", ""},
+		{"explicit station wins", time.Date(2023, time.June, 1, 8, 30, 0, 0, time.UTC), "grill", "grill"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			store := &StubKitchenStore{}
+			server := NewKitchenServer(store, WithClock(&StubClock{c.at}), WithStationRules(rules...))
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newCreateTicketRequest(newTicket(c.station)))
+
+			assertStatus(t, response.Code, http.StatusAccepted)
+			if store.tickets[0].Station != c.want {
+				t.Errorf("got station %q, want %q", store.tickets[0].Station, c.want)
+			}
+		})
+	}
+}
+
+func TestParseStationRules(t *testing.T) {
+	t.Run("parses windows", func(t *testing.T) {
+		got, err := ParseStationRules("06:00-11:00=breakfast,17:30-02:00=dinner")
+		if err != nil {
+			t.Fatalf("unable to parse rules, %v", err)
+		}
+
+		want := []StationRule{
+			{Start: 6 * time.Hour, End: 11 * time.Hour, Station: "breakfast"},
+			{Start: 17*time.Hour + 30*time.Minute, End: 2 * time.Hour, Station: "dinner"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("rejects malformed rules", func(t *testing.T) {
+		for _, rules := range []string{"breakfast", "06:00=breakfast", "6am-11am=breakfast", "06:00-11:00="} {
+			if _, err := ParseStationRules(rules); err == nil {
+				t.Errorf("expected an error parsing %q but didn't get one", rules)
+			}
+		}
+	})
+}
+
+func TestAutoAcceptStations(t *testing.T) {
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	newTicket := func(station string) Ticket {
+		return Ticket{Station: station, Items: []Item{{Name: "coffee", Quantity: 1, Unit: UNIT_EACH}}}
+	}
+
+	t.Run("accepts tickets for auto-accept stations", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithClock(clock), WithAutoAcceptStations("bar"))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(newTicket("bar")))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if store.tickets[0].Status != STATUS_ACCEPTED {
+			t.Errorf("got status %v, want %v", store.tickets[0].Status, STATUS_ACCEPTED)
+		}
+
+		assertEvents(t, store.events, []TicketEvent{
+			{Type: EVENT_CREATED, TicketID: 0, Status: STATUS_ACCEPTED, OccurredAt: clock.now},
+			{Type: EVENT_ACCEPTED, TicketID: 0, Status: STATUS_ACCEPTED, Reason: autoAcceptedReason, OccurredAt: clock.now},
+		})
+	})
+
+	t.Run("leaves other stations pending", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithClock(clock), WithAutoAcceptStations("bar"))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(newTicket("grill")))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		if store.tickets[0].Status != STATUS_PENDING {
+			t.Errorf("got status %v, want %v", store.tickets[0].Status, STATUS_PENDING)
+		}
+		if len(store.events) != 1 {
+			t.Errorf("got events %v, want only the created event", store.events)
+		}
+	})
+
+	t.Run("applies to stations assigned by rules", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		rule := StationRule{Start: 0, End: 24 * time.Hour, Station: "bar"}
+		server := NewKitchenServer(store, WithClock(clock), WithStationRules(rule), WithAutoAcceptStations("bar"))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(newTicket("")))
+
+		if store.tickets[0].Status != STATUS_ACCEPTED {
+			t.Errorf("got status %v, want %v", store.tickets[0].Status, STATUS_ACCEPTED)
+		}
+	})
+}
+
+func TestValidateAutoAcceptStations(t *testing.T) {
+	t.Run("accepts stations with a pending default status", func(t *testing.T) {
+		if err := ValidateAutoAcceptStations(STATUS_PENDING, []string{"bar"}); err != nil {
+			t.Errorf("got error %v, want none", err)
+		}
+	})
+
+	t.Run("rejects stations with another default status", func(t *testing.T) {
+		if err := ValidateAutoAcceptStations(STATUS_ACCEPTED, []string{"bar"}); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("rejects an empty station", func(t *testing.T) {
+		if err := ValidateAutoAcceptStations(STATUS_PENDING, []string{""}); err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+}
+
+// SlowCountKitchenStore holds every count back after taking it, so that a
+// capacity check made on a count ahead of the write races with the other
+// requests.
+type SlowCountKitchenStore struct {
+	*InMemoryKitchenStore
+	delay time.Duration
+}
+
+func (s *SlowCountKitchenStore) CountTickets(filter TicketFilter) (int, error) {
+	count, err := s.InMemoryKitchenStore.CountTickets(filter)
+	time.Sleep(s.delay)
+	return count, err
+}
+
+func TestStationCapacities(t *testing.T) {
+	newServer := func(t testing.TB) *KitchenServer {
+		t.Helper()
+
+		store := NewInMemoryKitchenStore()
+		for _, station := range []string{"grill", "grill", "grill", "fryer"} {
+			store.StoreTicket(Ticket{Station: station, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		}
+
+		capacities, err := ParseStationCapacities("grill=2,fryer=2")
+		if err != nil {
+			t.Fatalf("unable to parse station capacities, %v", err)
+		}
+
+		return NewKitchenServer(store, WithStationCapacities(capacities), WithLongPollTimeout(0))
+	}
+
+	accept := func(t testing.TB, server *KitchenServer, ticketID int) *httptest.ResponseRecorder {
+		t.Helper()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newAcceptTicketRequest(ticketID))
+		return response
+	}
+
+	t.Run("rejects accepting past a station's capacity until a ticket completes", func(t *testing.T) {
+		server := newServer(t)
+
+		assertStatus(t, accept(t, server, 1).Code, http.StatusOK)
+		assertStatus(t, accept(t, server, 2).Code, http.StatusOK)
+
+		response := accept(t, server, 3)
+		assertStatus(t, response.Code, http.StatusConflict)
+		assertErrorCode(t, response, CODE_STATION_FULL)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(1))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		assertStatus(t, accept(t, server, 3).Code, http.StatusOK)
+	})
+
+	t.Run("rejects claiming the next ticket for a full station", func(t *testing.T) {
+		server := newServer(t)
+		accept(t, server, 1)
+		accept(t, server, 2)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newNextTicketRequest("grill"))
+
+		assertStatus(t, response.Code, http.StatusConflict)
+		assertErrorCode(t, response, CODE_STATION_FULL)
+	})
+
+	t.Run("keeps concurrent accepts within the station capacity", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		for range 10 {
+			store.StoreTicket(Ticket{Station: "grill", Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		}
+
+		slow := &SlowCountKitchenStore{InMemoryKitchenStore: store, delay: 10 * time.Millisecond}
+		server := NewKitchenServer(slow, WithStationCapacities(map[string]int{"grill": 2}), WithLongPollTimeout(0))
+
+		var wg sync.WaitGroup
+		for ticketID := 1; ticketID <= 10; ticketID++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				accept(t, server, ticketID)
+			}()
+		}
+		wg.Wait()
+
+		if accepted, _ := store.CountTickets(TicketFilter{Statuses: []Status{STATUS_ACCEPTED}}); accepted != 2 {
+			t.Errorf("got %d tickets accepted, want 2", accepted)
+		}
+	})
+
+	t.Run("leaves other stations and reads alone", func(t *testing.T) {
+		server := newServer(t)
+		accept(t, server, 1)
+		accept(t, server, 2)
+
+		assertStatus(t, accept(t, server, 4).Code, http.StatusOK)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(3))
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("rejects invalid capacities", func(t *testing.T) {
+		for _, pairs := range []string{"grill", "grill=0", "=2", "grill=two"} {
+			if _, err := ParseStationCapacities(pairs); err == nil {
+				t.Errorf("expected an error parsing %q but didn't get one", pairs)
+			}
+		}
+	})
+}
+
+func TestStationOverride(t *testing.T) {
+	grillTicket := func(items ...Item) Ticket {
+		return Ticket{OrderID: "order-1", Station: "grill", Items: items}
+	}
+	burger := Item{Name: "burger", Quantity: 1, Unit: UNIT_EACH}
+	fries := Item{Name: "fries", Quantity: 1, Unit: UNIT_EACH, StationOverride: "fryer"}
+
+	t.Run("routes an overridden item to its station", func(t *testing.T) {
+		ticket := grillTicket(burger, fries)
+
+		if !involvesStation(ticket, "fryer") {
+			t.Errorf("expected ticket to involve the fryer but it didn't")
+		}
+		if !involvesStation(ticket, "grill") {
+			t.Errorf("expected ticket to involve the grill but it didn't")
+		}
+	})
+
+	t.Run("leaves the ticket station when every item is overridden", func(t *testing.T) {
+		ticket := grillTicket(fries)
+
+		if involvesStation(ticket, "grill") {
+			t.Errorf("expected ticket not to involve the grill but it did")
+		}
+	})
+
+	t.Run("lists overridden tickets under their item's station", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store, WithStations("grill", "fryer"))
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(grillTicket(burger)))
+		server.ServeHTTP(httptest.NewRecorder(), newCreateTicketRequest(grillTicket(burger, fries)))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?station=fryer"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		page := getTicketPageFromResponse(t, response.Body)
+		if len(page.Tickets) != 1 || page.Tickets[0].ID != 2 {
+			t.Errorf("got tickets %+v, want only ticket 2", page.Tickets)
+		}
+	})
+
+	t.Run("rejects an override to an unknown station", func(t *testing.T) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store, WithStations("grill", "fryer"))
+
+		smoker := Item{Name: "brisket", Quantity: 1, Unit: UNIT_EACH, StationOverride: "smoker"}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(grillTicket(burger, smoker)))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_UNKNOWN_STATION)
+		if len(store.tickets) != 0 {
+			t.Errorf("got %d tickets stored, want 0", len(store.tickets))
+		}
+	})
+}
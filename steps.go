@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type Step struct {
+	Name string
+	Done bool
+}
+
+type CompleteRequest struct {
+	CompletedAt *time.Time
+}
+
+type StepCheck struct {
+	Item int
+	Step string
+}
+
+func ticketStepProgress(ticket Ticket) (done, total int) {
+	for _, item := range ticket.Items {
+		for _, step := range item.Steps {
+			total++
+			if step.Done {
+				done++
+			}
+		}
+	}
+
+	return done, total
+}
+
+// undoSteps returns a copy of items with every step left to do again.
+func undoSteps(items Items) Items {
+	undone := make(Items, len(items))
+	copy(undone, items)
+	for i, item := range undone {
+		if item.Steps == nil {
+			continue
+		}
+
+		steps := make([]Step, len(item.Steps))
+		for j, step := range item.Steps {
+			steps[j] = Step{Name: step.Name}
+		}
+		undone[i].Steps = steps
+	}
+
+	return undone
+}
+
+func (k *KitchenServer) checkStep(w http.ResponseWriter, r *http.Request, ticketID int) {
+	check := StepCheck{}
+	err := decodeRequestBody(r.Body, &check)
+	if err != nil || check.Step == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	store := k.storeFor(r)
+	ticket, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if check.Item < 0 || check.Item >= len(ticket.Items) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	item := ticket.Items[check.Item]
+	steps := make([]Step, len(item.Steps))
+	copy(steps, item.Steps)
+
+	found := false
+	for i := range steps {
+		if steps[i].Name == check.Step {
+			steps[i].Done = true
+			found = true
+		}
+	}
+
+	if !found {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	items := make(Items, len(ticket.Items))
+	copy(items, ticket.Items)
+	items[check.Item].Steps = steps
+	ticket.Items = items
+	ticket.UpdatedAt = now
+
+	err = k.updateTicket(store, ticket, TicketEvent{
+		Type:       EVENT_UPDATED,
+		TicketID:   ticketID,
+		Status:     ticket.Status,
+		Reason:     fmt.Sprintf("%s: %s done", item.Name, check.Step),
+		OccurredAt: now,
+	})
+	if err != nil {
+		k.logger.Error("unable to check step", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+}
+
+func (k *KitchenServer) completeTicket(w http.ResponseWriter, r *http.Request, ticketID int) {
+	request := CompleteRequest{}
+	err := decodeRequestBody(r.Body, &request)
+	if err != nil && !errors.Is(err, errEmptyBody) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if request.CompletedAt != nil {
+		if err := k.checkNotFuture("CompletedAt", *request.CompletedAt); err != nil {
+			k.writeValidationError(w, err)
+			return
+		}
+	}
+
+	store := k.storeFor(r)
+	ticket, err := store.GetTicketByID(ticketID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !containsStatus(activeStatuses, ticket.Status) {
+		k.writeError(w, http.StatusConflict, CODE_INVALID_TRANSITION, fmt.Sprintf("can't complete %v ticket", ticket.Status))
+		return
+	}
+
+	if done, total := ticketStepProgress(ticket); done < total {
+		k.writeError(w, http.StatusConflict, CODE_STEPS_INCOMPLETE, fmt.Sprintf("%d of %d steps are done", done, total))
+		return
+	}
+
+	if !k.authorize(w, r, ACTION_UPDATE, ticket) {
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	now := k.clock.Now()
+	completedAt := now
+	if request.CompletedAt != nil {
+		completedAt = *request.CompletedAt
+	}
+	ticket.Status = STATUS_COMPLETED
+	ticket.CompletedAt = &completedAt
+	ticket.UpdatedAt = now
+
+	err = k.updateTicket(store, ticket, TicketEvent{
+		Type:       EVENT_COMPLETED,
+		TicketID:   ticketID,
+		Status:     ticket.Status,
+		OccurredAt: now,
+	})
+	if err != nil {
+		k.logger.Error("unable to complete ticket", "ticket_id", ticketID, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, k.newTicketResponse(ticket))
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestItemSteps(t *testing.T) {
+	clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{{ID: 0, Status: STATUS_ACCEPTED, Items: []Item{
+				{Name: "chicken", Quantity: 1, Unit: UNIT_EACH, Steps: []Step{{Name: "marinate"}, {Name: "grill"}}},
+				{Name: "salad", Quantity: 1, Unit: UNIT_EACH},
+			}}},
+		}
+	}
+
+	t.Run("blocks completion until the last step is checked", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(0))
+		assertStatus(t, response.Code, http.StatusConflict)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newCheckStepRequest(0, StepCheck{Item: 0, Step: "marinate"}))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(0))
+		assertStatus(t, response.Code, http.StatusConflict)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newCheckStepRequest(0, StepCheck{Item: 0, Step: "grill"}))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(0))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		if store.tickets[0].Status != STATUS_COMPLETED {
+			t.Errorf("got status %v, want %v", store.tickets[0].Status, STATUS_COMPLETED)
+		}
+	})
+
+	t.Run("surfaces step progress on GET", func(t *testing.T) {
+		store := newStore()
+		server := NewKitchenServer(store, WithClock(clock))
+		server.ServeHTTP(httptest.NewRecorder(), newCheckStepRequest(0, StepCheck{Item: 0, Step: "grill"}))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(0))
+
+		got := TicketResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+
+		if got.StepsDone != 1 || got.StepsTotal != 2 {
+			t.Errorf("got %d of %d steps done, want 1 of 2", got.StepsDone, got.StepsTotal)
+		}
+		if !got.Items[0].Steps[1].Done {
+			t.Errorf("got grill step not done, want done")
+		}
+	})
+
+	t.Run("returns Bad Request for unknown step or item", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(clock))
+
+		for _, check := range []StepCheck{{Item: 0, Step: "fry"}, {Item: 5, Step: "grill"}, {Item: 1, Step: "grill"}} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newCheckStepRequest(0, check))
+			assertStatus(t, response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns Conflict completing a cancelled ticket", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 0, Status: STATUS_CANCELLED}}}
+		server := NewKitchenServer(store, WithClock(clock))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(0))
+
+		assertStatus(t, response.Code, http.StatusConflict)
+	})
+}
+
+func newCheckStepRequest(ticketID int, check StepCheck) *http.Request {
+	buffer := &bytes.Buffer{}
+	json.NewEncoder(buffer).Encode(check)
+
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/%d/steps", ticketID), buffer)
+	return req
+}
+
+func TestCompletedAt(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	newServer := func() (*KitchenServer, *StubKitchenStore) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 0, Status: STATUS_ACCEPTED, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}}}
+		return NewKitchenServer(store, WithClock(&StubClock{now}), WithClockSkew(5*time.Second)), store
+	}
+
+	complete := func(t testing.TB, server *KitchenServer, completedAt time.Time) *httptest.ResponseRecorder {
+		t.Helper()
+
+		body := &bytes.Buffer{}
+		json.NewEncoder(body).Encode(CompleteRequest{CompletedAt: &completedAt})
+		request, _ := http.NewRequest(http.MethodPost, "/ticket/0/complete", body)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("records a client supplied completion time", func(t *testing.T) {
+		server, store := newServer()
+		completedAt := now.Add(-time.Minute)
+
+		response := complete(t, server, completedAt)
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got, _ := store.GetTicketByID(0)
+		if got.CompletedAt == nil || !got.CompletedAt.Equal(completedAt) {
+			t.Errorf("got completed at %v, want %v", got.CompletedAt, completedAt)
+		}
+		if !got.UpdatedAt.Equal(now) {
+			t.Errorf("got updated at %v, want %v", got.UpdatedAt, now)
+		}
+	})
+
+	t.Run("accepts a time within the clock skew", func(t *testing.T) {
+		server, _ := newServer()
+
+		assertStatus(t, complete(t, server, now.Add(3*time.Second)).Code, http.StatusOK)
+	})
+
+	t.Run("rejects a completion time in the future", func(t *testing.T) {
+		server, store := newServer()
+
+		response := complete(t, server, now.Add(time.Hour))
+
+		assertStatus(t, response.Code, http.StatusUnprocessableEntity)
+		assertErrorCode(t, response, CODE_FUTURE_TIMESTAMP)
+		if got, _ := store.GetTicketByID(0); got.Status != STATUS_ACCEPTED {
+			t.Errorf("got status %v, want ticket left accepted", got.Status)
+		}
+	})
+
+	t.Run("defaults the completion time to now", func(t *testing.T) {
+		server, store := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(0))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got, _ := store.GetTicketByID(0)
+		if got.CompletedAt == nil || !got.CompletedAt.Equal(now) {
+			t.Errorf("got completed at %v, want %v", got.CompletedAt, now)
+		}
+	})
+}
+
+func newCompleteTicketRequest(ticketID int) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/ticket/%d/complete", ticketID), nil)
+	return req
+}
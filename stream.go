@@ -0,0 +1,53 @@
+package main
+
+import "net/http"
+
+const streamFlushEvery = 100
+
+func (k *KitchenServer) streamTickets(w http.ResponseWriter, r *http.Request, filter TicketFilter) {
+	controller := http.NewResponseController(w)
+	started := false
+	written := 0
+
+	err := k.storeFor(r).StreamTickets(r.Context(), func(ticket Ticket) error {
+		if !filter.Matches(ticket) {
+			return nil
+		}
+
+		data, err := k.marshalJSON(ticket)
+		if err != nil {
+			return err
+		}
+
+		separator := ","
+		if !started {
+			w.WriteHeader(http.StatusOK)
+			started = true
+			separator = "["
+		}
+		if _, err := w.Write(append([]byte(separator), data...)); err != nil {
+			return err
+		}
+
+		written++
+		if written%streamFlushEvery == 0 {
+			controller.Flush()
+		}
+
+		return nil
+	})
+	if err != nil {
+		k.logger.Error("unable to stream tickets", "written", written, "error", err)
+		if !started {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if !started {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("[]\n"))
+		return
+	}
+	w.Write([]byte("]\n"))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamTickets(t *testing.T) {
+	t.Run("streams a large list as a complete JSON array", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		for range 5000 {
+			store.StoreTicket(Ticket{Status: STATUS_PENDING, Items: []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+		}
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?stream=true"))
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var got []Ticket
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("unable to parse streamed array, %v", err)
+		}
+
+		if len(got) != 5000 {
+			t.Fatalf("got %d tickets, want 5000", len(got))
+		}
+		for i, ticket := range got {
+			if ticket.ID != i+1 {
+				t.Fatalf("got ticket %d at position %d, want %d", ticket.ID, i, i+1)
+			}
+		}
+	})
+
+	t.Run("applies the list filter", func(t *testing.T) {
+		store := &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_PENDING},
+				{ID: 2, Status: STATUS_ACCEPTED},
+			},
+		}
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?stream=true&status=accepted"))
+
+		var got []Ticket
+		json.NewDecoder(response.Body).Decode(&got)
+		assertTickets(t, got, store.tickets[1:])
+	})
+
+	t.Run("streams an empty array", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?stream=true"))
+
+		if response.Body.String() != "[]\n" {
+			t.Errorf("got body %q, want an empty array", response.Body.String())
+		}
+	})
+}
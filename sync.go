@@ -0,0 +1,14 @@
+package main
+
+import "time"
+
+func maxUpdatedAt(tickets []Ticket, since time.Time) *time.Time {
+	latest := since
+	for _, ticket := range tickets {
+		if ticket.UpdatedAt.After(latest) {
+			latest = ticket.UpdatedAt
+		}
+	}
+
+	return &latest
+}
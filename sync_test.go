@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUpdatedSinceSync(t *testing.T) {
+	morning := time.Date(2023, time.June, 2, 9, 0, 0, 0, time.UTC)
+	noon := time.Date(2023, time.June, 2, 12, 0, 0, 0, time.UTC)
+
+	newServer := func() (*KitchenServer, *StubClock) {
+		store := NewInMemoryKitchenStore()
+		for _, name := range []string{"burger", "fries", "shake"} {
+			store.StoreTicket(Ticket{Items: Items{{Name: name, Quantity: 1, Unit: UNIT_EACH}}, UpdatedAt: morning})
+		}
+
+		clock := &StubClock{now: noon}
+		return NewKitchenServer(store, WithClock(clock)), clock
+	}
+
+	syncPage := func(t testing.TB, server *KitchenServer, since time.Time) TicketPage {
+		t.Helper()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?updatedSince="+since.Format(time.RFC3339Nano)))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		return getTicketPageFromResponse(t, response.Body)
+	}
+
+	t.Run("returns everything updated after the cursor with the next cursor", func(t *testing.T) {
+		server, _ := newServer()
+
+		page := syncPage(t, server, morning.Add(-time.Minute))
+
+		if len(page.Tickets) != 3 {
+			t.Fatalf("got %d tickets, want 3", len(page.Tickets))
+		}
+		if page.MaxUpdatedAt == nil || !page.MaxUpdatedAt.Equal(morning) {
+			t.Errorf("got max updated at %v, want %v", page.MaxUpdatedAt, morning)
+		}
+	})
+
+	t.Run("returns only the changes since the last sync, including deletions", func(t *testing.T) {
+		server, clock := newServer()
+		cursor := *syncPage(t, server, morning.Add(-time.Minute)).MaxUpdatedAt
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCompleteTicketRequest(2))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		clock.now = noon.Add(time.Minute)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newDeleteTicketRequest(3, ""))
+		assertStatus(t, response.Code, http.StatusNoContent)
+
+		page := syncPage(t, server, cursor)
+
+		if len(page.Tickets) != 2 {
+			t.Fatalf("got tickets %v, want the completed and deleted tickets", page.Tickets)
+		}
+		if page.Tickets[0].ID != 2 || page.Tickets[0].Status != STATUS_COMPLETED || page.Tickets[0].Deleted {
+			t.Errorf("got %+v, want ticket 2 completed", page.Tickets[0])
+		}
+		if page.Tickets[1].ID != 3 || !page.Tickets[1].Deleted {
+			t.Errorf("got %+v, want ticket 3 deleted", page.Tickets[1])
+		}
+		if !page.MaxUpdatedAt.Equal(clock.now) {
+			t.Errorf("got max updated at %v, want %v", page.MaxUpdatedAt, clock.now)
+		}
+
+		page = syncPage(t, server, *page.MaxUpdatedAt)
+		if len(page.Tickets) != 0 {
+			t.Errorf("got tickets %v, want nothing new", page.Tickets)
+		}
+		if !page.MaxUpdatedAt.Equal(clock.now) {
+			t.Errorf("got max updated at %v, want the cursor kept", page.MaxUpdatedAt)
+		}
+	})
+
+	t.Run("reports purged tickets as deletions until the next purge", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		store.StoreTicket(Ticket{Status: STATUS_COMPLETED, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}, UpdatedAt: morning})
+
+		clock := &StubClock{now: noon}
+
+		server := NewKitchenServer(store, WithClock(clock), WithAdmin(true))
+		server.AdminHandler().ServeHTTP(httptest.NewRecorder(), newPurgeCompletedRequest(morning.Add(time.Minute).Format(time.RFC3339)))
+
+		page := syncPage(t, server, morning)
+		if len(page.Tickets) != 1 || !page.Tickets[0].Deleted || !page.Tickets[0].UpdatedAt.Equal(noon) {
+			t.Fatalf("got tickets %v, want the purged ticket as a tombstone", page.Tickets)
+		}
+
+		clock.now = noon.Add(time.Hour)
+		server.AdminHandler().ServeHTTP(httptest.NewRecorder(), newPurgeCompletedRequest(noon.Add(time.Minute).Format(time.RFC3339)))
+
+		page = syncPage(t, server, morning)
+		if len(page.Tickets) != 0 {
+			t.Errorf("got tickets %v, want the tombstone dropped by the next purge", page.Tickets)
+		}
+	})
+
+	t.Run("hides deleted tickets outside of a sync", func(t *testing.T) {
+		server, _ := newServer()
+		server.ServeHTTP(httptest.NewRecorder(), newDeleteTicketRequest(1, ""))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(1))
+		assertStatus(t, response.Code, http.StatusNotFound)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest(""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		page := getTicketPageFromResponse(t, response.Body)
+		if len(page.Tickets) != 2 || page.MaxUpdatedAt != nil {
+			t.Errorf("got page %+v, want the two remaining tickets", page)
+		}
+	})
+
+	t.Run("returns Bad Request on an invalid cursor", func(t *testing.T) {
+		server, _ := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newListTicketsRequest("?updatedSince=yesterday"))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const taxRateScale = 1_000_000
+
+type TaxRate int64
+
+func ParseTaxRate(percent string) (TaxRate, error) {
+	rate, err := strconv.ParseFloat(percent, 64)
+	if err != nil || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return 0, fmt.Errorf("invalid tax rate %q, want a percentage like 8.875", percent)
+	}
+
+	if rate < 0 {
+		return 0, fmt.Errorf("tax rate must not be negative, got %v", rate)
+	}
+
+	if rate > 100 {
+		return 0, fmt.Errorf("tax rate must be at most 100, got %v", rate)
+	}
+
+	return TaxRate(math.Round(rate * taxRateScale / 100)), nil
+}
+
+func ParseTaxCategories(pairs string) (map[string]TaxRate, error) {
+	categories := map[string]TaxRate{}
+	for _, pair := range strings.Split(pairs, ",") {
+		category, percent, found := strings.Cut(pair, "=")
+		category = strings.TrimSpace(category)
+		if !found || category == "" {
+			return nil, fmt.Errorf("invalid tax category %q, want category=percentage", pair)
+		}
+
+		rate, err := ParseTaxRate(strings.TrimSpace(percent))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tax category %q, %v", pair, err)
+		}
+		categories[category] = rate
+	}
+
+	return categories, nil
+}
+
+func (t TaxRate) Of(cents int64) (int64, error) {
+	if t == 0 || cents == 0 {
+		return 0, nil
+	}
+
+	if cents > (math.MaxInt64-taxRateScale/2)/int64(t) {
+		return 0, fmt.Errorf("tax on %d overflows", cents)
+	}
+
+	return (cents*int64(t) + taxRateScale/2) / taxRateScale, nil
+}
+
+type TicketTotals struct {
+	SubtotalCents   int64
+	TaxCents        int64
+	GrandTotalCents int64
+}
+
+func ticketTotals(ticket Ticket, rate TaxRate, categories map[string]TaxRate) (TicketTotals, error) {
+	totals := TicketTotals{}
+
+	total, err := ticketTotal(ticket)
+	if err != nil {
+		return TicketTotals{}, err
+	}
+	if total != nil {
+		totals.SubtotalCents = total.Amount
+	}
+
+	subtotals := map[TaxRate]int64{}
+	for _, item := range ticket.Items {
+		if item.Price == nil {
+			continue
+		}
+
+		itemRate := rate
+		if item.TaxCategory != "" {
+			itemRate = categories[item.TaxCategory]
+		}
+		subtotals[itemRate] += item.Price.Amount
+	}
+
+	for itemRate, subtotal := range subtotals {
+		tax, err := itemRate.Of(subtotal)
+		if err != nil {
+			return TicketTotals{}, err
+		}
+
+		totals.TaxCents, err = addCents(totals.TaxCents, tax)
+		if err != nil {
+			return TicketTotals{}, err
+		}
+	}
+
+	totals.GrandTotalCents, err = addCents(totals.SubtotalCents, totals.TaxCents, ticket.TipCents)
+	if err != nil {
+		return TicketTotals{}, err
+	}
+
+	return totals, nil
+}
+
+func (k *KitchenServer) checkTaxCategories(ticket Ticket) error {
+	for _, item := range ticket.Items {
+		if _, ok := k.taxCategories[item.TaxCategory]; item.TaxCategory != "" && !ok {
+			return newValidationError(CODE_UNKNOWN_TAX_CATEGORY, "item %q has unknown tax category %q", item.Name, item.TaxCategory)
+		}
+	}
+
+	return nil
+}
+
+func addCents(amounts ...int64) (int64, error) {
+	sum := int64(0)
+	for _, amount := range amounts {
+		if amount > math.MaxInt64-sum {
+			return 0, fmt.Errorf("total overflows")
+		}
+		sum += amount
+	}
+
+	return sum, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTaxRate(t *testing.T) {
+	cases := []struct {
+		rate  string
+		cents int64
+		want  int64
+	}{
+		{"20", 1250, 250},
+		{"8.875", 1000, 89},
+		{"17.5", 999, 175},
+		{"5", 10, 1},
+		{"5", 9, 0},
+		{"20", 1, 0},
+		{"0", 1250, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.rate+"% rounds to the nearest cent", func(t *testing.T) {
+			rate, err := ParseTaxRate(c.rate)
+			if err != nil {
+				t.Fatalf("unable to parse tax rate, %v", err)
+			}
+
+			got, err := rate.Of(c.cents)
+			if err != nil {
+				t.Fatalf("unable to compute tax, %v", err)
+			}
+
+			if got != c.want {
+				t.Errorf("got tax %d on %d, want %d", got, c.cents, c.want)
+			}
+		})
+	}
+
+	for _, rate := range []string{"-1", "101", "ten", "NaN"} {
+		t.Run("rejects rate "+rate, func(t *testing.T) {
+			if _, err := ParseTaxRate(rate); err == nil {
+				t.Errorf("expected an error but didn't get one")
+			}
+		})
+	}
+}
+
+func TestTicketTotalsWithTaxAndTip(t *testing.T) {
+	rate, _ := ParseTaxRate("17.5")
+
+	t.Run("adds tax and tip to the subtotal", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 0, TipCents: 200, Items: Items{
+			{Name: "burger", Quantity: 1, Unit: UNIT_EACH, Price: &Money{Amount: 950, Currency: "GBP"}},
+			{Name: "fries", Quantity: 1, Unit: UNIT_EACH, Price: &Money{Amount: 349, Currency: "GBP"}},
+		}}}}
+		server := NewKitchenServer(store, WithTaxRate(rate))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(0))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := TicketResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+
+		want := TicketTotals{SubtotalCents: 1299, TaxCents: 227, GrandTotalCents: 1726}
+		if got.TicketTotals != want {
+			t.Errorf("got totals %+v, want %+v", got.TicketTotals, want)
+		}
+		if got.TipCents != 200 {
+			t.Errorf("got tip %d, want 200", got.TipCents)
+		}
+	})
+
+	t.Run("rejects a negative tip", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithTaxRate(rate))
+
+		ticket := Ticket{TipCents: -1, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_INVALID_TIP)
+	})
+}
+
+func TestTaxCategories(t *testing.T) {
+	rate, _ := ParseTaxRate("20")
+	categories, err := ParseTaxCategories("food=8.875, drink=5")
+	if err != nil {
+		t.Fatalf("didn't expect an error but got one, %v", err)
+	}
+
+	t.Run("blends the tax of each category", func(t *testing.T) {
+		ticket := Ticket{Items: Items{
+			{Name: "burger", Quantity: 1, Unit: UNIT_EACH, TaxCategory: "food", Price: &Money{Amount: 1000, Currency: "USD"}},
+			{Name: "fries", Quantity: 1, Unit: UNIT_EACH, TaxCategory: "food", Price: &Money{Amount: 400, Currency: "USD"}},
+			{Name: "cola", Quantity: 1, Unit: UNIT_EACH, TaxCategory: "drink", Price: &Money{Amount: 250, Currency: "USD"}},
+			{Name: "candle", Quantity: 1, Unit: UNIT_EACH, Price: &Money{Amount: 300, Currency: "USD"}},
+		}}
+
+		got, err := ticketTotals(ticket, rate, categories)
+		if err != nil {
+			t.Fatalf("didn't expect an error but got one, %v", err)
+		}
+
+		// 8.875% of 1400 is 124.25, 5% of 250 is 12.5 and 20% of 300 is 60.
+		want := TicketTotals{SubtotalCents: 1950, TaxCents: 124 + 13 + 60, GrandTotalCents: 1950 + 197}
+		if got != want {
+			t.Errorf("got totals %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("serves the blended tax", func(t *testing.T) {
+		store := &StubKitchenStore{tickets: []Ticket{{ID: 0, Items: Items{
+			{Name: "burger", Quantity: 1, Unit: UNIT_EACH, TaxCategory: "food", Price: &Money{Amount: 1000, Currency: "USD"}},
+			{Name: "cola", Quantity: 1, Unit: UNIT_EACH, TaxCategory: "drink", Price: &Money{Amount: 200, Currency: "USD"}},
+		}}}}
+		server := NewKitchenServer(store, WithTaxRate(rate), WithTaxCategories(categories))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetTicketRequest(0))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := TicketResponse{}
+		json.NewDecoder(response.Body).Decode(&got)
+
+		want := TicketTotals{SubtotalCents: 1200, TaxCents: 89 + 10, GrandTotalCents: 1299}
+		if got.TicketTotals != want {
+			t.Errorf("got totals %+v, want %+v", got.TicketTotals, want)
+		}
+	})
+
+	t.Run("rejects an unknown category", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithTaxCategories(categories))
+
+		ticket := Ticket{Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH, TaxCategory: "alcohol"}}}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_UNKNOWN_TAX_CATEGORY)
+	})
+
+	t.Run("accepts a known category", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{}, WithTaxCategories(categories))
+
+		ticket := Ticket{Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH, TaxCategory: "food"}}}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateTicketRequest(ticket))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+	})
+
+	for _, pairs := range []string{"food", "=5", "food=lots", "food=101"} {
+		t.Run("rejects tax categories "+pairs, func(t *testing.T) {
+			if _, err := ParseTaxCategories(pairs); err == nil {
+				t.Errorf("expected an error but didn't get one")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var errTemplateNotFound = errors.New("no template named")
+
+type Template struct {
+	Name      string
+	KitchenID string
+	Items     Items
+}
+
+func templateKey(kitchenID, name string) string {
+	return kitchenID + "/" + name
+}
+
+func (k *KitchenServer) serveTemplates(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/template"), "/")
+
+	switch {
+	case r.Method == http.MethodPost && name == "":
+		k.storeTemplate(w, r)
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && name != "":
+		k.getTemplate(w, r, name)
+	case r.Method == http.MethodDelete && name != "":
+		k.deleteTemplate(w, r, name)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (k *KitchenServer) storeTemplate(w http.ResponseWriter, r *http.Request) {
+	template := Template{}
+	err := decodeRequestBody(r.Body, &template)
+	if err != nil {
+		k.writeError(w, http.StatusBadRequest, CODE_INVALID_JSON, "unable to unmarshal template JSON, "+err.Error())
+		return
+	}
+
+	template.Name = strings.TrimSpace(template.Name)
+	if template.Name == "" || strings.Contains(template.Name, "/") {
+		k.writeError(w, http.StatusBadRequest, CODE_TEMPLATE_NAME_EMPTY, "template needs a name without slashes")
+		return
+	}
+
+	k.canonicalizeItemNames(template.Items)
+	err = k.checkTicket(Ticket{Items: template.Items})
+	if err == nil {
+		template.Items, err = k.handleDuplicateItems(template.Items)
+	}
+	if err != nil {
+		k.writeValidationError(w, err)
+		return
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	err = k.storeFor(r).StoreTemplate(template)
+	if err != nil {
+		k.logger.Error("unable to store template", "name", template.Name, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	k.writeJSON(w, http.StatusCreated, template)
+}
+
+func (k *KitchenServer) getTemplate(w http.ResponseWriter, r *http.Request, name string) {
+	template, err := k.storeFor(r).GetTemplate("", name)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	k.writeJSON(w, http.StatusOK, template)
+}
+
+func (k *KitchenServer) deleteTemplate(w http.ResponseWriter, r *http.Request, name string) {
+	err := k.storeFor(r).DeleteTemplate("", name)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
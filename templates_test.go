@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	usual := Template{Name: "usual-bob", Items: Items{
+		{Name: "burger", Quantity: 1, Unit: UNIT_EACH},
+		{Name: "fries", Quantity: 2, Unit: UNIT_EACH},
+	}}
+
+	newServer := func() (*KitchenServer, *StubKitchenStore) {
+		store := &StubKitchenStore{}
+		server := NewKitchenServer(store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newStoreTemplateRequest(t, usual))
+		assertStatus(t, response.Code, http.StatusCreated)
+
+		return server, store
+	}
+
+	t.Run("stores a template", func(t *testing.T) {
+		server, _ := newServer()
+
+		request, _ := http.NewRequest(http.MethodGet, "/template/usual-bob", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		got := Template{}
+		json.NewDecoder(response.Body).Decode(&got)
+		if got.Name != usual.Name {
+			t.Errorf("got template %q, want %q", got.Name, usual.Name)
+		}
+		assertItems(t, got.Items, usual.Items)
+	})
+
+	t.Run("creates a ticket from a template", func(t *testing.T) {
+		server, store := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRawPostRequest("/ticket/?fromTemplate=usual-bob", `{"OrderID": "42"}`))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		assertItems(t, store.tickets[0].Items, usual.Items)
+		if store.tickets[0].OrderID != "42" {
+			t.Errorf("got order ID %q, want %q", store.tickets[0].OrderID, "42")
+		}
+	})
+
+	t.Run("items in the body override the template", func(t *testing.T) {
+		server, store := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRawPostRequest("/ticket/?fromTemplate=usual-bob", `{"Items": ["salad"]}`))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		assertItems(t, store.tickets[0].Items, Items{{Name: "salad", Quantity: 1, Unit: UNIT_EACH}})
+	})
+
+	t.Run("returns Not Found on unknown template", func(t *testing.T) {
+		server, store := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRawPostRequest("/ticket/?fromTemplate=usual-alice", `{}`))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+		assertErrorCode(t, response, CODE_TEMPLATE_NOT_FOUND)
+		if len(store.tickets) != 0 {
+			t.Errorf("got %d tickets stored, want none", len(store.tickets))
+		}
+	})
+
+	t.Run("deletes a template", func(t *testing.T) {
+		server, store := newServer()
+
+		request, _ := http.NewRequest(http.MethodDelete, "/template/usual-bob", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNoContent)
+		if len(store.templates) != 0 {
+			t.Errorf("got templates %v, want none", store.templates)
+		}
+	})
+
+	t.Run("returns Bad Request on template without a name", func(t *testing.T) {
+		server, _ := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newStoreTemplateRequest(t, Template{Items: usual.Items}))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_TEMPLATE_NAME_EMPTY)
+	})
+
+	t.Run("returns Bad Request on template without items", func(t *testing.T) {
+		server, _ := newServer()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newStoreTemplateRequest(t, Template{Name: "empty"}))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+		assertErrorCode(t, response, CODE_ITEMS_EMPTY)
+	})
+
+	t.Run("keeps templates per kitchen", func(t *testing.T) {
+		store := NewInMemoryKitchenStore()
+		server := NewKitchenServer(store, WithKitchens("london", "paris"))
+
+		request := newStoreTemplateRequest(t, usual)
+		request.URL.Path = "/london/template"
+		server.ServeHTTP(httptest.NewRecorder(), request)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRawPostRequest("/paris/ticket/?fromTemplate=usual-bob", `{}`))
+		assertStatus(t, response.Code, http.StatusNotFound)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newRawPostRequest("/london/ticket/?fromTemplate=usual-bob", `{}`))
+		assertStatus(t, response.Code, http.StatusAccepted)
+	})
+}
+
+func newStoreTemplateRequest(t testing.TB, template Template) *http.Request {
+	t.Helper()
+
+	data, err := json.Marshal(template)
+	if err != nil {
+		t.Fatalf("unable to marshal template, %v", err)
+	}
+
+	return newRawPostRequest("/template", string(data))
+}
+
+func newRawPostRequest(path, body string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	return req
+}
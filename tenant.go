@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type kitchenIDKey struct{}
+
+func (k *KitchenServer) routeKitchen(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	if len(k.kitchens) == 0 {
+		return r, true
+	}
+
+	kitchenID, rest, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if !found || !k.kitchens[kitchenID] {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	r = r.Clone(context.WithValue(r.Context(), kitchenIDKey{}, kitchenID))
+	r.URL.Path = "/" + rest
+
+	return r, true
+}
+
+func (k *KitchenServer) storeFor(r *http.Request) KitchenStore {
+	store := k.store
+	if retrying, ok := store.(*retryingStore); ok {
+		store = retrying.withContext(r.Context())
+	}
+
+	kitchenID, ok := r.Context().Value(kitchenIDKey{}).(string)
+	if !ok {
+		return store
+	}
+
+	return &kitchenStore{KitchenStore: store, kitchenID: kitchenID}
+}
+
+type kitchenStore struct {
+	KitchenStore
+	kitchenID string
+}
+
+func (s *kitchenStore) GetTicketByID(ticketID int) (Ticket, error) {
+	ticket, err := s.KitchenStore.GetTicketByID(ticketID)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	if ticket.KitchenID != s.kitchenID {
+		return Ticket{}, fmt.Errorf("no ticket with ID = %d in kitchen %q", ticketID, s.kitchenID)
+	}
+
+	return ticket, nil
+}
+
+// ownsTicket reports whether ticketID belongs to this kitchen, even once the
+// ticket is deleted and GetTicketByID no longer finds it.
+func (s *kitchenStore) ownsTicket(ticketID int) bool {
+	tickets, err := s.GetTickets(TicketFilter{
+		IncludeDeleted: true,
+		Limit:          1,
+		Expression:     func(ticket Ticket) bool { return ticket.ID == ticketID },
+	})
+	return err == nil && len(tickets) > 0
+}
+
+func (s *kitchenStore) StoreTicket(ticket Ticket) (int, error) {
+	ticket.KitchenID = s.kitchenID
+	return s.KitchenStore.StoreTicket(ticket)
+}
+
+func (s *kitchenStore) StoreTicketIfNotExists(ticket Ticket) (Ticket, bool, error) {
+	ticket.KitchenID = s.kitchenID
+	return s.KitchenStore.StoreTicketIfNotExists(ticket)
+}
+
+func (s *kitchenStore) GetTickets(filter TicketFilter) ([]Ticket, error) {
+	filter.KitchenID = s.kitchenID
+	return s.KitchenStore.GetTickets(filter)
+}
+
+func (s *kitchenStore) GetTicketsByIDs(ticketIDs []int) ([]Ticket, error) {
+	tickets, err := s.KitchenStore.GetTicketsByIDs(ticketIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := []Ticket{}
+	for _, ticket := range tickets {
+		if ticket.KitchenID == s.kitchenID {
+			kept = append(kept, ticket)
+		}
+	}
+
+	return kept, nil
+}
+
+func (s *kitchenStore) StreamTickets(ctx context.Context, fn func(Ticket) error) error {
+	return s.KitchenStore.StreamTickets(ctx, func(ticket Ticket) error {
+		if ticket.KitchenID != s.kitchenID {
+			return nil
+		}
+
+		return fn(ticket)
+	})
+}
+
+func (s *kitchenStore) CountTickets(filter TicketFilter) (int, error) {
+	filter.KitchenID = s.kitchenID
+	return s.KitchenStore.CountTickets(filter)
+}
+
+func (s *kitchenStore) ClaimNextTicket(filter TicketFilter, claim Claim) (Ticket, bool, error) {
+	filter.KitchenID = s.kitchenID
+	return s.KitchenStore.ClaimNextTicket(filter, claim)
+}
+
+func (s *kitchenStore) MoveTicket(ticketID int, delta int, movedAt time.Time) (Ticket, error) {
+	if _, err := s.GetTicketByID(ticketID); err != nil {
+		return Ticket{}, err
+	}
+
+	return s.KitchenStore.MoveTicket(ticketID, delta, movedAt)
+}
+
+func (s *kitchenStore) ExpireTicket(ticketID int, now time.Time) (Ticket, bool, error) {
+	if _, err := s.GetTicketByID(ticketID); err != nil {
+		return Ticket{}, false, err
+	}
+
+	return s.KitchenStore.ExpireTicket(ticketID, now)
+}
+
+func (s *kitchenStore) UpdateTicket(ticket Ticket) error {
+	if _, err := s.GetTicketByID(ticket.ID); err != nil {
+		return err
+	}
+
+	ticket.KitchenID = s.kitchenID
+	return s.KitchenStore.UpdateTicket(ticket)
+}
+
+func (s *kitchenStore) UpdateTicketWithOutbox(ticket Ticket, event TicketEvent) error {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return errNoOutboxWriter
+	}
+
+	if _, err := s.GetTicketByID(ticket.ID); err != nil {
+		return err
+	}
+
+	ticket.KitchenID = s.kitchenID
+	return writer.UpdateTicketWithOutbox(ticket, event)
+}
+
+func (s *kitchenStore) StoreTicketWithOutbox(ticket Ticket, events []TicketEvent) (int, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return 0, errNoOutboxWriter
+	}
+
+	ticket.KitchenID = s.kitchenID
+	return writer.StoreTicketWithOutbox(ticket, events)
+}
+
+func (s *kitchenStore) StoreTicketIfNotExistsWithOutbox(ticket Ticket, events []TicketEvent) (Ticket, bool, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, false, errNoOutboxWriter
+	}
+
+	ticket.KitchenID = s.kitchenID
+	return writer.StoreTicketIfNotExistsWithOutbox(ticket, events)
+}
+
+func (s *kitchenStore) DeleteTicketWithOutbox(ticketID int, deletedAt time.Time, event TicketEvent) error {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return errNoOutboxWriter
+	}
+
+	if _, err := s.GetTicketByID(ticketID); err != nil {
+		return err
+	}
+
+	return writer.DeleteTicketWithOutbox(ticketID, deletedAt, event)
+}
+
+func (s *kitchenStore) ClaimNextTicketWithOutbox(filter TicketFilter, claim Claim, event TicketEvent) (Ticket, bool, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, false, errNoOutboxWriter
+	}
+
+	filter.KitchenID = s.kitchenID
+	return writer.ClaimNextTicketWithOutbox(filter, claim, event)
+}
+
+func (s *kitchenStore) MoveTicketWithOutbox(ticketID int, delta int, movedAt time.Time, event TicketEvent) (Ticket, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, errNoOutboxWriter
+	}
+
+	if _, err := s.GetTicketByID(ticketID); err != nil {
+		return Ticket{}, err
+	}
+
+	return writer.MoveTicketWithOutbox(ticketID, delta, movedAt, event)
+}
+
+func (s *kitchenStore) ExpireTicketWithOutbox(ticketID int, now time.Time, event TicketEvent) (Ticket, bool, error) {
+	writer, ok := s.KitchenStore.(OutboxWriter)
+	if !ok {
+		return Ticket{}, false, errNoOutboxWriter
+	}
+
+	if _, err := s.GetTicketByID(ticketID); err != nil {
+		return Ticket{}, false, err
+	}
+
+	return writer.ExpireTicketWithOutbox(ticketID, now, event)
+}
+
+func (s *kitchenStore) DeleteTicket(ticketID int, deletedAt time.Time) error {
+	if _, err := s.GetTicketByID(ticketID); err != nil {
+		return err
+	}
+
+	return s.KitchenStore.DeleteTicket(ticketID, deletedAt)
+}
+
+func (s *kitchenStore) RemoveTicket(ticketID int) error {
+	if _, err := s.GetTicketByID(ticketID); err != nil {
+		return err
+	}
+
+	return s.KitchenStore.RemoveTicket(ticketID)
+}
+
+func (s *kitchenStore) StoreTicketEvent(event TicketEvent) error {
+	if _, err := s.GetTicketByID(event.TicketID); err != nil {
+		return err
+	}
+
+	return s.KitchenStore.StoreTicketEvent(event)
+}
+
+func (s *kitchenStore) GetTicketEvents(ticketID int) ([]TicketEvent, error) {
+	if _, err := s.GetTicketByID(ticketID); err != nil {
+		return nil, err
+	}
+
+	return s.KitchenStore.GetTicketEvents(ticketID)
+}
+
+func (s *kitchenStore) StoreTemplate(template Template) error {
+	template.KitchenID = s.kitchenID
+	return s.KitchenStore.StoreTemplate(template)
+}
+
+func (s *kitchenStore) GetTemplate(_, name string) (Template, error) {
+	return s.KitchenStore.GetTemplate(s.kitchenID, name)
+}
+
+func (s *kitchenStore) DeleteTemplate(_, name string) error {
+	return s.KitchenStore.DeleteTemplate(s.kitchenID, name)
+}
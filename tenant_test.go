@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestKitchenIsolation(t *testing.T) {
+	ticket := Ticket{
+		OrderID: "order-42",
+		Items:   Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}},
+	}
+
+	newServer := func() (*KitchenServer, *StubKitchenStore) {
+		store := &StubKitchenStore{}
+		clock := &StubClock{time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)}
+		return NewKitchenServer(store, WithKitchens("london", "paris"), WithClock(clock)), store
+	}
+
+	t.Run("creates ticket in kitchen from path", func(t *testing.T) {
+		server, store := newServer()
+
+		request := newPostTicketRequest("/london/ticket/", ticket)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+
+		if store.tickets[0].KitchenID != "london" {
+			t.Errorf("got kitchen %q, want %q", store.tickets[0].KitchenID, "london")
+		}
+	})
+
+	t.Run("returns Not Found for ticket of another kitchen", func(t *testing.T) {
+		server, _ := newServer()
+		server.ServeHTTP(httptest.NewRecorder(), newPostTicketRequest("/london/ticket/", ticket))
+
+		request, _ := http.NewRequest(http.MethodGet, "/paris/ticket/0", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+
+		request, _ = http.NewRequest(http.MethodGet, "/london/ticket/0", nil)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("lists only tickets of the kitchen", func(t *testing.T) {
+		server, store := newServer()
+		server.ServeHTTP(httptest.NewRecorder(), newPostTicketRequest("/london/ticket/", ticket))
+		server.ServeHTTP(httptest.NewRecorder(), newPostTicketRequest("/paris/ticket/", ticket))
+
+		request, _ := http.NewRequest(http.MethodGet, "/paris/ticket/", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+
+		got := getTicketPageFromResponse(t, response.Body)
+		assertTickets(t, got.Tickets, store.tickets[1:])
+	})
+
+	t.Run("doesn't reopen ticket of another kitchen", func(t *testing.T) {
+		store := &StubKitchenStore{
+			tickets: []Ticket{{ID: 1, KitchenID: "london", Status: STATUS_COMPLETED}},
+		}
+		server := NewKitchenServer(store, WithKitchens("london", "paris"))
+
+		request := newReopenTicketRequest(1, ReopenRequest{Reason: "wrong order"})
+		request.URL.Path = "/paris" + request.URL.Path
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+		if store.tickets[0].Status != STATUS_COMPLETED {
+			t.Errorf("got status %v, want ticket left %v", store.tickets[0].Status, STATUS_COMPLETED)
+		}
+	})
+
+	t.Run("returns Not Found for unknown kitchen", func(t *testing.T) {
+		server, store := newServer()
+
+		request := newPostTicketRequest("/berlin/ticket/", ticket)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+		if len(store.tickets) != 0 {
+			t.Errorf("got %d tickets, want none stored", len(store.tickets))
+		}
+	})
+}
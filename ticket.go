@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Status int
+
+const (
+	STATUS_PENDING Status = iota
+	STATUS_ACCEPTED
+	STATUS_COMPLETED
+	STATUS_CANCELLED
+	STATUS_MERGED
+)
+
+var statusNames = map[Status]string{
+	STATUS_PENDING:   "pending",
+	STATUS_ACCEPTED:  "accepted",
+	STATUS_COMPLETED: "completed",
+	STATUS_CANCELLED: "cancelled",
+	STATUS_MERGED:    "merged",
+}
+
+func AllStatuses() []Status {
+	return []Status{STATUS_PENDING, STATUS_ACCEPTED, STATUS_COMPLETED, STATUS_CANCELLED, STATUS_MERGED}
+}
+
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+func ParseStatus(name string) (Status, error) {
+	for status, statusName := range statusNames {
+		if statusName == name {
+			return status, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown status %q", name)
+}
+
+func (s Status) Valid() bool {
+	_, ok := statusNames[s]
+	return ok
+}
+
+const (
+	UNIT_EACH = "each"
+	UNIT_HALF = "half"
+	UNIT_KG   = "kg"
+)
+
+var knownAllergens = map[string]bool{
+	"celery":     true,
+	"crustacean": true,
+	"egg":        true,
+	"fish":       true,
+	"gluten":     true,
+	"lupin":      true,
+	"milk":       true,
+	"mollusc":    true,
+	"mustard":    true,
+	"peanut":     true,
+	"sesame":     true,
+	"soy":        true,
+	"sulphite":   true,
+	"tree-nut":   true,
+}
+
+type Ticket struct {
+	ID            int
+	KitchenID     string
+	OrderID       string
+	Station       string
+	ClaimedBy     string
+	Status        Status
+	QueueRank     int
+	Rush          bool
+	Deleted       bool
+	Items         Items
+	Notes         string
+	Substitutions []Substitution
+	DependsOn     []int
+	TipCents      int64
+	ScheduledFor  *time.Time
+	ExpiresAt     *time.Time
+	CompletedAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type Items []Item
+
+func (i *Items) UnmarshalJSON(data []byte) error {
+	var list string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*i = itemsFromList(list)
+		return nil
+	}
+
+	var items []Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	*i = items
+	return nil
+}
+
+func itemsFromList(list string) Items {
+	var items Items
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		item := Item{Name: name}
+		item.setDefaults()
+		items = append(items, item)
+	}
+
+	return items
+}
+
+type Item struct {
+	Name            string
+	Quantity        float64
+	Unit            string
+	Allergens       []string
+	Price           *Money
+	TaxCategory     string
+	StationOverride string
+	Steps           []Step
+}
+
+func (i *Item) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*i = Item{Name: name}
+		i.setDefaults()
+		return nil
+	}
+
+	// Quantity defaults to one only when the field is left out, so an
+	// explicit 0 still reaches validation and is rejected.
+	type plainItem Item
+	item := plainItem{Quantity: 1}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&item); err != nil {
+		return err
+	}
+
+	*i = Item(item)
+	if i.Unit == "" {
+		i.Unit = UNIT_EACH
+	}
+	return nil
+}
+
+func (i *Item) setDefaults() {
+	if i.Unit == "" {
+		i.Unit = UNIT_EACH
+	}
+
+	if i.Quantity == 0 {
+		i.Quantity = 1
+	}
+}
+
+func isTicketValid(ticket Ticket, limits Limits) bool {
+	return validateTicket(ticket, limits) == nil
+}
+
+func validateTicket(ticket Ticket, limits Limits) error {
+	if ticket.Items == nil {
+		return newValidationError(CODE_ITEMS_EMPTY, "ticket has no items")
+	}
+
+	if err := limits.check(ticket); err != nil {
+		return err
+	}
+
+	for _, item := range ticket.Items {
+		if err := validateItem(item); err != nil {
+			return err
+		}
+	}
+
+	if ticket.TipCents < 0 {
+		return newValidationError(CODE_INVALID_TIP, "ticket has tip %d, want 0 or more", ticket.TipCents)
+	}
+
+	if _, err := ticketTotal(ticket); err != nil {
+		return newValidationError(CODE_MIXED_CURRENCIES, "unable to total ticket, %v", err)
+	}
+
+	return nil
+}
+
+func isItemValid(item Item) bool {
+	return validateItem(item) == nil
+}
+
+func validateItem(item Item) error {
+	if item.Name == "" {
+		return newValidationError(CODE_ITEM_NAME_EMPTY, "item has no name")
+	}
+
+	if item.Quantity <= 0 {
+		return newValidationError(CODE_INVALID_QUANTITY, "item %q has quantity %v, want more than 0", item.Name, item.Quantity)
+	}
+
+	for _, allergen := range item.Allergens {
+		if !isAllergenKnown(allergen) {
+			return newValidationError(CODE_UNKNOWN_ALLERGEN, "item %q has unknown allergen %q", item.Name, allergen)
+		}
+	}
+
+	if item.Price != nil && !item.Price.Valid() {
+		return newValidationError(CODE_INVALID_PRICE, "item %q has an invalid price", item.Name)
+	}
+
+	for _, step := range item.Steps {
+		if step.Name == "" {
+			return newValidationError(CODE_STEP_NAME_EMPTY, "item %q has a step without a name", item.Name)
+		}
+	}
+
+	switch item.Unit {
+	case UNIT_EACH, UNIT_HALF:
+		if item.Quantity != math.Trunc(item.Quantity) {
+			return newValidationError(CODE_INVALID_QUANTITY, "item %q has quantity %v, want a whole number of %s", item.Name, item.Quantity, item.Unit)
+		}
+		return nil
+	case UNIT_KG:
+		return nil
+	}
+
+	return newValidationError(CODE_INVALID_UNIT, "item %q has unknown unit %q", item.Name, item.Unit)
+}
+
+func isAllergenKnown(allergen string) bool {
+	return knownAllergens[allergen]
+}
+
+func ticketAllergens(ticket Ticket) []string {
+	seen := map[string]bool{}
+	allergens := []string{}
+	for _, item := range ticket.Items {
+		for _, allergen := range item.Allergens {
+			if !seen[allergen] {
+				seen[allergen] = true
+				allergens = append(allergens, allergen)
+			}
+		}
+	}
+	sort.Strings(allergens)
+
+	return allergens
+}
+
+func ticketHasAllergen(ticket Ticket, allergen string) bool {
+	for _, item := range ticket.Items {
+		for _, itemAllergen := range item.Allergens {
+			if itemAllergen == allergen {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func ticketHasItem(ticket Ticket, name string) bool {
+	for _, item := range ticket.Items {
+		if item.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	t.Run("names every status", func(t *testing.T) {
+		want := []string{"pending", "accepted", "completed", "cancelled", "merged"}
+
+		got := []string{}
+		for _, status := range AllStatuses() {
+			got = append(got, status.String())
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got status names %v, want %v", got, want)
+		}
+	})
+
+	t.Run("names unknown status by its value", func(t *testing.T) {
+		got := Status(42).String()
+		want := "Status(42)"
+
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("reports only defined statuses as valid", func(t *testing.T) {
+		for _, status := range AllStatuses() {
+			if !status.Valid() {
+				t.Errorf("got %v invalid, want valid", status)
+			}
+		}
+
+		for _, status := range []Status{-1, Status(len(AllStatuses()))} {
+			if status.Valid() {
+				t.Errorf("got %v valid, want invalid", status)
+			}
+		}
+	})
+
+	t.Run("parses status names", func(t *testing.T) {
+		for _, status := range AllStatuses() {
+			got, err := ParseStatus(status.String())
+			if err != nil || got != status {
+				t.Errorf("got %v, %v parsing %q, want %v", got, err, status.String(), status)
+			}
+		}
+
+		if _, err := ParseStatus("burnt"); err == nil {
+			t.Errorf("expected an error parsing unknown status but didn't get one")
+		}
+	})
+
+	t.Run("round-trips through JSON", func(t *testing.T) {
+		for _, status := range AllStatuses() {
+			data, err := json.Marshal(Ticket{Status: status})
+			if err != nil {
+				t.Fatalf("unable to marshal ticket, %v", err)
+			}
+
+			got := Ticket{}
+			json.Unmarshal(data, &got)
+
+			if got.Status != status {
+				t.Errorf("got status %v after round-trip, want %v", got.Status, status)
+			}
+		}
+	})
+}
+
+func TestItemValidation(t *testing.T) {
+	cases := []struct {
+		name  string
+		item  Item
+		valid bool
+	}{
+		{"whole each", Item{Name: "burger", Quantity: 2, Unit: UNIT_EACH}, true},
+		{"fractional each", Item{Name: "burger", Quantity: 1.5, Unit: UNIT_EACH}, false},
+		{"whole half", Item{Name: "pizza", Quantity: 3, Unit: UNIT_HALF}, true},
+		{"fractional half", Item{Name: "pizza", Quantity: 0.5, Unit: UNIT_HALF}, false},
+		{"fractional kg", Item{Name: "ribs", Quantity: 0.35, Unit: UNIT_KG}, true},
+		{"zero quantity", Item{Name: "ribs", Quantity: 0, Unit: UNIT_KG}, false},
+		{"negative quantity", Item{Name: "burger", Quantity: -1, Unit: UNIT_EACH}, false},
+		{"unknown unit", Item{Name: "water", Quantity: 1, Unit: "litre"}, false},
+		{"empty name", Item{Quantity: 1, Unit: UNIT_EACH}, false},
+		{"known allergen", Item{Name: "satay", Quantity: 1, Unit: UNIT_EACH, Allergens: []string{"peanut"}}, true},
+		{"unknown allergen", Item{Name: "satay", Quantity: 1, Unit: UNIT_EACH, Allergens: []string{"kryptonite"}}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := isItemValid(c.item)
+			if got != c.valid {
+				t.Errorf("got valid %v for %v, want %v", got, c.item, c.valid)
+			}
+		})
+	}
+}
+
+func TestItemUnmarshalJSON(t *testing.T) {
+	t.Run("decodes a bare item name as one each", func(t *testing.T) {
+		item := Item{}
+		err := json.Unmarshal([]byte(`"burger"`), &item)
+		if err != nil {
+			t.Fatalf("unable to unmarshal item, %v", err)
+		}
+
+		assertItem(t, item, Item{Name: "burger", Quantity: 1, Unit: UNIT_EACH})
+	})
+
+	t.Run("decodes an item object with unit and quantity", func(t *testing.T) {
+		item := Item{}
+		err := json.Unmarshal([]byte(`{"Name":"ribs","Quantity":0.5,"Unit":"kg"}`), &item)
+		if err != nil {
+			t.Fatalf("unable to unmarshal item, %v", err)
+		}
+
+		assertItem(t, item, Item{Name: "ribs", Quantity: 0.5, Unit: UNIT_KG})
+	})
+
+	t.Run("keeps an explicit zero quantity", func(t *testing.T) {
+		item := Item{}
+		err := json.Unmarshal([]byte(`{"Name":"ribs","Quantity":0,"Unit":"kg"}`), &item)
+		if err != nil {
+			t.Fatalf("unable to unmarshal item, %v", err)
+		}
+
+		assertItem(t, item, Item{Name: "ribs", Quantity: 0, Unit: UNIT_KG})
+		if isItemValid(item) {
+			t.Errorf("got %v valid, want a zero quantity rejected", item)
+		}
+	})
+
+	t.Run("rejects unknown item fields", func(t *testing.T) {
+		item := Item{}
+		err := json.Unmarshal([]byte(`{"Name":"ribs","Colour":"red"}`), &item)
+		if err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+}
+
+func TestItemsUnmarshalJSON(t *testing.T) {
+	want := Items{
+		{Name: "burger", Quantity: 1, Unit: UNIT_EACH},
+		{Name: "fries", Quantity: 1, Unit: UNIT_EACH},
+	}
+
+	cases := map[string]string{
+		"array":                  `["burger","fries"]`,
+		"comma separated string": `"burger,fries"`,
+		"string with whitespace": `" burger , fries ,"`,
+		"array of item objects":  `[{"Name":"burger"},{"Name":"fries","Quantity":1,"Unit":"each"}]`,
+	}
+
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Items{}
+			err := json.Unmarshal([]byte(data), &got)
+			if err != nil {
+				t.Fatalf("unable to unmarshal items, %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got items %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("empty string has no items", func(t *testing.T) {
+		var got Items
+		json.Unmarshal([]byte(`""`), &got)
+
+		if got != nil {
+			t.Errorf("got items %v, want none", got)
+		}
+	})
+}
+
+func TestTicketAllergens(t *testing.T) {
+	t.Run("returns deduplicated sorted allergens of all items", func(t *testing.T) {
+		ticket := Ticket{Items: []Item{
+			{Name: "satay", Quantity: 1, Unit: UNIT_EACH, Allergens: []string{"peanut", "soy"}},
+			{Name: "pad thai", Quantity: 1, Unit: UNIT_EACH, Allergens: []string{"egg", "peanut"}},
+			{Name: "rice", Quantity: 1, Unit: UNIT_EACH},
+		}}
+
+		got := ticketAllergens(ticket)
+		want := []string{"egg", "peanut", "soy"}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got allergens %v, want %v", got, want)
+		}
+	})
+
+	t.Run("returns empty set for allergen free ticket", func(t *testing.T) {
+		ticket := Ticket{Items: []Item{{Name: "rice", Quantity: 1, Unit: UNIT_EACH}}}
+
+		got := ticketAllergens(ticket)
+		if len(got) != 0 {
+			t.Errorf("got allergens %v, want none", got)
+		}
+	})
+}
+
+func assertItem(t testing.TB, got, want Item) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got item %v, want %v", got, want)
+	}
+}
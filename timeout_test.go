@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type SlowKitchenStore struct {
+	*StubKitchenStore
+	delay time.Duration
+}
+
+func (s *SlowKitchenStore) GetTicketByID(ticketID int) (Ticket, error) {
+	time.Sleep(s.delay)
+	return s.StubKitchenStore.GetTicketByID(ticketID)
+}
+
+func TestRequestTimeoutStreaming(t *testing.T) {
+	store := NewInMemoryKitchenStore()
+	for range streamFlushEvery + 1 {
+		store.StoreTicket(Ticket{Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}})
+	}
+	server := NewKitchenServer(store, WithRequestTimeout(time.Second))
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, newListTicketsRequest("?stream=true"))
+
+	assertStatus(t, response.Code, http.StatusOK)
+	if !response.Flushed {
+		t.Error("expected ?stream=true to flush as it goes but it was buffered")
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	newStore := func() *StubKitchenStore {
+		return &StubKitchenStore{
+			tickets: []Ticket{
+				{ID: 1, Status: STATUS_COMPLETED, Items: Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}},
+			},
+		}
+	}
+
+	t.Run("returns Service Unavailable when store exceeds budget", func(t *testing.T) {
+		store := &SlowKitchenStore{newStore(), 50 * time.Millisecond}
+		server := NewKitchenServer(store, WithRequestTimeout(10*time.Millisecond))
+
+		request := newGetTicketRequest(1)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+	})
+
+	t.Run("returns OK when store responds within budget", func(t *testing.T) {
+		store := &SlowKitchenStore{newStore(), time.Millisecond}
+		server := NewKitchenServer(store, WithRequestTimeout(time.Second))
+
+		request := newGetTicketRequest(1)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("doesn't write to store after budget elapses", func(t *testing.T) {
+		stub := newStore()
+		store := &SlowKitchenStore{stub, 50 * time.Millisecond}
+		server := NewKitchenServer(store, WithRequestTimeout(10*time.Millisecond))
+
+		request := newReopenTicketRequest(1, ReopenRequest{Reason: "wrong order"})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+
+		time.Sleep(100 * time.Millisecond)
+
+		if stub.tickets[0].Status != STATUS_COMPLETED {
+			t.Errorf("got status %v, want timed out reopen to leave %v", stub.tickets[0].Status, STATUS_COMPLETED)
+		}
+		if len(stub.events) != 0 {
+			t.Errorf("got events %v, want none recorded", stub.events)
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"time"
+	_ "time/tzdata"
+)
+
+func (k *KitchenServer) localTime(t time.Time) time.Time {
+	if k.timezone == nil {
+		return t
+	}
+
+	return t.In(k.timezone)
+}
+
+func (k *KitchenServer) businessDayStart(t time.Time) time.Time {
+	local := k.localTime(t)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+}
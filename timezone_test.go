@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("unable to load timezone, %v", err)
+	}
+
+	// 01:00 on June 3rd in Tokyo, still June 2nd in UTC.
+	now := time.Date(2023, time.June, 2, 16, 0, 0, 0, time.UTC)
+
+	newStore := func() *InMemoryKitchenStore {
+		store := NewInMemoryKitchenStore()
+		for _, completedAt := range []time.Time{
+			time.Date(2023, time.June, 2, 14, 0, 0, 0, time.UTC),
+			time.Date(2023, time.June, 2, 15, 30, 0, 0, time.UTC),
+		} {
+			store.StoreTicket(Ticket{
+				Status:    STATUS_COMPLETED,
+				Items:     Items{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}},
+				UpdatedAt: completedAt,
+			})
+		}
+
+		return store
+	}
+
+	listCompleted := func(t testing.TB, server *KitchenServer) []int {
+		t.Helper()
+
+		request, _ := http.NewRequest(http.MethodGet, "/ticket/completed", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response.Code, http.StatusOK)
+
+		ids := []int{}
+		for _, ticket := range getTicketPageFromResponse(t, response.Body).Tickets {
+			ids = append(ids, ticket.ID)
+		}
+		return ids
+	}
+
+	t.Run("starts the business day at local midnight", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(&StubClock{now}), WithTimezone(tokyo))
+
+		if got, want := listCompleted(t, server), []int{2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("uses the clock's timezone without one configured", func(t *testing.T) {
+		server := NewKitchenServer(newStore(), WithClock(&StubClock{now}))
+
+		if got, want := listCompleted(t, server), []int{1, 2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("matches station rules on local time", func(t *testing.T) {
+		rules, _ := ParseStationRules("00:00-06:00=night,06:00-00:00=day")
+		server := NewKitchenServer(&StubKitchenStore{}, WithClock(&StubClock{now}), WithTimezone(tokyo), WithStationRules(rules...))
+
+		if got := server.defaultStation(now); got != "night" {
+			t.Errorf("got station %q, want night", got)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+const (
+	VERSION_V1 = "v1"
+	VERSION_V2 = "v2"
+)
+
+const acceptVersionHeader = "Accept-Version"
+
+func ValidateVersion(version string) error {
+	switch version {
+	case "", VERSION_V1, VERSION_V2:
+		return nil
+	}
+
+	return fmt.Errorf("unknown version %q, want %s or %s", version, VERSION_V1, VERSION_V2)
+}
+
+type versionWriter struct {
+	http.ResponseWriter
+	version string
+	status  int
+	body    bytes.Buffer
+}
+
+func (v *versionWriter) WriteHeader(status int) {
+	if v.status == 0 {
+		v.status = status
+	}
+}
+
+func (v *versionWriter) Write(data []byte) (int, error) {
+	if v.status == 0 {
+		v.status = http.StatusOK
+	}
+
+	return v.body.Write(data)
+}
+
+func (v *versionWriter) Unwrap() http.ResponseWriter {
+	return v.ResponseWriter
+}
+
+func (v *versionWriter) finish() {
+	if v.status == 0 {
+		return
+	}
+
+	body := v.body.Bytes()
+	if isJSONResponse(v.Header()) {
+		if converted, err := convertVersion(body, v.version); err == nil {
+			body = converted
+		}
+	}
+
+	v.ResponseWriter.WriteHeader(v.status)
+	v.ResponseWriter.Write(body)
+}
+
+func isJSONResponse(header http.Header) bool {
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// versionResponses reshapes JSON responses for clients that send an
+// Accept-Version header. Tickets are always kept in their rich form, v1
+// clients get item names and integer statuses and v2 clients get item
+// objects and status names.
+func (k *KitchenServer) versionResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", acceptVersionHeader)
+
+		version := r.Header.Get(acceptVersionHeader)
+		if version == "" {
+			version = k.defaultVersion
+		}
+		if ValidateVersion(version) != nil {
+			k.writeError(w, http.StatusNotAcceptable, CODE_UNSUPPORTED_VERSION, fmt.Sprintf("unsupported version %q, want %s or %s", version, VERSION_V1, VERSION_V2))
+			return
+		}
+
+		if version == "" || isStreamingRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		versioned := &versionWriter{ResponseWriter: w, version: version}
+		next.ServeHTTP(versioned, r)
+		versioned.finish()
+	})
+}
+
+func convertVersion(data []byte, version string) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var value any
+	if err := d.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("response holds more than one JSON value")
+	}
+
+	converted, err := json.Marshal(walkVersion(value, version))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(converted, '\n'), nil
+}
+
+func walkVersion(value any, version string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			v[key] = walkVersion(field, version)
+		}
+
+		if items, ok := v["Items"].([]any); ok && version == VERSION_V1 {
+			v["Items"] = itemNames(items)
+		}
+		if _, ok := v["Status"]; ok && hasTicketStatus(v) && version == VERSION_V2 {
+			v["Status"] = statusName(v["Status"])
+		}
+	case []any:
+		for i, element := range v {
+			v[i] = walkVersion(element, version)
+		}
+	}
+
+	return value
+}
+
+// hasTicketStatus tells tickets and ticket events, whose Status is a ticket
+// Status, apart from batch results, whose Status is an HTTP status code.
+func hasTicketStatus(object map[string]any) bool {
+	_, isTicket := object["Items"]
+	_, isEvent := object["TicketID"]
+	return isTicket || isEvent
+}
+
+func itemNames(items []any) []any {
+	names := make([]any, 0, len(items))
+	for _, item := range items {
+		if object, ok := item.(map[string]any); ok {
+			names = append(names, object["Name"])
+		} else {
+			names = append(names, item)
+		}
+	}
+
+	return names
+}
+
+func statusName(value any) any {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+
+	status, err := number.Int64()
+	if err != nil || !Status(status).Valid() {
+		return value
+	}
+
+	return Status(status).String()
+}
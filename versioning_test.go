@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAcceptVersion(t *testing.T) {
+	ticket := Ticket{ID: 1, OrderID: "order-1", Status: STATUS_ACCEPTED, Items: Items{
+		{Name: "burger", Quantity: 1, Unit: UNIT_EACH},
+		{Name: "fries", Quantity: 2, Unit: UNIT_EACH, Allergens: []string{"gluten"}},
+	}}
+
+	getTicket := func(t *testing.T, server *KitchenServer, version string) map[string]any {
+		t.Helper()
+
+		request := newGetTicketRequest(1)
+		if version != "" {
+			request.Header.Set(acceptVersionHeader, version)
+		}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		got := map[string]any{}
+		if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+			t.Fatalf("unable to parse response %q, %v", response.Body, err)
+		}
+		return got
+	}
+
+	t.Run("gives v1 clients item names and integer statuses", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{tickets: []Ticket{ticket}})
+
+		got := getTicket(t, server, VERSION_V1)
+
+		if want := []any{"burger", "fries"}; !reflect.DeepEqual(got["Items"], want) {
+			t.Errorf("got items %v, want %v", got["Items"], want)
+		}
+		if got["Status"] != float64(STATUS_ACCEPTED) {
+			t.Errorf("got status %v, want %d", got["Status"], STATUS_ACCEPTED)
+		}
+	})
+
+	t.Run("gives v2 clients item objects and status names", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{tickets: []Ticket{ticket}})
+
+		got := getTicket(t, server, VERSION_V2)
+
+		items, ok := got["Items"].([]any)
+		if !ok || len(items) != 2 {
+			t.Fatalf("got items %v, want 2 item objects", got["Items"])
+		}
+		fries, ok := items[1].(map[string]any)
+		if !ok || fries["Name"] != "fries" || fries["Quantity"] != float64(2) {
+			t.Errorf("got item %v, want 2 fries", items[1])
+		}
+		if got["Status"] != "accepted" {
+			t.Errorf("got status %v, want accepted", got["Status"])
+		}
+	})
+
+	t.Run("reshapes tickets in pages", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{tickets: []Ticket{ticket}})
+
+		request := newListTicketsRequest("?limit=10")
+		request.Header.Set(acceptVersionHeader, VERSION_V1)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		page := struct{ Tickets []struct{ Items []string } }{}
+		json.Unmarshal(response.Body.Bytes(), &page)
+		if len(page.Tickets) != 1 || !reflect.DeepEqual(page.Tickets[0].Items, []string{"burger", "fries"}) {
+			t.Errorf("got page %q, want ticket 1 with item names", response.Body)
+		}
+	})
+
+	t.Run("uses the default version without a header", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{tickets: []Ticket{ticket}}, WithDefaultVersion(VERSION_V2))
+
+		got := getTicket(t, server, "")
+
+		if got["Status"] != "accepted" {
+			t.Errorf("got status %v, want accepted", got["Status"])
+		}
+	})
+
+	t.Run("rejects unknown versions", func(t *testing.T) {
+		server := NewKitchenServer(&StubKitchenStore{tickets: []Ticket{ticket}})
+
+		request := newGetTicketRequest(1)
+		request.Header.Set(acceptVersionHeader, "v3")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotAcceptable)
+		assertErrorCode(t, response, CODE_UNSUPPORTED_VERSION)
+	})
+
+	t.Run("leaves batch result statuses alone", func(t *testing.T) {
+		data, err := convertVersion([]byte(`[{"Index": 0, "ID": 1, "Status": 201}]`), VERSION_V2)
+		if err != nil {
+			t.Fatalf("unable to convert results, %v", err)
+		}
+
+		if got := string(data); got != `[{"ID":1,"Index":0,"Status":201}]`+"\n" {
+			t.Errorf("got results %q, want the HTTP status kept", got)
+		}
+	})
+}
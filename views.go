@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+func (k *KitchenServer) listActiveTickets(w http.ResponseWriter, r *http.Request) {
+	filter, err := getTicketFilter(r, k.limits.MaxPageLimit)
+	if err != nil || r.URL.Query().Has("status") {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	filter.Statuses = activeStatuses
+	if k.completedLinger > 0 {
+		now := k.clock.Now()
+		matches := filter.Expression
+		filter.Statuses = []Status{STATUS_PENDING, STATUS_ACCEPTED, STATUS_COMPLETED}
+		filter.Expression = func(ticket Ticket) bool {
+			return (ticket.Status != STATUS_COMPLETED || k.isLingering(ticket, now)) && (matches == nil || matches(ticket))
+		}
+	}
+	k.serveTicketList(w, r, filter)
+}
+
+func (k *KitchenServer) listCompletedTickets(w http.ResponseWriter, r *http.Request) {
+	filter, err := getTicketFilter(r, k.limits.MaxPageLimit)
+	if err != nil || r.URL.Query().Has("status") {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	since := k.businessDayStart(k.clock.Now())
+	if value := r.URL.Query().Get("since"); value != "" {
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	filter.Statuses = []Status{STATUS_COMPLETED}
+	filter.UpdatedAfter = since.Add(-time.Nanosecond)
+	k.serveTicketList(w, r, filter)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTicketViews(t *testing.T) {
+	now := time.Date(2023, time.June, 2, 15, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour)
+	morning := time.Date(2023, time.June, 2, 9, 0, 0, 0, time.UTC)
+
+	newServer := func() *KitchenServer {
+		store := NewInMemoryKitchenStore()
+		for _, ticket := range []Ticket{
+			{Status: STATUS_PENDING, UpdatedAt: morning},
+			{Status: STATUS_ACCEPTED, UpdatedAt: morning},
+			{Status: STATUS_COMPLETED, UpdatedAt: morning},
+			{Status: STATUS_COMPLETED, UpdatedAt: yesterday},
+			{Status: STATUS_CANCELLED, UpdatedAt: morning},
+			{Status: STATUS_MERGED, UpdatedAt: morning},
+		} {
+			ticket.Items = []Item{{Name: "burger", Quantity: 1, Unit: UNIT_EACH}}
+			store.StoreTicket(ticket)
+		}
+
+		return NewKitchenServer(store, WithClock(&StubClock{now}))
+	}
+
+	listIDs := func(t testing.TB, server *KitchenServer, path string) []int {
+		t.Helper()
+
+		request, _ := http.NewRequest(http.MethodGet, path, nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response.Code, http.StatusOK)
+
+		ids := []int{}
+		for _, ticket := range getTicketPageFromResponse(t, response.Body).Tickets {
+			ids = append(ids, ticket.ID)
+		}
+		return ids
+	}
+
+	t.Run("active view lists pending and accepted tickets", func(t *testing.T) {
+		got := listIDs(t, newServer(), "/ticket/active")
+
+		if want := []int{1, 2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("completed view lists tickets completed today", func(t *testing.T) {
+		got := listIDs(t, newServer(), "/ticket/completed")
+
+		if want := []int{3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("completed view lists tickets since a given time", func(t *testing.T) {
+		got := listIDs(t, newServer(), "/ticket/completed?since="+yesterday.Format(time.RFC3339))
+
+		if want := []int{3, 4}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("active view follows status changes", func(t *testing.T) {
+		server := newServer()
+		server.ServeHTTP(httptest.NewRecorder(), newCompleteTicketRequest(1))
+
+		if got, want := listIDs(t, server, "/ticket/active"), []int{2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got active IDs %v, want %v", got, want)
+		}
+		if got, want := listIDs(t, server, "/ticket/completed"), []int{1, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got completed IDs %v, want %v", got, want)
+		}
+	})
+
+	t.Run("returns Bad Request on malformed since", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/ticket/completed?since=today", nil)
+		response := httptest.NewRecorder()
+		newServer().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("returns Bad Request on a status filter", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/ticket/active?status=completed", nil)
+		response := httptest.NewRecorder()
+		newServer().ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}